@@ -7,3 +7,9 @@ var MigrationsFS embed.FS
 
 //go:embed migrations/seeds/*.sql
 var SeedsFS embed.FS
+
+// BreachedPasswords is a gzip-compressed list of SHA-1 hashes of common
+// breached passwords, rebuilt with make breached-passwords
+//
+//go:embed passwords/breached-sha1.txt.gz
+var BreachedPasswords []byte
@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions ADD COLUMN user_agent VARCHAR(512);
+ALTER TABLE user_sessions ADD COLUMN ip_address VARCHAR(64);
+ALTER TABLE user_sessions ADD COLUMN last_seen_at TIMESTAMP;
+
+UPDATE user_sessions SET last_seen_at = COALESCE(rotated_at, created_at) WHERE last_seen_at IS NULL;
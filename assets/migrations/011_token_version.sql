@@ -0,0 +1,2 @@
+-- Incremented on password change and reset; tokens carrying an older value are rejected
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
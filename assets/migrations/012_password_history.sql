@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS password_histories (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_histories_user_id ON password_histories(user_id);
@@ -0,0 +1,80 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id INTEGER NOT NULL,
+    permission_id INTEGER NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_permissions_permission_id ON role_permissions(permission_id);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INTEGER NOT NULL,
+    role_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles(role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access, including user management'),
+    ('user', 'Default role for registered users')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('resources:read', 'List and view resources'),
+    ('resources:write', 'Create, update and delete resources'),
+    ('users:read', 'View user accounts and roles'),
+    ('users:admin', 'Manage user accounts and role assignments')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles CROSS JOIN permissions
+WHERE roles.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id
+FROM roles JOIN permissions ON permissions.name IN ('resources:read', 'resources:write')
+WHERE roles.name = 'user'
+ON CONFLICT DO NOTHING;
+
+-- Carry over users.role. Role checks used to ignore case, so names are
+-- lowercased. Custom roles get the user role's permissions, which matches
+-- the access they had before, and users without a role become users.
+INSERT INTO roles (name, description)
+SELECT DISTINCT LOWER(TRIM(role)), 'Migrated from users.role'
+FROM users
+WHERE role IS NOT NULL AND TRIM(role) <> ''
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT migrated.id, base.permission_id
+FROM roles migrated
+JOIN role_permissions base ON base.role_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE migrated.description = 'Migrated from users.role'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id
+FROM users JOIN roles ON roles.name = COALESCE(NULLIF(LOWER(TRIM(users.role)), ''), 'user')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- users.role was carried over into user_roles by 013_rbac.sql
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
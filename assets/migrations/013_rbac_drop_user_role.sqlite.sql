@@ -0,0 +1,4 @@
+-- users.role was carried over into user_roles by 013_rbac.sql. SQLite has no
+-- DROP COLUMN IF EXISTS, and cannot drop a column that is still indexed.
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN role;
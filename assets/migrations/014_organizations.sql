@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(120) NOT NULL,
+    slug VARCHAR(63) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_members_org_user ON organization_members(organization_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- Active organization of a session, carried in its access tokens
+ALTER TABLE user_sessions ADD COLUMN organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- Everything used to be global. Existing users and resources move into one
+-- default organization so nobody loses access; admins become its owners.
+-- Resources follow in 014_organizations_resources, written per driver.
+INSERT INTO organizations (name, slug) VALUES ('Default', 'default')
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT organizations.id, users.id,
+    CASE WHEN EXISTS (
+        SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+        WHERE user_roles.user_id = users.id AND roles.name = 'admin'
+    ) THEN 'owner' ELSE 'member' END
+FROM users CROSS JOIN organizations
+WHERE organizations.slug = 'default'
+ON CONFLICT (organization_id, user_id) DO NOTHING;
@@ -0,0 +1,5 @@
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE resources SET organization_id = (SELECT id FROM organizations WHERE slug = 'default') WHERE organization_id IS NULL;
+ALTER TABLE resources ALTER COLUMN organization_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_resources_organization_id ON resources(organization_id);
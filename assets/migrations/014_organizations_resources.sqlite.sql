@@ -0,0 +1,28 @@
+-- SQLite cannot add a NOT NULL column without a default or alter one later,
+-- so resources is rebuilt with organization_id and its indexes recreated.
+CREATE TABLE resources_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(120) NOT NULL,
+    description TEXT,
+    status VARCHAR(40) NOT NULL DEFAULT 'active',
+    created_by_id INTEGER NOT NULL,
+    organization_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+);
+
+INSERT INTO resources_new (id, name, description, status, created_by_id, organization_id, created_at, updated_at, deleted_at)
+SELECT id, name, description, status, created_by_id, (SELECT id FROM organizations WHERE slug = 'default'), created_at, updated_at, deleted_at
+FROM resources;
+
+DROP TABLE resources;
+ALTER TABLE resources_new RENAME TO resources;
+
+CREATE INDEX IF NOT EXISTS idx_resources_name ON resources(name);
+CREATE INDEX IF NOT EXISTS idx_resources_status ON resources(status);
+CREATE INDEX IF NOT EXISTS idx_resources_created_by_id ON resources(created_by_id);
+CREATE INDEX IF NOT EXISTS idx_resources_deleted_at ON resources(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_resources_organization_id ON resources(organization_id);
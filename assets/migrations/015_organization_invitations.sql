@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    token_hash VARCHAR(128) NOT NULL UNIQUE,
+    invited_by_id INTEGER,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_by_id INTEGER,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (accepted_by_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_organization_id ON organization_invitations(organization_id);
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_email ON organization_invitations(email);
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_invited_by_id ON organization_invitations(invited_by_id);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS resource_shares (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL,
+    resource_id INTEGER NOT NULL,
+    user_id INTEGER,
+    role VARCHAR(20),
+    level VARCHAR(20) NOT NULL,
+    granted_by_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_id) REFERENCES resources(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (granted_by_id) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK ((user_id IS NULL) <> (role IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_shares_organization_id ON resource_shares(organization_id);
+CREATE INDEX IF NOT EXISTS idx_resource_shares_user_id ON resource_shares(user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_shares_resource_user ON resource_shares(resource_id, user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_resource_shares_resource_role ON resource_shares(resource_id, role);
@@ -0,0 +1,21 @@
+ALTER TABLE resources ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION resources_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS resources_search_vector_update ON resources;
+CREATE TRIGGER resources_search_vector_update
+    BEFORE INSERT OR UPDATE OF name, description ON resources
+    FOR EACH ROW EXECUTE FUNCTION resources_search_vector_update();
+
+UPDATE resources SET search_vector =
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B');
+
+CREATE INDEX IF NOT EXISTS idx_resources_search_vector ON resources USING GIN (search_vector);
@@ -0,0 +1,23 @@
+-- Requires SQLite built with FTS5 (go build -tags sqlite_fts5)
+CREATE VIRTUAL TABLE IF NOT EXISTS resources_fts USING fts5(
+    name,
+    description,
+    content = 'resources',
+    content_rowid = 'id',
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS resources_fts_insert AFTER INSERT ON resources BEGIN
+    INSERT INTO resources_fts (rowid, name, description) VALUES (NEW.id, NEW.name, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS resources_fts_delete AFTER DELETE ON resources BEGIN
+    INSERT INTO resources_fts (resources_fts, rowid, name, description) VALUES ('delete', OLD.id, OLD.name, OLD.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS resources_fts_update AFTER UPDATE OF name, description ON resources BEGIN
+    INSERT INTO resources_fts (resources_fts, rowid, name, description) VALUES ('delete', OLD.id, OLD.name, OLD.description);
+    INSERT INTO resources_fts (rowid, name, description) VALUES (NEW.id, NEW.name, NEW.description);
+END;
+
+INSERT INTO resources_fts (resources_fts) VALUES ('rebuild');
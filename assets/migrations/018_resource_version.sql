@@ -0,0 +1,2 @@
+-- Incremented on every update; exposed as the resource's ETag for If-Match checks
+ALTER TABLE resources ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
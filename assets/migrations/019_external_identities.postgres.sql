@@ -0,0 +1,2 @@
+-- External token identities store the issuer URL as their provider
+ALTER TABLE user_identities ALTER COLUMN provider TYPE VARCHAR(255);
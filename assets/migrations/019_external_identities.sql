@@ -0,0 +1,3 @@
+-- Set on identities whose account an external token provisioned; only those
+-- take their roles from the provider's role claim
+ALTER TABLE user_identities ADD COLUMN provisioned BOOLEAN NOT NULL DEFAULT FALSE;
@@ -1,10 +1,10 @@
-INSERT INTO users (email, password, password_is_set_by_user, role, is_active)
+INSERT INTO users (email, password, password_is_set_by_user, is_active, email_verified_at)
 VALUES (
     'admin@example.com',
     '$2a$10$slYQmyNdGzin7olVN3VN2OPST9/PgBkqquzi.Ss8KIUgO2t0jWMUe',
     true,
-    'admin',
-    true
+    true,
+    CURRENT_TIMESTAMP
 )
 ON CONFLICT (email) DO NOTHING;
 
@@ -13,3 +13,15 @@ SELECT id, 'Admin', 'User'
 FROM users
 WHERE email = 'admin@example.com'
 ON CONFLICT (user_id) DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT users.id, roles.id
+FROM users JOIN roles ON roles.name = 'admin'
+WHERE users.email = 'admin@example.com'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT organizations.id, users.id, 'owner'
+FROM users CROSS JOIN organizations
+WHERE users.email = 'admin@example.com' AND organizations.slug = 'default'
+ON CONFLICT (organization_id, user_id) DO NOTHING;
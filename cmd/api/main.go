@@ -15,6 +15,7 @@ import (
 	"go-fiber-boilerplate/internal/database"
 	"go-fiber-boilerplate/internal/middleware"
 	"go-fiber-boilerplate/internal/routes"
+	"go-fiber-boilerplate/pkg/listquery"
 	"go-fiber-boilerplate/pkg/utils"
 
 	_ "go-fiber-boilerplate/docs"
@@ -53,6 +54,8 @@ func main() {
 	}
 	utils.SetLogLevel(cfg.LogLevel)
 	utils.SetQuiet(cfg.LogQuiet)
+	utils.SetPasswordHasher(cfg.GetPasswordHasher())
+	listquery.SetCursorKey(cfg.GetCursorKey())
 	utils.CleanupOldLogs("logs/app", cfg.LogRetentionDays)
 
 	db, err := database.Initialize(cfg)
@@ -83,6 +86,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	tokenManager, err := cfg.GetTokenManager()
+	if err != nil {
+		utils.Log("App").Error("Failed to load JWT signing keys", "error", err)
+		os.Exit(1)
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName:           cfg.AppName,
 		BodyLimit:         10 * 1024 * 1024,
@@ -97,7 +106,7 @@ func main() {
 	middleware.InitLimiterStorage(cache.NewLimiterStorage(cfg))
 
 	setupMiddleware(app, cfg)
-	routes.SetupRoutes(app, cacheClient)
+	routes.SetupRoutes(app, cacheClient, tokenManager)
 	startServer(app, cfg)
 }
 
@@ -178,6 +187,7 @@ func setupMiddleware(app *fiber.App, cfg *config.Config) {
 		AllowOrigins:     cfg.CORSAllowedOrigins,
 		AllowMethods:     cfg.CORSAllowedMethods,
 		AllowHeaders:     cfg.CORSAllowedHeaders,
+		ExposeHeaders:    fiber.HeaderETag,
 		AllowCredentials: true,
 	}))
 	app.Use(helmet.New())
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"strconv"
@@ -37,9 +39,28 @@ type Config struct {
 	CacheEnabled  bool
 	CacheTTL      time.Duration
 
-	JWTSecret        string
-	JWTExpiry        time.Duration
-	JWTRefreshExpiry time.Duration
+	JWTSecret           string
+	JWTExpiry           time.Duration
+	JWTRefreshExpiry    time.Duration
+	JWTAlgorithm        string
+	JWTKeyID            string
+	JWTPrivateKeyFile   string
+	JWTVerificationKeys string
+
+	ExternalAuthIssuer        string
+	ExternalAuthAudience      string
+	ExternalAuthJWKSURL       string
+	ExternalAuthJWKSCacheTTL  time.Duration
+	ExternalAuthSubjectClaim  string
+	ExternalAuthEmailClaim    string
+	ExternalAuthRoleClaim     string
+	ExternalAuthAutoProvision bool
+	ExternalAuthDefaultRole   string
+
+	TenantBaseDomain string
+	PolicyHideDenied bool
+	CursorSecret     string
+	IfMatchRequired  bool
 
 	CORSAllowedOrigins string
 	CORSAllowedMethods string
@@ -59,6 +80,43 @@ type Config struct {
 	FrontendURL      string
 	PasswordResetURL string
 
+	EmailVerificationURL      string
+	EmailVerificationTTL      time.Duration
+	EmailVerificationRequired bool
+
+	MagicLinkURL string
+	MagicLinkTTL time.Duration
+
+	ResourceURL string
+
+	InvitationURL             string
+	InvitationTTL             time.Duration
+	InvitationCleanupInterval time.Duration
+
+	MFAEncryptionKey string
+	MFAIssuer        string
+	MFAChallengeTTL  time.Duration
+
+	OAuthRedirectURL string
+	OAuthStateTTL    time.Duration
+	OAuthProviders   map[string]OAuthProviderSettings
+
+	LoginLockoutThreshold int
+	LoginLockoutDuration  time.Duration
+	LoginBackoffAfter     int
+	LoginBackoffBase      time.Duration
+
+	PasswordMinLength       int
+	PasswordRequiredClasses string
+	PasswordHistory         int
+	PasswordCheckBreached   bool
+
+	PasswordHashAlgorithm    string
+	PasswordBcryptCost       int
+	PasswordArgon2Memory     int
+	PasswordArgon2Iterations int
+	PasswordArgon2Threads    int
+
 	SMTPHost      string
 	SMTPPort      int
 	SMTPUser      string
@@ -100,13 +158,32 @@ func LoadConfig() (*Config, error) {
 		CacheEnabled:  parseBool(getEnv("CACHE_ENABLED", "true")),
 		CacheTTL:      parseDuration(getEnv("CACHE_TTL", "5m")),
 
-		JWTSecret:        getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
-		JWTExpiry:        parseDuration(getEnv("JWT_EXPIRY", "15m")),
-		JWTRefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h")),
+		JWTSecret:           getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
+		JWTExpiry:           parseDuration(getEnv("JWT_EXPIRY", "15m")),
+		JWTRefreshExpiry:    parseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h")),
+		JWTAlgorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyID:            getEnv("JWT_KEY_ID", ""),
+		JWTPrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTVerificationKeys: getEnv("JWT_VERIFICATION_KEYS", ""),
+
+		ExternalAuthIssuer:        getEnv("EXTERNAL_AUTH_ISSUER", ""),
+		ExternalAuthAudience:      getEnv("EXTERNAL_AUTH_AUDIENCE", ""),
+		ExternalAuthJWKSURL:       getEnv("EXTERNAL_AUTH_JWKS_URL", ""),
+		ExternalAuthJWKSCacheTTL:  parseDuration(getEnv("EXTERNAL_AUTH_JWKS_CACHE_TTL", "1h")),
+		ExternalAuthSubjectClaim:  getEnv("EXTERNAL_AUTH_CLAIM_SUBJECT", "sub"),
+		ExternalAuthEmailClaim:    getEnv("EXTERNAL_AUTH_CLAIM_EMAIL", "email"),
+		ExternalAuthRoleClaim:     getEnv("EXTERNAL_AUTH_CLAIM_ROLE", ""),
+		ExternalAuthAutoProvision: parseBool(getEnv("EXTERNAL_AUTH_AUTO_PROVISION", "false")),
+		ExternalAuthDefaultRole:   getEnv("EXTERNAL_AUTH_DEFAULT_ROLE", "user"),
+
+		TenantBaseDomain: strings.ToLower(strings.TrimPrefix(getEnv("TENANT_BASE_DOMAIN", ""), ".")),
+		PolicyHideDenied: parseBool(getEnv("POLICY_HIDE_DENIED", "false")),
+		CursorSecret:     getEnv("CURSOR_SECRET", ""),
+		IfMatchRequired:  parseBool(getEnv("IF_MATCH_REQUIRED", "true")),
 
 		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:4000,http://localhost:8080"),
 		CORSAllowedMethods: getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
-		CORSAllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization"),
+		CORSAllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,X-API-Key,X-Organization-ID,If-Match,If-None-Match"),
 
 		LogLevel:         getEnv("LOG_LEVEL", "info"),
 		LogHTTPBody:      parseBool(getEnv("LOG_HTTP_BODY", "true")),
@@ -122,6 +199,43 @@ func LoadConfig() (*Config, error) {
 		FrontendURL:      getEnv("FRONTEND_URL", "http://localhost:3000"),
 		PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password?token={token}"),
 
+		EmailVerificationURL:      getEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email?token={token}"),
+		EmailVerificationTTL:      parseDuration(getEnv("EMAIL_VERIFICATION_TTL", "24h")),
+		EmailVerificationRequired: parseBool(getEnv("EMAIL_VERIFICATION_REQUIRED", "false")),
+
+		MagicLinkURL: getEnv("MAGIC_LINK_URL", "http://localhost:3000/magic-link?token={token}"),
+		MagicLinkTTL: parseDuration(getEnv("MAGIC_LINK_TTL", "15m")),
+
+		ResourceURL: getEnv("RESOURCE_URL", "http://localhost:3000/resources/{id}"),
+
+		InvitationURL:             getEnv("INVITATION_URL", "http://localhost:3000/invitations/accept?token={token}"),
+		InvitationTTL:             parseDuration(getEnv("INVITATION_TTL", "168h")),
+		InvitationCleanupInterval: parseDuration(getEnv("INVITATION_CLEANUP_INTERVAL", "1h")),
+
+		MFAEncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+		MFAIssuer:        getEnv("MFA_ISSUER", ""),
+		MFAChallengeTTL:  parseDuration(getEnv("MFA_CHALLENGE_TTL", "5m")),
+
+		OAuthRedirectURL: getEnv("OAUTH_REDIRECT_URL", "http://localhost:3000/oauth/{provider}/callback"),
+		OAuthStateTTL:    parseDuration(getEnv("OAUTH_STATE_TTL", "10m")),
+		OAuthProviders:   loadOAuthProviders(getEnv("OAUTH_PROVIDERS", "")),
+
+		LoginLockoutThreshold: parseInt(getEnv("LOGIN_LOCKOUT_THRESHOLD", "10")),
+		LoginLockoutDuration:  parseDuration(getEnv("LOGIN_LOCKOUT_DURATION", "15m")),
+		LoginBackoffAfter:     parseInt(getEnv("LOGIN_BACKOFF_AFTER", "3")),
+		LoginBackoffBase:      parseDuration(getEnv("LOGIN_BACKOFF_BASE", "1s")),
+
+		PasswordMinLength:       parseInt(getEnv("PASSWORD_MIN_LENGTH", "8")),
+		PasswordRequiredClasses: getEnv("PASSWORD_REQUIRED_CLASSES", ""),
+		PasswordHistory:         parseInt(getEnv("PASSWORD_HISTORY", "5")),
+		PasswordCheckBreached:   parseBool(getEnv("PASSWORD_CHECK_BREACHED", "true")),
+
+		PasswordHashAlgorithm:    getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		PasswordBcryptCost:       parseInt(getEnv("PASSWORD_BCRYPT_COST", "12")),
+		PasswordArgon2Memory:     parseInt(getEnv("PASSWORD_ARGON2_MEMORY", "65536")),
+		PasswordArgon2Iterations: parseInt(getEnv("PASSWORD_ARGON2_ITERATIONS", "3")),
+		PasswordArgon2Threads:    parseInt(getEnv("PASSWORD_ARGON2_THREADS", "2")),
+
 		SMTPHost:      getEnv("SMTP_HOST", ""),
 		SMTPPort:      parseInt(getEnv("SMTP_PORT", "587")),
 		SMTPUser:      getEnv("SMTP_USER", ""),
@@ -138,11 +252,29 @@ func LoadConfig() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
-	if c.JWTSecret == "your-super-secret-jwt-key-change-this-in-production" {
-		return fmt.Errorf("JWT_SECRET must be changed from the default value")
+	switch c.JWTAlgorithm {
+	case "HS256":
+		if c.JWTSecret == "your-super-secret-jwt-key-change-this-in-production" {
+			return fmt.Errorf("JWT_SECRET must be changed from the default value")
+		}
+		if len(c.JWTSecret) < 32 {
+			return fmt.Errorf("JWT_SECRET must be at least 32 characters long")
+		}
+	case "RS256", "ES256", "EdDSA":
+		if c.JWTKeyID == "" || c.JWTPrivateKeyFile == "" {
+			return fmt.Errorf("JWT_KEY_ID and JWT_PRIVATE_KEY_FILE are required when JWT_ALGORITHM is %s", c.JWTAlgorithm)
+		}
+	default:
+		return fmt.Errorf("JWT_ALGORITHM must be one of HS256, RS256, ES256, EdDSA")
+	}
+	if c.CursorSecret == "" && c.JWTAlgorithm != "HS256" {
+		return fmt.Errorf("CURSOR_SECRET is required when JWT_ALGORITHM is %s", c.JWTAlgorithm)
 	}
-	if len(c.JWTSecret) < 32 {
-		return fmt.Errorf("JWT_SECRET must be at least 32 characters long")
+	if c.CursorSecret != "" && len(c.CursorSecret) < 32 {
+		return fmt.Errorf("CURSOR_SECRET must be at least 32 characters long")
+	}
+	if c.ExternalAuthIssuer != "" && c.ExternalAuthAudience == "" {
+		return fmt.Errorf("EXTERNAL_AUTH_AUDIENCE is required when EXTERNAL_AUTH_ISSUER is configured")
 	}
 	if c.DBDriver != "postgres" && c.DBDriver != "sqlite" {
 		return fmt.Errorf("DB_DRIVER must be either 'postgres' or 'sqlite'")
@@ -156,6 +288,26 @@ func (c *Config) Validate() error {
 	if c.SMTPHost != "" && c.SMTPFromEmail == "" {
 		return fmt.Errorf("SMTP_FROM_EMAIL is required when SMTP_HOST is configured")
 	}
+	if c.MFAEncryptionKey != "" {
+		if _, err := utils.NewSecretCipher(c.MFAEncryptionKey); err != nil {
+			return fmt.Errorf("MFA_ENCRYPTION_KEY is invalid: %w", err)
+		}
+	}
+	if c.LoginLockoutThreshold < 0 || c.LoginBackoffAfter < 0 {
+		return fmt.Errorf("LOGIN_LOCKOUT_THRESHOLD and LOGIN_BACKOFF_AFTER must not be negative")
+	}
+	if c.LoginLockoutDuration <= 0 {
+		return fmt.Errorf("LOGIN_LOCKOUT_DURATION must be positive")
+	}
+	if err := c.validateOAuthProviders(); err != nil {
+		return err
+	}
+	if err := c.validatePasswordPolicy(); err != nil {
+		return err
+	}
+	if c.EmailVerificationRequired && c.SMTPHost == "" {
+		utils.Log("Config").Warn("EMAIL_VERIFICATION_REQUIRED is enabled but SMTP is not configured; new users cannot verify their email")
+	}
 	return nil
 }
 
@@ -167,6 +319,21 @@ func (c *Config) IsProduction() bool {
 	return c.Env == "production"
 }
 
+// GetCursorKey returns the key that signs list cursors: CURSOR_SECRET, or
+// else a key derived from JWT_SECRET with HS256. Validate requires
+// CURSOR_SECRET with the other algorithms, whose keys are not shared secrets.
+func (c *Config) GetCursorKey() []byte {
+	if c.CursorSecret != "" {
+		return []byte(c.CursorSecret)
+	}
+	if c.JWTAlgorithm != "HS256" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, []byte(c.JWTSecret))
+	mac.Write([]byte("list cursor"))
+	return mac.Sum(nil)
+}
+
 func (c *Config) RedisEnabled() bool {
 	return strings.TrimSpace(c.RedisHost) != ""
 }
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+func TestLoadConfigRequiresCursorSecretWithAsymmetricKeys(t *testing.T) {
+	utils.InitLogger()
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("JWT_ALGORITHM", "EdDSA")
+	t.Setenv("JWT_KEY_ID", "2024-06")
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "./keys/2024-06.pem")
+	t.Setenv("CURSOR_SECRET", "")
+
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "CURSOR_SECRET") {
+		t.Fatalf("LoadConfig() error = %v, want CURSOR_SECRET to be required", err)
+	}
+
+	t.Setenv("CURSOR_SECRET", strings.Repeat("c", 32))
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := string(cfg.GetCursorKey()); got != strings.Repeat("c", 32) {
+		t.Fatalf("GetCursorKey() = %q, want CURSOR_SECRET", got)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"go-fiber-boilerplate/pkg/jwt"
+)
+
+// GetTokenManager builds the JWT token manager. HS256 signs with JWT_SECRET;
+// asymmetric algorithms sign with JWT_PRIVATE_KEY_FILE under JWT_KEY_ID and also
+// accept tokens signed by JWT_VERIFICATION_KEYS (comma-separated kid=path pairs).
+func (c *Config) GetTokenManager() (*jwt.TokenManager, error) {
+	if c.JWTAlgorithm == jwt.AlgorithmHS256 {
+		return jwt.NewTokenManager(c.JWTSecret), nil
+	}
+
+	active, err := jwt.LoadKeyFile(c.JWTKeyID, c.JWTPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE must contain a private key")
+	}
+	if active.Algorithm != c.JWTAlgorithm {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE holds a %s key but JWT_ALGORITHM is %s", active.Algorithm, c.JWTAlgorithm)
+	}
+
+	var verificationKeys []*jwt.Key
+	for _, entry := range strings.Split(c.JWTVerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(kid) == "" || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("JWT_VERIFICATION_KEYS entry %q must be kid=path", entry)
+		}
+		key, err := jwt.LoadKeyFile(strings.TrimSpace(kid), strings.TrimSpace(path))
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	keyring, err := jwt.NewKeyring(active, verificationKeys...)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.NewTokenManagerWithKeyring(keyring), nil
+}
+
+// GetExternalVerifier returns the verifier for tokens issued by the external
+// identity provider, or nil when EXTERNAL_AUTH_ISSUER is not configured.
+func (c *Config) GetExternalVerifier() *jwt.RemoteVerifier {
+	if c.ExternalAuthIssuer == "" {
+		return nil
+	}
+	return jwt.NewRemoteVerifier(jwt.RemoteVerifierConfig{
+		Issuer:       c.ExternalAuthIssuer,
+		Audience:     c.ExternalAuthAudience,
+		JWKSURL:      c.ExternalAuthJWKSURL,
+		CacheTTL:     c.ExternalAuthJWKSCacheTTL,
+		SubjectClaim: c.ExternalAuthSubjectClaim,
+		EmailClaim:   c.ExternalAuthEmailClaim,
+		RoleClaim:    c.ExternalAuthRoleClaim,
+	})
+}
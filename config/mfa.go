@@ -0,0 +1,24 @@
+package config
+
+import "go-fiber-boilerplate/pkg/utils"
+
+// GetMFACipher builds the cipher protecting TOTP secrets at rest. It returns
+// nil when MFA_ENCRYPTION_KEY is not set, which disables MFA enrollment.
+func (c *Config) GetMFACipher() *utils.SecretCipher {
+	if c.MFAEncryptionKey == "" {
+		return nil
+	}
+	cipher, err := utils.NewSecretCipher(c.MFAEncryptionKey)
+	if err != nil {
+		return nil
+	}
+	return cipher
+}
+
+// GetMFAIssuer returns the issuer shown in authenticator apps
+func (c *Config) GetMFAIssuer() string {
+	if c.MFAIssuer != "" {
+		return c.MFAIssuer
+	}
+	return c.AppName
+}
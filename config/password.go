@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"go-fiber-boilerplate/assets"
+	"go-fiber-boilerplate/pkg/password"
+	"go-fiber-boilerplate/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxPasswordLength matches the limit enforced by the request DTOs
+const maxPasswordLength = 255
+
+func (c *Config) validatePasswordPolicy() error {
+	if c.PasswordMinLength < 1 || c.PasswordMinLength > maxPasswordLength {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be between 1 and %d", maxPasswordLength)
+	}
+	if c.PasswordHistory < 0 {
+		return fmt.Errorf("PASSWORD_HISTORY must not be negative")
+	}
+	if _, err := c.passwordClasses(); err != nil {
+		return fmt.Errorf("PASSWORD_REQUIRED_CLASSES: %w", err)
+	}
+	switch c.PasswordHashAlgorithm {
+	case "argon2id":
+		if c.PasswordArgon2Iterations < 1 || c.PasswordArgon2Threads < 1 || c.PasswordArgon2Threads > 255 {
+			return fmt.Errorf("PASSWORD_ARGON2_ITERATIONS must be positive and PASSWORD_ARGON2_THREADS between 1 and 255")
+		}
+		if c.PasswordArgon2Memory < 8*c.PasswordArgon2Threads {
+			return fmt.Errorf("PASSWORD_ARGON2_MEMORY must be at least 8 KiB per thread")
+		}
+	case "bcrypt":
+		if c.PasswordBcryptCost < bcrypt.MinCost || c.PasswordBcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("PASSWORD_BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be either 'argon2id' or 'bcrypt'")
+	}
+	return nil
+}
+
+// GetPasswordHasher returns the hasher for new passwords. Hashes made with
+// another algorithm or other parameters are upgraded on the next login.
+func (c *Config) GetPasswordHasher() utils.PasswordHasher {
+	if c.PasswordHashAlgorithm == "bcrypt" {
+		return utils.NewBcryptHasher(c.PasswordBcryptCost)
+	}
+	params := utils.DefaultArgon2idParams
+	params.Memory = uint32(c.PasswordArgon2Memory)
+	params.Iterations = uint32(c.PasswordArgon2Iterations)
+	params.Parallelism = uint8(c.PasswordArgon2Threads)
+	return utils.NewArgon2idHasher(params)
+}
+
+func (c *Config) passwordClasses() ([]password.Class, error) {
+	var classes []password.Class
+	for _, name := range strings.Split(c.PasswordRequiredClasses, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		class, err := password.ParseClass(name)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// GetPasswordPolicy builds the policy applied to new passwords. The breached
+// password check uses the list embedded in assets.
+func (c *Config) GetPasswordPolicy() *password.Policy {
+	rules := []password.Rule{
+		password.Length(c.PasswordMinLength, maxPasswordLength),
+		password.PersonalInfo(),
+	}
+	if classes, _ := c.passwordClasses(); len(classes) > 0 {
+		rules = append(rules, password.CharacterClasses(classes...))
+	}
+	if c.PasswordHistory > 0 {
+		rules = append(rules, password.History(c.PasswordHistory, func(plain, hash string) bool {
+			return utils.VerifyPassword(plain, hash) == nil
+		}))
+	}
+	if c.PasswordCheckBreached {
+		source, err := password.LoadRangeSource(bytes.NewReader(assets.BreachedPasswords))
+		if err != nil {
+			utils.Log("Config").Warn("Breached password list could not be loaded, check disabled", "error", err)
+		} else {
+			rules = append(rules, password.Breached(source))
+		}
+	}
+	return password.NewPolicy(rules...)
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +15,8 @@ import (
 	"go-fiber-boilerplate/pkg/utils"
 )
 
+var ErrDisabled = errors.New("cache disabled")
+
 type Client struct {
 	rdb     *redis.Client
 	enabled bool
@@ -99,6 +102,48 @@ func (c *Client) Delete(ctx context.Context, keys ...string) {
 	}
 }
 
+func (c *Client) Exists(ctx context.Context, keys ...string) bool {
+	if !c.Enabled() || len(keys) == 0 {
+		return false
+	}
+	n, err := c.rdb.Exists(ctx, keys...).Result()
+	if err != nil {
+		utils.LogCtx(ctx, "Cache").Warn("Exists failed", "keys", keys, "error", err)
+		return false
+	}
+	return n > 0
+}
+
+// Incr atomically increments a counter and (re)sets its expiry. It returns an
+// error when the cache is disabled so callers can fall back to another store.
+func (c *Client) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if !c.Enabled() {
+		return 0, ErrDisabled
+	}
+	pipe := c.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		utils.LogCtx(ctx, "Cache").Warn("Incr failed", "key", key, "error", err)
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// SetNX stores key until ttl passes unless it already exists, reporting
+// whether it was stored. It returns ErrDisabled when the cache is disabled.
+func (c *Client) SetNX(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if !c.Enabled() {
+		return false, ErrDisabled
+	}
+	ok, err := c.rdb.SetNX(ctx, key, "true", ttl).Result()
+	if err != nil {
+		utils.LogCtx(ctx, "Cache").Warn("SetNX failed", "key", key, "error", err)
+		return false, err
+	}
+	return ok, nil
+}
+
 func (c *Client) Close() {
 	if c.Enabled() {
 		_ = c.rdb.Close()
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenDenylist tracks revoked access tokens and sessions until they expire.
+// Entries are written to Redis when the cache is enabled and always mirrored
+// in-process, so revocation keeps working without Redis on a single instance.
+type TokenDenylist struct {
+	client    *Client
+	mu        sync.Mutex
+	local     map[string]time.Time
+	nextSweep time.Time
+}
+
+// denylistSweepInterval is how often add drops expired local entries. Reads
+// drop the entries they find expired, so the sweep only bounds the memory held
+// by entries nobody looks up again.
+const denylistSweepInterval = time.Minute
+
+func NewTokenDenylist(client *Client) *TokenDenylist {
+	return &TokenDenylist{client: client, local: make(map[string]time.Time)}
+}
+
+// RevokeToken denylists an access token ID until the token expires.
+func (d *TokenDenylist) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) {
+	if tokenID == "" {
+		return
+	}
+	d.add(ctx, "denylist:jti:"+tokenID, expiresAt)
+}
+
+// ClaimToken denylists a single-use token ID until the token expires and
+// reports whether this call was the first to do so. Concurrent claims of the
+// same token ID succeed exactly once.
+func (d *TokenDenylist) ClaimToken(ctx context.Context, tokenID string, expiresAt time.Time) bool {
+	if d == nil {
+		return true
+	}
+	key := "denylist:jti:" + tokenID
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	if exp, ok := d.local[key]; ok && exp.After(time.Now()) {
+		d.mu.Unlock()
+		return false
+	}
+	d.local[key] = expiresAt
+	d.mu.Unlock()
+	// Redis decides between instances; without it the local claim stands
+	if claimed, err := d.client.SetNX(ctx, key, ttl); err == nil && !claimed {
+		return false
+	}
+	return true
+}
+
+// ReleaseToken withdraws a claim taken with ClaimToken so that the token can
+// be used again.
+func (d *TokenDenylist) ReleaseToken(ctx context.Context, tokenID string) {
+	if d == nil || tokenID == "" {
+		return
+	}
+	key := "denylist:jti:" + tokenID
+	d.mu.Lock()
+	delete(d.local, key)
+	d.mu.Unlock()
+	d.client.Delete(ctx, key)
+}
+
+// RevokeSession denylists every access token bound to a session until the
+// given time, which should cover the longest-lived access token for it.
+func (d *TokenDenylist) RevokeSession(ctx context.Context, sessionID uint, until time.Time) {
+	if sessionID == 0 {
+		return
+	}
+	d.add(ctx, sessionKey(sessionID), until)
+}
+
+// IsRevoked reports whether the token ID or its session has been revoked.
+func (d *TokenDenylist) IsRevoked(ctx context.Context, tokenID string, sessionID uint) bool {
+	if d == nil {
+		return false
+	}
+	keys := make([]string, 0, 2)
+	if tokenID != "" {
+		keys = append(keys, "denylist:jti:"+tokenID)
+	}
+	if sessionID != 0 {
+		keys = append(keys, sessionKey(sessionID))
+	}
+	for _, key := range keys {
+		if d.containsLocal(key) {
+			return true
+		}
+	}
+	return d.client.Exists(ctx, keys...)
+}
+
+func (d *TokenDenylist) add(ctx context.Context, key string, until time.Time) {
+	if d == nil {
+		return
+	}
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return
+	}
+	d.mu.Lock()
+	if now := time.Now(); now.After(d.nextSweep) {
+		for k, exp := range d.local {
+			if !exp.After(now) {
+				delete(d.local, k)
+			}
+		}
+		d.nextSweep = now.Add(denylistSweepInterval)
+	}
+	d.local[key] = until
+	d.mu.Unlock()
+	d.client.SetJSON(ctx, key, true, ttl)
+}
+
+func (d *TokenDenylist) containsLocal(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	exp, ok := d.local[key]
+	if !ok {
+		return false
+	}
+	if !exp.After(time.Now()) {
+		delete(d.local, key)
+		return false
+	}
+	return true
+}
+
+func sessionKey(sessionID uint) string {
+	return "denylist:sid:" + strconv.FormatUint(uint64(sessionID), 10)
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+func TestTokenDenylistRevokesUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewTokenDenylist(nil)
+
+	denylist.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour))
+	denylist.RevokeSession(ctx, 7, time.Now().Add(time.Hour))
+	testutil.AssertTrue(t, denylist.IsRevoked(ctx, "jti-1", 0), "revoked token ID")
+	testutil.AssertTrue(t, denylist.IsRevoked(ctx, "jti-2", 7), "token of a revoked session")
+	testutil.AssertFalse(t, denylist.IsRevoked(ctx, "jti-2", 8), "unrelated token")
+
+	// Entries last as long as the token they block
+	denylist.RevokeToken(ctx, "jti-short", time.Now().Add(50*time.Millisecond))
+	denylist.RevokeToken(ctx, "jti-expired", time.Now().Add(-time.Second))
+	testutil.AssertTrue(t, denylist.IsRevoked(ctx, "jti-short", 0), "token before it expires")
+	testutil.AssertFalse(t, denylist.IsRevoked(ctx, "jti-expired", 0), "an expired token needs no entry")
+	time.Sleep(60 * time.Millisecond)
+	testutil.AssertFalse(t, denylist.IsRevoked(ctx, "jti-short", 0), "entry outlived the token")
+
+	var disabled *TokenDenylist
+	disabled.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour))
+	testutil.AssertFalse(t, disabled.IsRevoked(ctx, "jti-1", 0), "a nil denylist revokes nothing")
+}
+
+func TestTokenDenylistSweepsExpiredEntriesPeriodically(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewTokenDenylist(nil)
+	denylist.RevokeToken(ctx, "jti-short", time.Now().Add(20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+
+	// Inserts between sweeps leave expired entries alone
+	denylist.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour))
+	testutil.AssertLen(t, denylist.local, 2)
+
+	denylist.nextSweep = time.Now().Add(-time.Second)
+	denylist.RevokeToken(ctx, "jti-2", time.Now().Add(time.Hour))
+	testutil.AssertLen(t, denylist.local, 2)
+	testutil.AssertFalse(t, denylist.IsRevoked(ctx, "jti-short", 0), "swept entry")
+	testutil.AssertTrue(t, denylist.IsRevoked(ctx, "jti-1", 0), "live entry survives the sweep")
+}
+
+func TestTokenDenylistClaimsTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	denylist := NewTokenDenylist(nil)
+	expiresAt := time.Now().Add(time.Minute)
+
+	var claimed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if denylist.ClaimToken(ctx, "challenge", expiresAt) {
+				claimed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	testutil.AssertEqual(t, int32(1), claimed.Load())
+	testutil.AssertTrue(t, denylist.IsRevoked(ctx, "challenge", 0), "a claimed token is revoked")
+
+	denylist.ReleaseToken(ctx, "challenge")
+	testutil.AssertFalse(t, denylist.IsRevoked(ctx, "challenge", 0), "a released token is usable")
+	testutil.AssertTrue(t, denylist.ClaimToken(ctx, "challenge", expiresAt), "a released token can be claimed again")
+	testutil.AssertFalse(t, denylist.ClaimToken(ctx, "expired", time.Now().Add(-time.Second)), "an expired token cannot be claimed")
+}
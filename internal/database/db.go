@@ -4,6 +4,7 @@ import (
 	"embed"
 
 	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/pkg/tenant"
 	"go-fiber-boilerplate/pkg/utils"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -21,6 +22,11 @@ func Initialize(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		utils.Log("Database").Error("Failed to register tenant plugin", "error", err)
+		return nil, err
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		utils.Log("Database").Error("Failed to get underlying sql.DB", "error", err)
@@ -54,6 +54,11 @@ func (m *Migrator) RunMigrationsFromFS(files embed.FS) error {
 			continue
 		}
 
+		// Skip migrations written for another database driver
+		if dialect := migrationDialect(entry.Name()); dialect != "" && dialect != m.db.Dialector.Name() {
+			continue
+		}
+
 		// Check if migration is already applied
 		if m.isMigrationApplied(entry.Name()) {
 			continue
@@ -87,12 +92,34 @@ func (m *Migrator) RunMigrationsFromFS(files embed.FS) error {
 	return nil
 }
 
+// migrationDialect returns the driver a migration is limited to, taken from
+// names like 013_rbac_drop_user_role.sqlite.sql. Plain .sql files run everywhere.
+func migrationDialect(name string) string {
+	name = strings.TrimSuffix(name, ".sql")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
+
+// sqliteTypes rewrites Postgres column types SQLite reads differently. SQLite
+// only assigns ids to INTEGER PRIMARY KEY columns; a SERIAL key stays NULL.
+var sqliteTypes = strings.NewReplacer("SERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT")
+
+// dialectSQL adapts migration SQL written for Postgres to the database driver
+func (m *Migrator) dialectSQL(sql string) string {
+	if m.db.Dialector.Name() == "sqlite" {
+		return sqliteTypes.Replace(sql)
+	}
+	return sql
+}
+
 // executeMigration executes a single migration
 func (m *Migrator) executeMigration(migration *MigrationFile) error {
 	utils.Log("Migrator").Info("Running migration", "version", migration.Version)
 
 	// Execute SQL
-	if err := m.db.Exec(migration.SQL).Error; err != nil {
+	if err := m.db.Exec(m.dialectSQL(migration.SQL)).Error; err != nil {
 		return fmt.Errorf("failed to execute migration %s: %w", migration.Version, err)
 	}
 
@@ -107,13 +134,13 @@ func (m *Migrator) executeMigration(migration *MigrationFile) error {
 
 // EnsureMigrationTable ensures the migration versions table exists
 func (m *Migrator) EnsureMigrationTable() error {
-	return m.db.Exec(`
+	return m.db.Exec(m.dialectSQL(`
 		CREATE TABLE IF NOT EXISTS migration_versions (
 			id SERIAL PRIMARY KEY,
 			version VARCHAR(50) NOT NULL UNIQUE,
 			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)
-	`).Error
+	`)).Error
 }
 
 func (m *Migrator) ensureMigrationTable() error {
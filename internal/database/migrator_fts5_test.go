@@ -0,0 +1,73 @@
+//go:build sqlite_fts5
+
+package database
+
+import (
+	"slices"
+	"testing"
+
+	"go-fiber-boilerplate/assets"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+// The SQLite migrations create an FTS5 table, so this file needs the
+// sqlite_fts5 build tag that make test sets.
+func TestMigrateFromFSOnSQLite(t *testing.T) {
+	utils.InitLogger()
+	db := openSQLite(t)
+
+	if err := MigrateFromFS(db, assets.MigrationsFS); err != nil {
+		t.Fatalf("MigrateFromFS() error = %v", err)
+	}
+
+	applied, err := NewMigrator(db).GetAppliedMigrations()
+	if err != nil {
+		t.Fatalf("GetAppliedMigrations() error = %v", err)
+	}
+	for _, version := range applied {
+		if dialect := migrationDialect(version); dialect == "postgres" {
+			t.Errorf("Postgres migration %s ran on SQLite", version)
+		}
+	}
+	for _, version := range []string{"013_rbac_drop_user_role.sqlite.sql", "017_resource_search.sqlite.sql", "019_external_identities.sql"} {
+		if !slices.Contains(applied, version) {
+			t.Errorf("Applied migrations = %v, want %s", applied, version)
+		}
+	}
+
+	// Running again applies nothing
+	if err := MigrateFromFS(db, assets.MigrationsFS); err != nil {
+		t.Fatalf("Second MigrateFromFS() error = %v", err)
+	}
+
+	columns := map[string][]string{
+		"users":           {"email_verified_at", "failed_login_attempts", "locked_until", "token_version"},
+		"user_sessions":   {"user_agent", "ip_address", "last_seen_at", "organization_id"},
+		"resources":       {"organization_id", "version"},
+		"user_roles":      {"user_id", "role_id"},
+		"resource_shares": {"resource_id", "level"},
+	}
+	for table, names := range columns {
+		for _, name := range names {
+			if !db.Migrator().HasColumn(table, name) {
+				t.Errorf("%s.%s is missing", table, name)
+			}
+		}
+	}
+	if db.Migrator().HasColumn("users", "role") {
+		t.Error("users.role was not dropped")
+	}
+
+	// Ids are assigned, and resources need an organization
+	if err := db.Exec("INSERT INTO users (email, password) VALUES ('a@example.com', 'x')").Error; err != nil {
+		t.Fatalf("Insert user: %v", err)
+	}
+	var userID *int64
+	db.Raw("SELECT id FROM users WHERE email = 'a@example.com'").Scan(&userID)
+	if userID == nil {
+		t.Fatal("User id was not assigned")
+	}
+	if err := db.Exec("INSERT INTO resources (name, created_by_id) VALUES ('orphan', ?)", *userID).Error; err == nil {
+		t.Error("Resource without an organization was inserted")
+	}
+}
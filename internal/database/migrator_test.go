@@ -0,0 +1,86 @@
+package database
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openSQLite opens an empty in-memory database. One connection keeps every
+// query on the same database.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get database handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestMigrationDialect(t *testing.T) {
+	tests := map[string]string{
+		"001_initial_schema.sql":               "",
+		"013_rbac_drop_user_role.sqlite.sql":   "sqlite",
+		"017_resource_search.postgres.sql":     "postgres",
+		"019_external_identities.postgres.sql": "postgres",
+	}
+	for name, want := range tests {
+		if got := migrationDialect(name); got != want {
+			t.Errorf("migrationDialect(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDialectSQLRewritesSerialKeysOnSQLite(t *testing.T) {
+	db := openSQLite(t)
+	sql := "CREATE TABLE things (id SERIAL PRIMARY KEY, name TEXT)"
+	want := "CREATE TABLE things (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)"
+	if got := NewMigrator(db).dialectSQL(sql); got != want {
+		t.Errorf("dialectSQL() = %q, want %q", got, want)
+	}
+}
+
+func TestMigratorRecordsAppliedMigrations(t *testing.T) {
+	db := openSQLite(t)
+	migrator := NewMigrator(db)
+
+	// The table is created once and kept
+	for i := 0; i < 2; i++ {
+		if err := migrator.EnsureMigrationTable(); err != nil {
+			t.Fatalf("EnsureMigrationTable() error = %v", err)
+		}
+	}
+	if migrator.isMigrationApplied("001_initial_schema.sql") {
+		t.Fatal("Migration applied before it ran")
+	}
+	for _, version := range []string{"001_initial_schema.sql", "002_add_indexes.sql"} {
+		if err := migrator.recordMigration(version); err != nil {
+			t.Fatalf("recordMigration(%q) error = %v", version, err)
+		}
+	}
+	if err := migrator.recordMigration("001_initial_schema.sql"); err == nil {
+		t.Error("Migration recorded twice")
+	}
+	if !migrator.isMigrationApplied("001_initial_schema.sql") {
+		t.Error("Recorded migration is not applied")
+	}
+
+	applied, err := migrator.GetAppliedMigrations()
+	if err != nil {
+		t.Fatalf("GetAppliedMigrations() error = %v", err)
+	}
+	if want := []string{"001_initial_schema.sql", "002_add_indexes.sql"}; !slices.Equal(applied, want) {
+		t.Errorf("GetAppliedMigrations() = %v, want %v", applied, want)
+	}
+}
@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+// AdminUserListQuery filters the admin user listing. Deleted selects
+// soft-deleted users instead of live ones.
+type AdminUserListQuery struct {
+	Search   string `query:"search" validate:"max=255"`
+	Role     string `query:"role" validate:"max=50"`
+	IsActive *bool  `query:"is_active"`
+	Deleted  bool   `query:"deleted"`
+	Page     int    `query:"page"`
+	Limit    int    `query:"limit"`
+}
+
+func (q *AdminUserListQuery) Validate() error {
+	return validate.Struct(q)
+}
+
+// UpdateUserRolesRequest replaces all of a user's roles; an empty list
+// removes every role
+type UpdateUserRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,max=20,dive,required,max=50" example:"admin,user"`
+}
+
+func (r *UpdateUserRolesRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required" example:"false"`
+}
+
+func (r *UpdateUserStatusRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// AdminUserResponse is the administrator's view of a user, including account
+// state that is hidden from the user's own profile.
+type AdminUserResponse struct {
+	ID              uint                 `json:"id" example:"1"`
+	Email           string               `json:"email" example:"john@example.com"`
+	Roles           []string             `json:"roles" example:"user"`
+	IsActive        bool                 `json:"is_active" example:"true"`
+	EmailVerifiedAt *time.Time           `json:"email_verified_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	LockedUntil     *time.Time           `json:"locked_until,omitempty" example:"2024-01-01T00:15:00Z"`
+	CreatedAt       time.Time            `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt       time.Time            `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	DeletedAt       *time.Time           `json:"deleted_at,omitempty" example:"2024-02-01T00:00:00Z"`
+	Profile         *UserProfileResponse `json:"profile,omitempty"`
+}
+
+type RoleResponse struct {
+	ID          uint     `json:"id" example:"1"`
+	Name        string   `json:"name" example:"admin"`
+	Description string   `json:"description" example:"Full access, including user management"`
+	Permissions []string `json:"permissions" example:"resources:read,users:admin"`
+}
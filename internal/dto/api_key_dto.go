@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest creates a key. Scopes must be ones that routes check
+// with RequireScopes; a key without scopes has the user's full access.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100" example:"CI deploy"`
+	Scopes    []string   `json:"scopes" validate:"omitempty,max=20,dive,required,oneof=resources:read resources:write" enums:"resources:read,resources:write" example:"resources:read"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2026-12-31T23:59:59Z"`
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id" example:"1"`
+	Name       string     `json:"name" example:"CI deploy"`
+	Prefix     string     `json:"prefix" example:"fbk_AbC123xy"`
+	Scopes     []string   `json:"scopes" example:"resources:read"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the secret, which is shown only once
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"fbk_AbC123xyZ..."`
+}
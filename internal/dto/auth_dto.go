@@ -6,7 +6,7 @@ var validate = validator.New()
 
 type RegisterRequest struct {
 	Email     string  `json:"email" validate:"required,email" example:"john@example.com"`
-	Password  string  `json:"password" validate:"required,min=8,max=255" example:"password123"`
+	Password  string  `json:"password" validate:"required,max=255" example:"correct-horse-battery"`
 	FirstName string  `json:"first_name" validate:"required,min=2,max=120" example:"John"`
 	LastName  *string `json:"last_name" validate:"omitempty,max=120" example:"Doe"`
 }
@@ -24,11 +24,15 @@ func (r *LoginRequest) Validate() error {
 	return validate.Struct(r)
 }
 
+// LoginResponse carries the token pair, or only MFAToken when MFARequired is
+// set and the login must be completed through /auth/mfa/verify.
 type LoginResponse struct {
-	Token        string `json:"token" example:"eyJhbGciOi..."`
-	RefreshToken string `json:"refresh_token" example:"eyJhbGciOi..."`
-	ExpiresIn    int64  `json:"expires_in" example:"900"`
-	Role         string `json:"role" example:"user"`
+	Token        string   `json:"token,omitempty" example:"eyJhbGciOi..."`
+	RefreshToken string   `json:"refresh_token,omitempty" example:"eyJhbGciOi..."`
+	ExpiresIn    int64    `json:"expires_in,omitempty" example:"900"`
+	Roles        []string `json:"roles,omitempty" example:"user"`
+	MFARequired  bool     `json:"mfa_required,omitempty" example:"false"`
+	MFAToken     string   `json:"mfa_token,omitempty" example:"eyJhbGciOi..."`
 }
 
 type RefreshTokenRequest struct {
@@ -40,7 +44,9 @@ func (r *RefreshTokenRequest) Validate() error {
 }
 
 type RefreshTokenResponse struct {
-	Token string `json:"token" example:"eyJhbGciOi..."`
+	Token        string `json:"token" example:"eyJhbGciOi..."`
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOi..."`
+	ExpiresIn    int64  `json:"expires_in" example:"900"`
 }
 
 type ForgotPasswordRequest struct {
@@ -52,10 +58,43 @@ func (r *ForgotPasswordRequest) Validate() error {
 }
 
 type ResetPasswordRequest struct {
-	Token       string `json:"token" validate:"required" example:"abc123"`
-	NewPassword string `json:"new_password" validate:"required,min=8,max=255" example:"newpassword123"`
+	Token               string `json:"token" validate:"required" example:"abc123"`
+	NewPassword         string `json:"new_password" validate:"required,max=255" example:"correct-horse-battery"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions" example:"true"`
 }
 
 func (r *ResetPasswordRequest) Validate() error {
 	return validate.Struct(r)
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" example:"abc123"`
+}
+
+func (r *VerifyEmailRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john@example.com"`
+}
+
+func (r *MagicLinkRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" validate:"required" example:"abc123"`
+}
+
+func (r *MagicLinkVerifyRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john@example.com"`
+}
+
+func (r *ResendVerificationRequest) Validate() error {
+	return validate.Struct(r)
+}
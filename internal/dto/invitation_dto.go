@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+// CreateInvitationRequest invites an email address into the organization.
+// ExpiresInHours overrides the configured invitation lifetime.
+type CreateInvitationRequest struct {
+	Email          string `json:"email" validate:"required,email,max=255" example:"jane@example.com"`
+	Role           string `json:"role" validate:"omitempty,oneof=owner admin member" example:"member"`
+	ExpiresInHours int    `json:"expires_in_hours" validate:"omitempty,min=1,max=720" example:"168"`
+}
+
+func (r *CreateInvitationRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type InvitationTokenRequest struct {
+	Token string `json:"token" validate:"required" example:"abc123"`
+}
+
+func (r *InvitationTokenRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// RegisterInvitationRequest creates an account for the invited email address
+// and accepts the invitation
+type RegisterInvitationRequest struct {
+	Token     string  `json:"token" validate:"required" example:"abc123"`
+	Password  string  `json:"password" validate:"required,max=255" example:"correct-horse-battery"`
+	FirstName string  `json:"first_name" validate:"required,min=2,max=120" example:"Jane"`
+	LastName  *string `json:"last_name" validate:"omitempty,max=120" example:"Doe"`
+}
+
+func (r *RegisterInvitationRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// InvitationResponse is an invitation as seen by the organization's admins.
+// Status is one of pending, accepted, revoked or expired.
+type InvitationResponse struct {
+	ID          uint       `json:"id" example:"1"`
+	Email       string     `json:"email" example:"jane@example.com"`
+	Role        string     `json:"role" example:"member"`
+	Status      string     `json:"status" example:"pending"`
+	InvitedByID *uint      `json:"invited_by_id,omitempty" example:"1"`
+	ExpiresAt   time.Time  `json:"expires_at" example:"2024-01-08T00:00:00Z"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// InvitationPreviewResponse describes a pending invitation to whoever holds
+// its token. AccountExists tells the client whether to sign in or register.
+type InvitationPreviewResponse struct {
+	OrganizationName string    `json:"organization_name" example:"Acme Inc"`
+	OrganizationSlug string    `json:"organization_slug" example:"acme"`
+	Email            string    `json:"email" example:"jane@example.com"`
+	Role             string    `json:"role" example:"member"`
+	ExpiresAt        time.Time `json:"expires_at" example:"2024-01-08T00:00:00Z"`
+	AccountExists    bool      `json:"account_exists" example:"false"`
+}
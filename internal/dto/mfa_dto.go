@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled" example:"true"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining" example:"10"`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/App:john@example.com?secret=JBSWY3DPEHPK3PXP&issuer=App"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k3j9d-a8s7q"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=32" example:"123456"`
+}
+
+func (r *MFACodeRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required" example:"eyJhbGciOi..."`
+	Code     string `json:"code" validate:"required,min=6,max=32" example:"123456"`
+}
+
+func (r *MFAVerifyRequest) Validate() error {
+	return validate.Struct(r)
+}
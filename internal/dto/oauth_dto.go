@@ -0,0 +1,22 @@
+package dto
+
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers" example:"google,github"`
+}
+
+// OAuthAuthorizeResponse carries the provider consent URL. The client keeps
+// StateToken and sends it back with the code and state from the callback.
+type OAuthAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://accounts.google.com/o/oauth2/v2/auth?..."`
+	StateToken       string `json:"state_token" example:"eyJhbGciOi..."`
+}
+
+type OAuthCallbackRequest struct {
+	Code       string `json:"code" validate:"required" example:"4/0AX4XfWh..."`
+	State      string `json:"state" validate:"required" example:"Xy3k..."`
+	StateToken string `json:"state_token" validate:"required" example:"eyJhbGciOi..."`
+}
+
+func (r *OAuthCallbackRequest) Validate() error {
+	return validate.Struct(r)
+}
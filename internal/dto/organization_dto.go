@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+// CreateOrganizationRequest creates an organization owned by the caller. The
+// slug names the organization's subdomain.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=120" example:"Acme Inc"`
+	Slug string `json:"slug" validate:"required,min=2,max=63,hostname_rfc1123,excludesall=." example:"acme"`
+}
+
+func (r *CreateOrganizationRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// AddOrganizationMemberRequest adds an existing user to the organization
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" validate:"required,email,max=255" example:"jane@example.com"`
+	Role  string `json:"role" validate:"omitempty,oneof=owner admin member" example:"member"`
+}
+
+func (r *AddOrganizationMemberRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member" example:"admin"`
+}
+
+func (r *UpdateOrganizationMemberRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// OrganizationResponse is an organization as seen by one of its members;
+// Role is the caller's role in it
+type OrganizationResponse struct {
+	ID        uint      `json:"id" example:"1"`
+	Name      string    `json:"name" example:"Acme Inc"`
+	Slug      string    `json:"slug" example:"acme"`
+	Role      string    `json:"role" example:"owner"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type OrganizationMemberResponse struct {
+	UserID    uint                 `json:"user_id" example:"1"`
+	Email     string               `json:"email" example:"jane@example.com"`
+	Role      string               `json:"role" example:"member"`
+	CreatedAt time.Time            `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	Profile   *UserProfileResponse `json:"profile,omitempty"`
+}
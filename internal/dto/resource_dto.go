@@ -23,11 +23,120 @@ func (r *UpdateResourceRequest) Validate() error {
 }
 
 type ResourceResponse struct {
+	ID             uint      `json:"id" example:"1"`
+	OrganizationID uint      `json:"organization_id" example:"1"`
+	Name           string    `json:"name" example:"Example Resource"`
+	Description    *string   `json:"description,omitempty" example:"A reusable sample resource"`
+	Status         string    `json:"status" example:"active"`
+	Version        uint      `json:"version" example:"1"`
+	CreatedByID    uint      `json:"created_by_id" example:"1"`
+	CreatedAt      time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt      time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// ShareResourceRequest shares a resource with a member by email, or with every
+// member holding an organization role. Sharing again changes the level.
+type ShareResourceRequest struct {
+	Email string `json:"email" validate:"required_without=Role,excluded_with=Role,omitempty,email,max=255" example:"jane@example.com"`
+	Role  string `json:"role" validate:"omitempty,oneof=owner admin member"`
+	Level string `json:"level" validate:"required,oneof=viewer editor" example:"viewer"`
+}
+
+func (r *ShareResourceRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// ResourceShareResponse is a grant to a user (UserID and Email) or to an
+// organization role (Role)
+type ResourceShareResponse struct {
 	ID          uint      `json:"id" example:"1"`
-	Name        string    `json:"name" example:"Example Resource"`
-	Description *string   `json:"description,omitempty" example:"A reusable sample resource"`
-	Status      string    `json:"status" example:"active"`
-	CreatedByID uint      `json:"created_by_id" example:"1"`
+	UserID      *uint     `json:"user_id,omitempty" example:"2"`
+	Email       string    `json:"email,omitempty" example:"jane@example.com"`
+	Role        *string   `json:"role,omitempty"`
+	Level       string    `json:"level" example:"viewer"`
+	GrantedByID *uint     `json:"granted_by_id,omitempty" example:"1"`
 	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
+
+// ResourceSearchResult is a resource matching a search. Highlight holds the
+// name and a snippet of the description, HTML escaped, with the matched terms
+// wrapped in <mark> tags.
+type ResourceSearchResult struct {
+	ResourceResponse
+	Highlight ResourceHighlight `json:"highlight"`
+}
+
+type ResourceHighlight struct {
+	Name        string `json:"name" example:"Quarterly <mark>report</mark>"`
+	Description string `json:"description" example:"…the <mark>report</mark> covers revenue…"`
+}
+
+// Bulk modes: atomic applies every item or none, partial applies the items
+// that succeed
+const (
+	BulkModeAtomic  = "atomic"
+	BulkModePartial = "partial"
+)
+
+// MaxBulkItems is how many items a bulk request takes; the handlers answer
+// 413 above it. Keep the max of the items validate tags in step.
+const MaxBulkItems = 1000
+
+// BulkCreateResourcesRequest creates resources; mode defaults to atomic.
+// Items are validated one by one and reported in the results.
+type BulkCreateResourcesRequest struct {
+	Mode  string                  `json:"mode" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+	Items []CreateResourceRequest `json:"items" validate:"required,min=1,max=1000"`
+}
+
+func (r *BulkCreateResourcesRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// BulkUpdateResourceItem updates one resource. With a version, the update
+// only applies while the resource is still at it, like If-Match.
+type BulkUpdateResourceItem struct {
+	ID      uint  `json:"id" validate:"required" example:"1"`
+	Version *uint `json:"version" example:"3"`
+	UpdateResourceRequest
+}
+
+func (r *BulkUpdateResourceItem) Validate() error {
+	return validate.Struct(r)
+}
+
+type BulkUpdateResourcesRequest struct {
+	Mode  string                   `json:"mode" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+	Items []BulkUpdateResourceItem `json:"items" validate:"required,min=1,max=1000"`
+}
+
+func (r *BulkUpdateResourcesRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+type BulkDeleteResourcesRequest struct {
+	Mode string `json:"mode" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+	IDs  []uint `json:"ids" validate:"required,min=1,max=1000" example:"1,2,3"`
+}
+
+func (r *BulkDeleteResourcesRequest) Validate() error {
+	return validate.Struct(r)
+}
+
+// BulkItemResult is the outcome of one item, by its index in the request.
+// Status is the HTTP status the item would get on its own; in atomic mode,
+// items that were not applied because another one failed get 424.
+type BulkItemResult struct {
+	Index  int    `json:"index" example:"0"`
+	ID     uint   `json:"id,omitempty" example:"1"`
+	Status int    `json:"status" example:"201"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkResourcesResponse struct {
+	Mode      string           `json:"mode" example:"atomic"`
+	Succeeded int              `json:"succeeded" example:"2"`
+	Failed    int              `json:"failed" example:"0"`
+	Results   []BulkItemResult `json:"results"`
+}
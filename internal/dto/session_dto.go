@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type SessionResponse struct {
+	ID         uint       `json:"id" example:"12"`
+	UserAgent  string     `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)"`
+	IPAddress  string     `json:"ip_address" example:"203.0.113.7"`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty" example:"2024-01-01T00:15:00Z"`
+	ExpiresAt  time.Time  `json:"expires_at" example:"2024-01-08T00:00:00Z"`
+	Current    bool       `json:"current" example:"true"`
+}
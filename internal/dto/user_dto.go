@@ -12,8 +12,9 @@ func (r *UpdateProfileRequest) Validate() error {
 }
 
 type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password" validate:"required,min=1" example:"oldpassword123"`
-	NewPassword string `json:"new_password" validate:"required,min=8,max=255,nefield=OldPassword" example:"newpassword123"`
+	OldPassword         string `json:"old_password" validate:"required,min=1" example:"oldpassword123"`
+	NewPassword         string `json:"new_password" validate:"required,max=255,nefield=OldPassword" example:"correct-horse-battery"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions" example:"true"`
 }
 
 func (r *ChangePasswordRequest) Validate() error {
@@ -28,7 +29,7 @@ type UserProfileResponse struct {
 type UserResponse struct {
 	ID        uint                 `json:"id" example:"1"`
 	Email     string               `json:"email" example:"john@example.com"`
-	Role      string               `json:"role" example:"user"`
+	Roles     []string             `json:"roles" example:"user"`
 	IsActive  bool                 `json:"is_active" example:"true"`
 	CreatedAt time.Time            `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt time.Time            `json:"updated_at" example:"2024-01-01T00:00:00Z"`
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type Admin struct {
+	lockoutService   services.LockoutService
+	adminUserService services.AdminUserService
+	roleService      services.RoleService
+}
+
+func NewAdmin(lockoutService services.LockoutService, adminUserService services.AdminUserService, roleService services.RoleService) *Admin {
+	return &Admin{lockoutService: lockoutService, adminUserService: adminUserService, roleService: roleService}
+}
+
+// ListUsers godoc
+//
+//	@Summary		List users
+//	@Description	Search users by email or name and filter by role and status. With deleted=true only soft-deleted users are listed.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			search		query		string				false	"Search email, first or last name"
+//	@Param			role		query		string				false	"Filter by role"
+//	@Param			is_active	query		bool				false	"Filter by active status"
+//	@Param			deleted		query		bool				false	"List soft-deleted users"
+//	@Param			page		query		int					false	"Page number"
+//	@Param			limit		query		int					false	"Items per page"
+//	@Success		200			{object}	models.PaginatedResponse
+//	@Failure		400			{object}	models.APIResponse	"Invalid query"
+//	@Failure		403			{object}	models.APIResponse	"Access denied"
+//	@Router			/admin/users [get]
+func (h *Admin) ListUsers(c *fiber.Ctx) error {
+	var query dto.AdminUserListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return utils.BadRequestResponse(c, "Invalid query parameters")
+	}
+	if err := query.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		query.Limit = 10
+	}
+	users, total, err := h.adminUserService.ListUsers(&query)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Admin").Error("List users failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list users")
+	}
+	return utils.PaginatedResponse(c, "Users retrieved successfully", users, query.Page, query.Limit, total)
+}
+
+// GetUser godoc
+//
+//	@Summary		Get a user
+//	@Description	Get a user's account details, including soft-deleted users
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"User ID"
+//	@Success		200	{object}	models.APIResponse	"User retrieved successfully"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Failure		404	{object}	models.APIResponse	"User not found"
+//	@Router			/admin/users/{id} [get]
+func (h *Admin) GetUser(c *fiber.Ctx) error {
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	user, err := h.adminUserService.GetUser(userID)
+	if err != nil {
+		return adminUserError(c, err, "Get user", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "User retrieved successfully", user)
+}
+
+// UpdateUserRoles godoc
+//
+//	@Summary		Replace a user's roles
+//	@Description	Assign exactly the given roles to another user. The user is signed out on all devices so that new tokens carry the roles.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"User ID"
+//	@Param			request	body		dto.UpdateUserRolesRequest	true	"New roles"
+//	@Success		200		{object}	models.APIResponse			"User roles updated successfully"
+//	@Failure		400		{object}	models.APIResponse			"Unknown role"
+//	@Failure		403		{object}	models.APIResponse			"Access denied"
+//	@Failure		404		{object}	models.APIResponse			"User not found"
+//	@Failure		409		{object}	models.APIResponse			"Cannot change own roles"
+//	@Router			/admin/users/{id}/roles [put]
+func (h *Admin) UpdateUserRoles(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	var req dto.UpdateUserRolesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	user, err := h.adminUserService.SetRoles(adminID, userID, req.Roles)
+	if err != nil {
+		return adminUserError(c, err, "Update user roles", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "User roles updated successfully", user)
+}
+
+// ListRoles godoc
+//
+//	@Summary		List roles
+//	@Description	List the roles that can be assigned to users, with the permissions each grants
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Roles retrieved successfully"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Router			/admin/roles [get]
+func (h *Admin) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.roleService.ListRoles(c.UserContext())
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Admin").Error("List roles failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list roles")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Roles retrieved successfully", roles)
+}
+
+// UpdateUserStatus godoc
+//
+//	@Summary		Activate or deactivate a user
+//	@Description	Deactivated users cannot log in, are signed out on all devices and their API keys stop working
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"User ID"
+//	@Param			request	body		dto.UpdateUserStatusRequest	true	"New status"
+//	@Success		200		{object}	models.APIResponse			"User status updated successfully"
+//	@Failure		403		{object}	models.APIResponse			"Access denied"
+//	@Failure		404		{object}	models.APIResponse			"User not found"
+//	@Failure		409		{object}	models.APIResponse			"Cannot change own status"
+//	@Router			/admin/users/{id}/status [put]
+func (h *Admin) UpdateUserStatus(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	var req dto.UpdateUserStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	user, err := h.adminUserService.SetActive(adminID, userID, *req.IsActive)
+	if err != nil {
+		return adminUserError(c, err, "Update user status", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "User status updated successfully", user)
+}
+
+// SendPasswordReset godoc
+//
+//	@Summary		Send a password reset email
+//	@Description	Email the user a password reset link. The current password keeps working until the reset is completed.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"User ID"
+//	@Success		200	{object}	models.APIResponse	"Password reset email sent"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Failure		404	{object}	models.APIResponse	"User not found"
+//	@Failure		409	{object}	models.APIResponse	"User account is inactive"
+//	@Failure		503	{object}	models.APIResponse	"Email service not configured"
+//	@Router			/admin/users/{id}/password-reset [post]
+func (h *Admin) SendPasswordReset(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if err := h.adminUserService.SendPasswordReset(adminID, userID); err != nil {
+		return adminUserError(c, err, "Send password reset", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Password reset email sent", nil)
+}
+
+// DeleteUser godoc
+//
+//	@Summary		Delete a user
+//	@Description	Soft-delete a user and sign them out on all devices. The user can be restored later.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"User ID"
+//	@Success		200	{object}	models.APIResponse	"User deleted successfully"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Failure		404	{object}	models.APIResponse	"User not found"
+//	@Failure		409	{object}	models.APIResponse	"Cannot delete own account"
+//	@Router			/admin/users/{id} [delete]
+func (h *Admin) DeleteUser(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if err := h.adminUserService.DeleteUser(adminID, userID); err != nil {
+		return adminUserError(c, err, "Delete user", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "User deleted successfully", nil)
+}
+
+// RestoreUser godoc
+//
+//	@Summary		Restore a deleted user
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"User ID"
+//	@Success		200	{object}	models.APIResponse	"User restored successfully"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Failure		404	{object}	models.APIResponse	"User not found"
+//	@Failure		409	{object}	models.APIResponse	"User is not deleted"
+//	@Router			/admin/users/{id}/restore [post]
+func (h *Admin) RestoreUser(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	user, err := h.adminUserService.RestoreUser(adminID, userID)
+	if err != nil {
+		return adminUserError(c, err, "Restore user", userID)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "User restored successfully", user)
+}
+
+// UnlockUser godoc
+//
+//	@Summary		Unlock a user account
+//	@Description	Lift a lockout caused by failed login attempts and reset the failure counter
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"User ID"
+//	@Success		200	{object}	models.APIResponse	"Account unlocked successfully"
+//	@Failure		403	{object}	models.APIResponse	"Access denied"
+//	@Failure		404	{object}	models.APIResponse	"User not found"
+//	@Router			/admin/users/{id}/unlock [post]
+func (h *Admin) UnlockUser(c *fiber.Ctx) error {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if err := h.lockoutService.Unlock(userID, adminID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return utils.NotFoundResponse(c, "User not found")
+		}
+		utils.LogCtx(c.UserContext(), "Admin").Error("Unlock user failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to unlock user")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Account unlocked successfully", nil)
+}
+
+// adminUserError maps AdminUserService errors to responses
+func adminUserError(c *fiber.Ctx, err error, action string, userID uint) error {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return utils.NotFoundResponse(c, "User not found")
+	case errors.Is(err, services.ErrCannotModifySelf):
+		return utils.ConflictResponse(c, "You cannot change your own roles, status or account")
+	case errors.Is(err, services.ErrUnknownRole):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrUserNotDeleted):
+		return utils.ConflictResponse(c, "User is not deleted")
+	case errors.Is(err, services.ErrInactiveAccount):
+		return utils.ConflictResponse(c, "User account is inactive")
+	case errors.Is(err, services.ErrPasswordResetDisabled):
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "Email service is not configured")
+	}
+	utils.LogCtx(c.UserContext(), "Admin").Error(action+" failed", "user_id", userID, "error", err)
+	return utils.InternalErrorResponse(c, "Failed to "+strings.ToLower(action))
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type APIKey struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKey(apiKeyService services.APIKeyService) *APIKey {
+	return &APIKey{apiKeyService: apiKeyService}
+}
+
+// ListAPIKeys godoc
+//
+//	@Summary		List API keys
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"API keys retrieved successfully"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/user/api-keys [get]
+func (h *APIKey) ListAPIKeys(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	keys, err := h.apiKeyService.List(userID)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "APIKey").Error("List API keys failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list API keys")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "API keys retrieved successfully", keys)
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Create an API key
+//	@Description	Create a personal access token for machine clients. The key is returned only once; send it as a bearer token or in the X-API-Key header. Keys without scopes have the same access as the user.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.CreateAPIKeyRequest	true	"Key name, scopes and expiry"
+//	@Success		201		{object}	models.APIResponse		"API key created successfully"
+//	@Failure		400		{object}	models.APIResponse		"Invalid request"
+//	@Failure		403		{object}	models.APIResponse		"Not available with api key authentication"
+//	@Failure		409		{object}	models.APIResponse		"API key limit reached"
+//	@Router			/user/api-keys [post]
+func (h *APIKey) CreateAPIKey(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	key, err := h.apiKeyService.Create(userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAPIKeyExpiry):
+			return utils.BadRequestResponse(c, err.Error())
+		case errors.Is(err, services.ErrAPIKeyLimitReached):
+			return utils.ConflictResponse(c, err.Error())
+		}
+		utils.LogCtx(c.UserContext(), "APIKey").Error("Create API key failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to create API key")
+	}
+	return utils.CreatedResponse(c, "API key created successfully", key)
+}
+
+// RevokeAPIKey godoc
+//
+//	@Summary		Revoke an API key
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"API key ID"
+//	@Success		200	{object}	models.APIResponse	"API key revoked successfully"
+//	@Failure		404	{object}	models.APIResponse	"API key not found"
+//	@Router			/user/api-keys/{id} [delete]
+func (h *APIKey) RevokeAPIKey(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid API key ID")
+	}
+	if err := h.apiKeyService.Revoke(userID, id); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			return utils.NotFoundResponse(c, "API key not found")
+		}
+		utils.LogCtx(c.UserContext(), "APIKey").Error("Revoke API key failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to revoke API key")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "API key revoked successfully", nil)
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
 	"go-fiber-boilerplate/pkg/utils"
 )
@@ -26,7 +29,7 @@ func NewAuth(authService services.AuthService) *Auth {
 //	@Produce		json
 //	@Param			request	body		dto.RegisterRequest	true	"Registration request"
 //	@Success		201		{object}	models.APIResponse	"User registered successfully"
-//	@Failure		400		{object}	models.APIResponse	"Invalid request"
+//	@Failure		400		{object}	models.APIResponse	"Invalid request or password rejected by the password policy"
 //	@Failure		409		{object}	models.APIResponse	"Email already registered"
 //	@Router			/auth/register [post]
 func (h *Auth) Register(c *fiber.Ctx) error {
@@ -43,6 +46,9 @@ func (h *Auth) Register(c *fiber.Ctx) error {
 		if errors.Is(err, services.ErrEmailAlreadyRegistered) {
 			return utils.ConflictResponse(c, "Email already registered")
 		}
+		if errors.Is(err, services.ErrPasswordPolicy) {
+			return passwordPolicyResponse(c, err)
+		}
 		utils.LogCtx(c.UserContext(), "Auth").Error("Registration failed", "error", err)
 		return utils.InternalErrorResponse(c, "Failed to register user")
 	}
@@ -53,7 +59,7 @@ func (h *Auth) Register(c *fiber.Ctx) error {
 // Login godoc
 //
 //	@Summary		User login
-//	@Description	Authenticate user and return access and refresh tokens
+//	@Description	Authenticate user and return access and refresh tokens. When two-factor authentication is enabled the response only carries mfa_required and mfa_token, which must be completed through /auth/mfa/verify.
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
@@ -61,6 +67,9 @@ func (h *Auth) Register(c *fiber.Ctx) error {
 //	@Success		200		{object}	models.APIResponse	"Login successful"
 //	@Failure		400		{object}	models.APIResponse	"Invalid request"
 //	@Failure		401		{object}	models.APIResponse	"Invalid credentials"
+//	@Failure		403		{object}	models.APIResponse	"Email address is not verified"
+//	@Failure		429		{object}	models.APIResponse	"Account temporarily locked"
+//	@Header			429		{integer}	Retry-After			"Seconds until the next attempt is allowed"
 //	@Router			/auth/login [post]
 func (h *Auth) Login(c *fiber.Ctx) error {
 	var req dto.LoginRequest
@@ -70,20 +79,67 @@ func (h *Auth) Login(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	resp, err := h.authService.Login(&req)
+	resp, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidCredentials) || errors.Is(err, services.ErrInactiveAccount) {
 			return utils.UnauthorizedResponse(c, err.Error())
 		}
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			return utils.ForbiddenResponse(c, err.Error())
+		}
+		if errors.Is(err, services.ErrAccountLocked) {
+			return accountLockedResponse(c, err)
+		}
 		utils.LogCtx(c.UserContext(), "Auth").Error("Login failed", "email", req.Email, "error", err)
 		return utils.InternalErrorResponse(c, "Failed to login")
 	}
+	if resp.MFARequired {
+		return utils.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication required", resp)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Login successful", resp)
+}
+
+// VerifyMFA godoc
+//
+//	@Summary		Complete two-factor login
+//	@Description	Exchange the mfa_token returned by login and a TOTP or recovery code for access and refresh tokens
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.MFAVerifyRequest	true	"Challenge token and code"
+//	@Success		200		{object}	models.APIResponse		"Login successful"
+//	@Failure		400		{object}	models.APIResponse		"Invalid request"
+//	@Failure		401		{object}	models.APIResponse		"Invalid token or code"
+//	@Failure		429		{object}	models.APIResponse		"Account temporarily locked"
+//	@Header			429		{integer}	Retry-After				"Seconds until the next attempt is allowed"
+//	@Router			/auth/mfa/verify [post]
+func (h *Auth) VerifyMFA(c *fiber.Ctx) error {
+	var req dto.MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	resp, err := h.authService.VerifyMFA(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFAToken) || errors.Is(err, services.ErrInvalidMFACode) ||
+			errors.Is(err, services.ErrMFANotEnabled) || errors.Is(err, services.ErrInactiveAccount) {
+			return utils.UnauthorizedResponse(c, err.Error())
+		}
+		if errors.Is(err, services.ErrAccountLocked) {
+			return accountLockedResponse(c, err)
+		}
+		utils.LogCtx(c.UserContext(), "Auth").Error("MFA verification failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to login")
+	}
 	return utils.SuccessResponse(c, fiber.StatusOK, "Login successful", resp)
 }
 
 // RefreshToken godoc
 //
 //	@Summary		Refresh access token
+//	@Description	Rotate the refresh token and issue a new token pair. Reusing an already rotated refresh token revokes the whole session.
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
@@ -99,11 +155,62 @@ func (h *Auth) RefreshToken(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	token, err := h.authService.RefreshToken(req.RefreshToken)
+	resp, err := h.authService.RefreshToken(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) || errors.Is(err, services.ErrInactiveAccount) {
+			return utils.UnauthorizedResponse(c, err.Error())
+		}
+		utils.LogCtx(c.UserContext(), "Auth").Error("Refresh token failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to refresh token")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Token refreshed successfully", resp)
+}
+
+// Logout godoc
+//
+//	@Summary		Logout current session
+//	@Description	Revoke the session behind the current access token and denylist the token
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Logged out successfully"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/auth/logout [post]
+func (h *Auth) Logout(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	tokenID, expiresAt := middleware.GetTokenFromContext(c)
+	if err := h.authService.Logout(userID, middleware.GetSessionIDFromContext(c), tokenID, expiresAt); err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Error("Logout failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to logout")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll godoc
+//
+//	@Summary		Logout all sessions
+//	@Description	Revoke every session of the current user on all devices
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Logged out from all sessions"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/auth/logout-all [post]
+func (h *Auth) LogoutAll(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		return utils.UnauthorizedResponse(c, err.Error())
+		return utils.UnauthorizedResponse(c, "Invalid user")
 	}
-	return utils.SuccessResponse(c, fiber.StatusOK, "Token refreshed successfully", dto.RefreshTokenResponse{Token: token})
+	tokenID, expiresAt := middleware.GetTokenFromContext(c)
+	if err := h.authService.LogoutAll(userID, tokenID, expiresAt); err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Error("Logout all failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to logout")
+	}
+	utils.LogCtx(c.UserContext(), "Auth").Info("User logged out from all sessions", "user_id", userID)
+	return utils.SuccessResponse(c, fiber.StatusOK, "Logged out from all sessions", nil)
 }
 
 // ForgotPassword godoc
@@ -136,12 +243,13 @@ func (h *Auth) ForgotPassword(c *fiber.Ctx) error {
 // ResetPassword godoc
 //
 //	@Summary		Reset password
+//	@Description	Set a new password with a reset token. All previously issued tokens stop working; revoke_other_sessions also signs out every session.
 //	@Tags			Authentication
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		dto.ResetPasswordRequest	true	"Reset token and new password"
 //	@Success		200		{object}	models.APIResponse			"Password reset successful"
-//	@Failure		400		{object}	models.APIResponse			"Invalid token or request"
+//	@Failure		400		{object}	models.APIResponse			"Invalid token, request, or password rejected by the password policy"
 //	@Router			/auth/reset-password [post]
 func (h *Auth) ResetPassword(c *fiber.Ctx) error {
 	var req dto.ResetPasswordRequest
@@ -151,12 +259,160 @@ func (h *Auth) ResetPassword(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	if err := h.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
+	if err := h.authService.ResetPassword(req.Token, req.NewPassword, req.RevokeOtherSessions); err != nil {
 		if errors.Is(err, services.ErrInvalidResetToken) {
 			return utils.BadRequestResponse(c, "Invalid or expired reset token")
 		}
+		if errors.Is(err, services.ErrPasswordPolicy) {
+			return passwordPolicyResponse(c, err)
+		}
 		utils.LogCtx(c.UserContext(), "Auth").Error("Reset password failed", "error", err)
 		return utils.InternalErrorResponse(c, "Failed to reset password")
 	}
 	return utils.SuccessResponse(c, fiber.StatusOK, "Password reset successful", nil)
 }
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify email address
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.VerifyEmailRequest	true	"Verification token"
+//	@Success		200		{object}	models.APIResponse		"Email verified successfully"
+//	@Failure		400		{object}	models.APIResponse		"Invalid token or request"
+//	@Router			/auth/verify-email [post]
+func (h *Auth) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerifyToken) {
+			return utils.BadRequestResponse(c, "Invalid or expired verification token")
+		}
+		utils.LogCtx(c.UserContext(), "Auth").Error("Email verification failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to verify email")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Email verified successfully", nil)
+}
+
+// RequestMagicLink godoc
+//
+//	@Summary		Request a magic login link
+//	@Description	Email a single-use sign-in link. The response is the same whether or not the address has an account.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.MagicLinkRequest	true	"Email address"
+//	@Success		200		{object}	models.APIResponse		"Login link sent if email exists"
+//	@Failure		429		{object}	models.APIResponse		"Too many requests for this email"
+//	@Router			/auth/magic-link [post]
+func (h *Auth) RequestMagicLink(c *fiber.Ctx) error {
+	var req dto.MagicLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err := h.authService.RequestMagicLink(req.Email); err != nil {
+		if errors.Is(err, services.ErrMagicLinkDisabled) {
+			return utils.SuccessResponse(c, fiber.StatusOK, "If the email exists, a login link will be sent", nil)
+		}
+		utils.LogCtx(c.UserContext(), "Auth").Error("Magic link request failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to process request")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "If the email exists, a login link will be sent", nil)
+}
+
+// VerifyMagicLink godoc
+//
+//	@Summary		Log in with a magic link
+//	@Description	Exchange the token from a magic link for access and refresh tokens. When two-factor authentication is enabled the response carries an mfa_token instead.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.MagicLinkVerifyRequest	true	"Login link token"
+//	@Success		200		{object}	models.APIResponse			"Login successful"
+//	@Failure		400		{object}	models.APIResponse			"Invalid or expired link"
+//	@Failure		401		{object}	models.APIResponse			"Inactive account"
+//	@Router			/auth/magic-link/verify [post]
+func (h *Auth) VerifyMagicLink(c *fiber.Ctx) error {
+	var req dto.MagicLinkVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	resp, err := h.authService.LoginWithMagicLink(req.Token, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMagicLink) {
+			return utils.BadRequestResponse(c, "Invalid or expired login link")
+		}
+		if errors.Is(err, services.ErrInactiveAccount) {
+			return utils.UnauthorizedResponse(c, err.Error())
+		}
+		utils.LogCtx(c.UserContext(), "Auth").Error("Magic link login failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to login")
+	}
+	if resp.MFARequired {
+		return utils.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication required", resp)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Login successful", resp)
+}
+
+// ResendVerification godoc
+//
+//	@Summary		Resend verification email
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ResendVerificationRequest	true	"Email address"
+//	@Success		200		{object}	models.APIResponse				"Verification email sent if the account needs it"
+//	@Router			/auth/resend-verification [post]
+func (h *Auth) ResendVerification(c *fiber.Ctx) error {
+	var req dto.ResendVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err := h.authService.ResendVerification(req.Email); err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Error("Resend verification failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to process request")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "If the account exists and is unverified, a verification link will be sent", nil)
+}
+
+// accountLockedResponse answers with 429 and tells the client when to retry
+func accountLockedResponse(c *fiber.Ctx, err error) error {
+	var locked *services.AccountLockedError
+	if errors.As(err, &locked) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(locked.RetryAfterSeconds()))
+	}
+	return utils.TooManyRequestsResponse(c, err.Error())
+}
+
+// passwordPolicyResponse answers with 400 and one field error per violated rule
+func passwordPolicyResponse(c *fiber.Ctx, err error) error {
+	var policyErr *services.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	fieldErrors := make([]models.FieldError, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		fieldErrors[i] = models.FieldError{Field: policyErr.Field, Code: v.Code, Message: v.Message}
+	}
+	return utils.ValidationErrorResponse(c, services.ErrPasswordPolicy.Error(), fieldErrors)
+}
+
+// clientInfo describes the device a session is created or refreshed from
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	return services.ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), IPAddress: c.IP()}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type Invitation struct {
+	invitationService services.InvitationService
+}
+
+func NewInvitation(invitationService services.InvitationService) *Invitation {
+	return &Invitation{invitationService: invitationService}
+}
+
+// ListInvitations godoc
+//
+//	@Summary		List organization invitations
+//	@Description	List the organization's invitations, newest first. Requires the owner or admin role.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Organization ID"
+//	@Success		200	{object}	models.APIResponse	"Invitations retrieved successfully"
+//	@Failure		403	{object}	models.APIResponse	"Insufficient organization role"
+//	@Router			/organizations/{id}/invitations [get]
+func (h *Invitation) ListInvitations(c *fiber.Ctx) error {
+	invitations, err := h.invitationService.List(middleware.GetOrganizationIDFromContext(c))
+	if err != nil {
+		return invitationError(c, err, "List invitations")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// CreateInvitation godoc
+//
+//	@Summary		Invite someone by email
+//	@Description	Email a single-use invitation link. Requires the owner or admin role; only owners can invite owners.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"Organization ID"
+//	@Param			request	body		dto.CreateInvitationRequest	true	"Invitation"
+//	@Success		201		{object}	models.APIResponse			"Invitation sent successfully"
+//	@Failure		403		{object}	models.APIResponse			"Insufficient organization role"
+//	@Failure		409		{object}	models.APIResponse			"Already a member or invitation pending"
+//	@Failure		502		{object}	models.APIResponse			"Invitation email could not be sent; the invitation was revoked"
+//	@Failure		503		{object}	models.APIResponse			"Email service is not configured"
+//	@Router			/organizations/{id}/invitations [post]
+func (h *Invitation) CreateInvitation(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	invitation, err := h.invitationService.Create(actorID, middleware.GetOrganizationIDFromContext(c), &req)
+	if err != nil {
+		return invitationError(c, err, "Create invitation")
+	}
+	return utils.CreatedResponse(c, "Invitation sent successfully", invitation)
+}
+
+// ResendInvitation godoc
+//
+//	@Summary		Resend an invitation
+//	@Description	Email a fresh link and restart the expiry; the previous link stops working. Expired invitations can be resent.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		int					true	"Organization ID"
+//	@Param			invitationId	path		int					true	"Invitation ID"
+//	@Success		200				{object}	models.APIResponse	"Invitation resent successfully"
+//	@Failure		404				{object}	models.APIResponse	"Invitation not found"
+//	@Failure		409				{object}	models.APIResponse	"Invitation already accepted or revoked"
+//	@Failure		502				{object}	models.APIResponse	"Invitation email could not be sent"
+//	@Router			/organizations/{id}/invitations/{invitationId}/resend [post]
+func (h *Invitation) ResendInvitation(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	invitationID, err := parseIDParam(c, "invitationId")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid invitation ID")
+	}
+	invitation, err := h.invitationService.Resend(actorID, middleware.GetOrganizationIDFromContext(c), invitationID)
+	if err != nil {
+		return invitationError(c, err, "Resend invitation")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Invitation resent successfully", invitation)
+}
+
+// RevokeInvitation godoc
+//
+//	@Summary		Revoke an invitation
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		int					true	"Organization ID"
+//	@Param			invitationId	path		int					true	"Invitation ID"
+//	@Success		200				{object}	models.APIResponse	"Invitation revoked successfully"
+//	@Failure		404				{object}	models.APIResponse	"Invitation not found"
+//	@Failure		409				{object}	models.APIResponse	"Invitation already accepted"
+//	@Router			/organizations/{id}/invitations/{invitationId} [delete]
+func (h *Invitation) RevokeInvitation(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	invitationID, err := parseIDParam(c, "invitationId")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid invitation ID")
+	}
+	if err := h.invitationService.Revoke(actorID, middleware.GetOrganizationIDFromContext(c), invitationID); err != nil {
+		return invitationError(c, err, "Revoke invitation")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Invitation revoked successfully", nil)
+}
+
+// LookupInvitation godoc
+//
+//	@Summary		Look up an invitation
+//	@Description	Describe a pending invitation by its token. account_exists tells whether to sign in and accept, or to register.
+//	@Tags			Invitations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.InvitationTokenRequest	true	"Invitation token"
+//	@Success		200		{object}	models.APIResponse			"Invitation retrieved successfully"
+//	@Failure		400		{object}	models.APIResponse			"Invalid or expired invitation"
+//	@Router			/invitations/lookup [post]
+func (h *Invitation) LookupInvitation(c *fiber.Ctx) error {
+	var req dto.InvitationTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	preview, err := h.invitationService.Lookup(req.Token)
+	if err != nil {
+		return invitationError(c, err, "Look up invitation")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Invitation retrieved successfully", preview)
+}
+
+// AcceptInvitation godoc
+//
+//	@Summary		Accept an invitation
+//	@Description	Join the organization with the signed-in account. The invitation must have been sent to the account's email address.
+//	@Tags			Invitations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.InvitationTokenRequest	true	"Invitation token"
+//	@Success		200		{object}	models.APIResponse			"Invitation accepted successfully"
+//	@Failure		400		{object}	models.APIResponse			"Invalid or expired invitation"
+//	@Failure		403		{object}	models.APIResponse			"Invitation was sent to a different email address"
+//	@Failure		409		{object}	models.APIResponse			"Already a member"
+//	@Router			/invitations/accept [post]
+func (h *Invitation) AcceptInvitation(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.InvitationTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	organization, err := h.invitationService.Accept(userID, req.Token)
+	if err != nil {
+		return invitationError(c, err, "Accept invitation")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Invitation accepted successfully", organization)
+}
+
+// RegisterInvitation godoc
+//
+//	@Summary		Register from an invitation
+//	@Description	Create an account for the invited email address and join the organization. The email address is verified by the invitation.
+//	@Tags			Invitations
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RegisterInvitationRequest	true	"Registration"
+//	@Success		201		{object}	models.APIResponse				"User registered successfully"
+//	@Failure		400		{object}	models.APIResponse				"Invalid or expired invitation, or password rejected by the password policy"
+//	@Failure		409		{object}	models.APIResponse				"Email already registered"
+//	@Router			/invitations/register [post]
+func (h *Invitation) RegisterInvitation(c *fiber.Ctx) error {
+	var req dto.RegisterInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	user, err := h.invitationService.Register(&req)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailAlreadyRegistered) {
+			return utils.ConflictResponse(c, "Email already registered; sign in to accept the invitation")
+		}
+		if errors.Is(err, services.ErrPasswordPolicy) {
+			return passwordPolicyResponse(c, err)
+		}
+		return invitationError(c, err, "Register from invitation")
+	}
+	utils.LogCtx(c.UserContext(), "Invitation").Info("User registered from invitation", "user_id", user.ID, "email", user.Email)
+	return utils.CreatedResponse(c, "User registered successfully", user)
+}
+
+func invitationError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidInvitation):
+		return utils.BadRequestResponse(c, "Invalid or expired invitation")
+	case errors.Is(err, services.ErrInvitationNotFound):
+		return utils.NotFoundResponse(c, "Invitation not found")
+	case errors.Is(err, services.ErrOrganizationNotFound):
+		return utils.NotFoundResponse(c, "Organization not found")
+	case errors.Is(err, services.ErrNotOrganizationMember):
+		return utils.ForbiddenResponse(c, "not a member of the organization")
+	case errors.Is(err, services.ErrOrganizationForbidden):
+		return utils.ForbiddenResponse(c, "insufficient organization role")
+	case errors.Is(err, services.ErrInvitationEmailMismatch):
+		return utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrAlreadyMember), errors.Is(err, services.ErrInvitationPending), errors.Is(err, services.ErrInvitationClosed):
+		return utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, services.ErrInvitationEmailDisabled):
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "Email service is not configured")
+	case errors.Is(err, services.ErrInvitationEmailFailed):
+		return utils.ErrorResponse(c, fiber.StatusBadGateway, "Invitation email could not be sent")
+	}
+	utils.LogCtx(c.UserContext(), "Invitation").Error(action+" failed", "organization_id", middleware.GetOrganizationIDFromContext(c), "error", err)
+	return utils.InternalErrorResponse(c, "Failed to "+strings.ToLower(action))
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/pkg/jwt"
+)
+
+type JWKS struct {
+	tokenManager *jwt.TokenManager
+}
+
+func NewJWKS(tokenManager *jwt.TokenManager) *JWKS {
+	return &JWKS{tokenManager: tokenManager}
+}
+
+// GetJWKS godoc
+//
+//	@Summary		JSON Web Key Set
+//	@Description	Public keys for verifying access tokens issued by this API. Empty when tokens are signed with HS256.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	jwt.JWKSet
+//	@Router			/.well-known/jwks.json [get]
+func (h *JWKS) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.tokenManager.Keyring().JWKS())
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/jwt"
+)
+
+func TestGetJWKSPublishesPublicKeys(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.AssertNoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	testutil.AssertNoError(t, err)
+	key, err := jwt.ParseKeyPEM("2024-01", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	testutil.AssertNoError(t, err)
+	keyring, err := jwt.NewKeyring(key)
+	testutil.AssertNoError(t, err)
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", NewJWKS(jwt.NewTokenManagerWithKeyring(keyring)).GetJWKS)
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/.well-known/jwks.json", nil))
+	testutil.AssertNoError(t, err)
+	defer resp.Body.Close()
+	testutil.AssertEqual(t, fiber.StatusOK, resp.StatusCode)
+	testutil.AssertEqual(t, "public, max-age=300", resp.Header.Get(fiber.HeaderCacheControl))
+	var set jwt.JWKSet
+	testutil.ParseJSONResponse(t, resp.Body, &set)
+	testutil.AssertLen(t, set.Keys, 1)
+	testutil.AssertEqual(t, "2024-01", set.Keys[0].Kid)
+	testutil.AssertEqual(t, "EC", set.Keys[0].Kty)
+	testutil.AssertEqual(t, jwt.AlgorithmES256, set.Keys[0].Alg)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type MFA struct {
+	mfaService services.MFAService
+}
+
+func NewMFA(mfaService services.MFAService) *MFA {
+	return &MFA{mfaService: mfaService}
+}
+
+// GetStatus godoc
+//
+//	@Summary		Get two-factor authentication status
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Status retrieved successfully"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/user/mfa [get]
+func (h *MFA) GetStatus(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	status, err := h.mfaService.Status(userID)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "MFA").Error("Get MFA status failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to get two-factor status")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Status retrieved successfully", status)
+}
+
+// Enroll godoc
+//
+//	@Summary		Start TOTP enrollment
+//	@Description	Generate a new TOTP secret and otpauth URI. The secret only becomes active after it is confirmed with a first code.
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Enrollment started"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Failure		409	{object}	models.APIResponse	"Already enabled"
+//	@Failure		503	{object}	models.APIResponse	"MFA not configured"
+//	@Router			/user/mfa/enroll [post]
+func (h *MFA) Enroll(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	resp, err := h.mfaService.BeginEnrollment(userID)
+	if err != nil {
+		return h.handleError(c, userID, "Start MFA enrollment failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Enrollment started", resp)
+}
+
+// Confirm godoc
+//
+//	@Summary		Confirm TOTP enrollment
+//	@Description	Activate two-factor authentication with a first code. The returned recovery codes are shown only once.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MFACodeRequest	true	"TOTP code"
+//	@Success		200		{object}	models.APIResponse	"Two-factor authentication enabled"
+//	@Failure		400		{object}	models.APIResponse	"Invalid code"
+//	@Failure		409		{object}	models.APIResponse	"Already enabled"
+//	@Router			/user/mfa/confirm [post]
+func (h *MFA) Confirm(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	resp, err := h.mfaService.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		return h.handleError(c, userID, "Confirm MFA enrollment failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication enabled", resp)
+}
+
+// RegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace all recovery codes. Requires a current TOTP or recovery code.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MFACodeRequest	true	"TOTP or recovery code"
+//	@Success		200		{object}	models.APIResponse	"Recovery codes regenerated"
+//	@Failure		400		{object}	models.APIResponse	"Invalid code"
+//	@Router			/user/mfa/recovery-codes [post]
+func (h *MFA) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	resp, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		return h.handleError(c, userID, "Regenerate recovery codes failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Recovery codes regenerated", resp)
+}
+
+// Disable godoc
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Remove the TOTP secret and all recovery codes. Requires a current TOTP or recovery code.
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.MFACodeRequest	true	"TOTP or recovery code"
+//	@Success		200		{object}	models.APIResponse	"Two-factor authentication disabled"
+//	@Failure		400		{object}	models.APIResponse	"Invalid code"
+//	@Router			/user/mfa/disable [post]
+func (h *MFA) Disable(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err := h.mfaService.Disable(userID, req.Code); err != nil {
+		return h.handleError(c, userID, "Disable MFA failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *MFA) handleError(c *fiber.Ctx, userID uint, logMessage string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrMFANotEnabled):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		return utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, services.ErrMFANotConfigured):
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, services.ErrUserNotFound):
+		return utils.NotFoundResponse(c, "User not found")
+	}
+	utils.LogCtx(c.UserContext(), "MFA").Error(logMessage, "user_id", userID, "error", err)
+	return utils.InternalErrorResponse(c, "Failed to process two-factor request")
+}
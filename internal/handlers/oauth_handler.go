@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type OAuth struct {
+	oauthService services.OAuthService
+}
+
+func NewOAuth(oauthService services.OAuthService) *OAuth {
+	return &OAuth{oauthService: oauthService}
+}
+
+// ListProviders godoc
+//
+//	@Summary		List OAuth providers
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	models.APIResponse	"Providers retrieved successfully"
+//	@Router			/auth/oauth/providers [get]
+func (h *OAuth) ListProviders(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, fiber.StatusOK, "Providers retrieved successfully", dto.OAuthProvidersResponse{
+		Providers: h.oauthService.Providers(),
+	})
+}
+
+// Authorize godoc
+//
+//	@Summary		Start OAuth login
+//	@Description	Return the provider consent URL and a state token. Keep the state token and post it with the code and state the provider redirects back with.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Param			provider	path		string				true	"Provider name"
+//	@Success		200			{object}	models.APIResponse	"Authorization started"
+//	@Failure		404			{object}	models.APIResponse	"Provider not configured"
+//	@Router			/auth/oauth/{provider}/authorize [get]
+func (h *OAuth) Authorize(c *fiber.Ctx) error {
+	resp, err := h.oauthService.Authorize(c.UserContext(), c.Params("provider"), 0)
+	if err != nil {
+		return h.handleError(c, "Start OAuth login failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Authorization started", resp)
+}
+
+// Callback godoc
+//
+//	@Summary		Complete OAuth login
+//	@Description	Exchange the authorization code for access and refresh tokens. New accounts are registered without a password.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string						true	"Provider name"
+//	@Param			request		body		dto.OAuthCallbackRequest	true	"Code, state and state token"
+//	@Success		200			{object}	models.APIResponse			"Login successful"
+//	@Failure		400			{object}	models.APIResponse			"Invalid request or state"
+//	@Failure		401			{object}	models.APIResponse			"Login failed"
+//	@Failure		409			{object}	models.APIResponse			"Email already registered"
+//	@Router			/auth/oauth/{provider}/callback [post]
+func (h *OAuth) Callback(c *fiber.Ctx) error {
+	var req dto.OAuthCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	resp, err := h.oauthService.Login(c.UserContext(), c.Params("provider"), &req, clientInfo(c))
+	if err != nil {
+		return h.handleError(c, "OAuth login failed", err)
+	}
+	if resp.MFARequired {
+		return utils.SuccessResponse(c, fiber.StatusOK, "Two-factor authentication required", resp)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Login successful", resp)
+}
+
+// ListIdentities godoc
+//
+//	@Summary		List linked accounts
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Identities retrieved successfully"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/user/identities [get]
+func (h *OAuth) ListIdentities(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	identities, err := h.oauthService.ListIdentities(userID)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "OAuth").Error("List identities failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list linked accounts")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Identities retrieved successfully", identities)
+}
+
+// AuthorizeLink godoc
+//
+//	@Summary		Start linking a provider account
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			provider	path		string				true	"Provider name"
+//	@Success		200			{object}	models.APIResponse	"Authorization started"
+//	@Failure		404			{object}	models.APIResponse	"Provider not configured"
+//	@Router			/user/identities/{provider}/authorize [get]
+func (h *OAuth) AuthorizeLink(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	resp, err := h.oauthService.Authorize(c.UserContext(), c.Params("provider"), userID)
+	if err != nil {
+		return h.handleError(c, "Start OAuth link failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Authorization started", resp)
+}
+
+// Link godoc
+//
+//	@Summary		Link a provider account
+//	@Tags			Users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			provider	path		string						true	"Provider name"
+//	@Param			request		body		dto.OAuthCallbackRequest	true	"Code, state and state token"
+//	@Success		200			{object}	models.APIResponse			"Account linked successfully"
+//	@Failure		400			{object}	models.APIResponse			"Invalid request or state"
+//	@Failure		409			{object}	models.APIResponse			"Already linked to another user"
+//	@Router			/user/identities/{provider}/callback [post]
+func (h *OAuth) Link(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.OAuthCallbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	identity, err := h.oauthService.Link(c.UserContext(), userID, c.Params("provider"), &req)
+	if err != nil {
+		return h.handleError(c, "OAuth link failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Account linked successfully", identity)
+}
+
+// Unlink godoc
+//
+//	@Summary		Unlink a provider account
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Identity ID"
+//	@Success		200	{object}	models.APIResponse	"Account unlinked successfully"
+//	@Failure		404	{object}	models.APIResponse	"Identity not found"
+//	@Failure		409	{object}	models.APIResponse	"Only remaining login method"
+//	@Router			/user/identities/{id} [delete]
+func (h *OAuth) Unlink(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid identity ID")
+	}
+	if err := h.oauthService.Unlink(userID, id); err != nil {
+		return h.handleError(c, "OAuth unlink failed", err)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Account unlinked successfully", nil)
+}
+
+func (h *OAuth) handleError(c *fiber.Ctx, logMessage string, err error) error {
+	switch {
+	case errors.Is(err, services.ErrOAuthProviderNotFound), errors.Is(err, services.ErrIdentityNotFound):
+		return utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, services.ErrInvalidOAuthState), errors.Is(err, services.ErrOAuthEmailRequired):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrOAuthExchangeFailed), errors.Is(err, services.ErrInactiveAccount):
+		return utils.UnauthorizedResponse(c, err.Error())
+	case errors.Is(err, services.ErrEmailNotVerified):
+		return utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrOAuthAccountExists), errors.Is(err, services.ErrIdentityAlreadyLinked),
+		errors.Is(err, services.ErrLastLoginMethod):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	utils.LogCtx(c.UserContext(), "OAuth").Error(logMessage, "provider", c.Params("provider"), "error", err)
+	return utils.InternalErrorResponse(c, "Failed to process OAuth request")
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type Organization struct {
+	organizationService services.OrganizationService
+}
+
+func NewOrganization(organizationService services.OrganizationService) *Organization {
+	return &Organization{organizationService: organizationService}
+}
+
+// ListOrganizations godoc
+//
+//	@Summary		List organizations
+//	@Description	List the organizations the user is a member of, with the user's role in each
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Organizations retrieved successfully"
+//	@Router			/organizations [get]
+func (h *Organization) ListOrganizations(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	organizations, err := h.organizationService.ListForUser(userID)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Organization").Error("List organizations failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list organizations")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Organizations retrieved successfully", organizations)
+}
+
+// CreateOrganization godoc
+//
+//	@Summary		Create an organization
+//	@Description	Create an organization owned by the user. The slug doubles as the organization's subdomain.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		dto.CreateOrganizationRequest	true	"Organization"
+//	@Success		201		{object}	models.APIResponse				"Organization created successfully"
+//	@Failure		400		{object}	models.APIResponse				"Invalid request"
+//	@Failure		409		{object}	models.APIResponse				"Slug already taken"
+//	@Router			/organizations [post]
+func (h *Organization) CreateOrganization(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	organization, err := h.organizationService.Create(userID, &req)
+	if err != nil {
+		return organizationError(c, err, "Create organization")
+	}
+	return utils.CreatedResponse(c, "Organization created successfully", organization)
+}
+
+// GetOrganization godoc
+//
+//	@Summary		Get an organization
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Organization ID"
+//	@Success		200	{object}	models.APIResponse	"Organization retrieved successfully"
+//	@Failure		403	{object}	models.APIResponse	"Not a member of the organization"
+//	@Router			/organizations/{id} [get]
+func (h *Organization) GetOrganization(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	organization, err := h.organizationService.Get(userID, middleware.GetOrganizationIDFromContext(c))
+	if err != nil {
+		return organizationError(c, err, "Get organization")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Organization retrieved successfully", organization)
+}
+
+// SwitchOrganization godoc
+//
+//	@Summary		Switch the active organization
+//	@Description	Make the organization the active one of the current session and issue a new token pair whose access token carries it. Requests without X-Organization-ID use the active organization.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Organization ID"
+//	@Success		200	{object}	models.APIResponse	"Organization switched successfully"
+//	@Failure		403	{object}	models.APIResponse	"Not a member of the organization"
+//	@Router			/organizations/{id}/switch [post]
+func (h *Organization) SwitchOrganization(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	sessionID := middleware.GetSessionIDFromContext(c)
+	if sessionID == 0 {
+		return utils.BadRequestResponse(c, "Switching organizations requires a session token")
+	}
+	tokens, err := h.organizationService.Switch(userID, sessionID, middleware.GetOrganizationIDFromContext(c))
+	if err != nil {
+		return organizationError(c, err, "Switch organization")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Organization switched successfully", tokens)
+}
+
+// ListMembers godoc
+//
+//	@Summary		List organization members
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Organization ID"
+//	@Success		200	{object}	models.APIResponse	"Members retrieved successfully"
+//	@Failure		403	{object}	models.APIResponse	"Not a member of the organization"
+//	@Router			/organizations/{id}/members [get]
+func (h *Organization) ListMembers(c *fiber.Ctx) error {
+	members, err := h.organizationService.ListMembers(middleware.GetOrganizationIDFromContext(c))
+	if err != nil {
+		return organizationError(c, err, "List members")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Members retrieved successfully", members)
+}
+
+// AddMember godoc
+//
+//	@Summary		Add an organization member
+//	@Description	Add an existing user by email. Requires the owner or admin role; only owners can add owners.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Organization ID"
+//	@Param			request	body		dto.AddOrganizationMemberRequest	true	"Member"
+//	@Success		201		{object}	models.APIResponse					"Member added successfully"
+//	@Failure		403		{object}	models.APIResponse					"Insufficient organization role"
+//	@Failure		404		{object}	models.APIResponse					"User not found"
+//	@Failure		409		{object}	models.APIResponse					"Already a member"
+//	@Router			/organizations/{id}/members [post]
+func (h *Organization) AddMember(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.AddOrganizationMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	member, err := h.organizationService.AddMember(actorID, middleware.GetOrganizationIDFromContext(c), &req)
+	if err != nil {
+		return organizationError(c, err, "Add member")
+	}
+	return utils.CreatedResponse(c, "Member added successfully", member)
+}
+
+// UpdateMember godoc
+//
+//	@Summary		Change a member's role
+//	@Description	Requires the owner or admin role; only owners can grant or revoke ownership. The last owner cannot be demoted.
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int									true	"Organization ID"
+//	@Param			userId	path		int									true	"User ID"
+//	@Param			request	body		dto.UpdateOrganizationMemberRequest	true	"New role"
+//	@Success		200		{object}	models.APIResponse					"Member updated successfully"
+//	@Failure		403		{object}	models.APIResponse					"Insufficient organization role"
+//	@Failure		404		{object}	models.APIResponse					"Member not found"
+//	@Failure		409		{object}	models.APIResponse					"Last owner"
+//	@Router			/organizations/{id}/members/{userId} [put]
+func (h *Organization) UpdateMember(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	var req dto.UpdateOrganizationMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	member, err := h.organizationService.UpdateMemberRole(actorID, middleware.GetOrganizationIDFromContext(c), userID, req.Role)
+	if err != nil {
+		return organizationError(c, err, "Update member")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Member updated successfully", member)
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove a member
+//	@Description	Owners and admins remove other members; any member can remove themselves to leave. The last owner cannot leave.
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Organization ID"
+//	@Param			userId	path		int					true	"User ID"
+//	@Success		200		{object}	models.APIResponse	"Member removed successfully"
+//	@Failure		403		{object}	models.APIResponse	"Insufficient organization role"
+//	@Failure		404		{object}	models.APIResponse	"Member not found"
+//	@Failure		409		{object}	models.APIResponse	"Last owner"
+//	@Router			/organizations/{id}/members/{userId} [delete]
+func (h *Organization) RemoveMember(c *fiber.Ctx) error {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	userID, err := parseIDParam(c, "userId")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if err := h.organizationService.RemoveMember(actorID, middleware.GetOrganizationIDFromContext(c), userID); err != nil {
+		return organizationError(c, err, "Remove member")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Member removed successfully", nil)
+}
+
+func organizationError(c *fiber.Ctx, err error, action string) error {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound):
+		return utils.NotFoundResponse(c, "Organization not found")
+	case errors.Is(err, services.ErrMemberNotFound):
+		return utils.NotFoundResponse(c, "Member not found")
+	case errors.Is(err, services.ErrUserNotFound):
+		return utils.NotFoundResponse(c, "User not found")
+	case errors.Is(err, services.ErrSessionNotFound):
+		return utils.NotFoundResponse(c, "Session not found")
+	case errors.Is(err, services.ErrNotOrganizationMember):
+		return utils.ForbiddenResponse(c, "not a member of the organization")
+	case errors.Is(err, services.ErrOrganizationForbidden):
+		return utils.ForbiddenResponse(c, "insufficient organization role")
+	case errors.Is(err, services.ErrSlugTaken), errors.Is(err, services.ErrAlreadyMember), errors.Is(err, services.ErrLastOwner):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	utils.LogCtx(c.UserContext(), "Organization").Error(action+" failed", "organization_id", middleware.GetOrganizationIDFromContext(c), "error", err)
+	return utils.InternalErrorResponse(c, "Failed to "+strings.ToLower(action))
+}
@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"go-fiber-boilerplate/internal/dto"
 	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/listquery"
 	"go-fiber-boilerplate/pkg/utils"
+	"go-fiber-boilerplate/pkg/versioning"
 )
 
 type Resource struct {
@@ -22,12 +27,25 @@ func NewResource(resourceService services.ResourceService) *Resource {
 // ListResources godoc
 //
 //	@Summary		List resources
+//	@Description	Members see the resources they created or that were shared with them; organization owners and admins see all of them. Pages by page and limit, or, when cursor is present, by cursor and limit with a models.CursorPaginatedResponse.
 //	@Tags			Resources
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			page	query		int	false	"Page number"
-//	@Param			limit	query		int	false	"Items per page"
-//	@Success		200		{object}	models.PaginatedResponse
+//	@Param			X-Organization-ID	header		int					false	"Active organization; defaults to the token's organization"
+//	@Param			page				query		int					false	"Page number"
+//	@Param			limit				query		int					false	"Items per page"
+//	@Param			cursor				query		string				false	"next_cursor or prev_cursor of the previous page; empty for the first page"
+//	@Param			count				query		bool				false	"With cursor, also return the total"
+//	@Param			status				query		string				false	"Comma separated statuses, e.g. active,inactive"
+//	@Param			created_by			query		string				false	"Comma separated creator IDs; me is the caller"
+//	@Param			created_after		query		string				false	"RFC 3339 timestamp or YYYY-MM-DD date, inclusive"
+//	@Param			created_before		query		string				false	"RFC 3339 timestamp or YYYY-MM-DD date, exclusive"
+//	@Param			updated_after		query		string				false	"RFC 3339 timestamp or YYYY-MM-DD date, inclusive"
+//	@Param			q					query		string				false	"Case-insensitive substring of the name or description"
+//	@Param			sort				query		string				false	"Comma separated keys among name, status, created_at and updated_at; prefix - for descending. Defaults to -created_at"
+//	@Success		200					{object}	models.PaginatedResponse
+//	@Failure		400					{object}	models.APIResponse	"Invalid query parameters or no active organization"
+//	@Failure		403					{object}	models.APIResponse	"Not a member of the organization"
 //	@Router			/resources [get]
 func (h *Resource) ListResources(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
@@ -38,7 +56,23 @@ func (h *Resource) ListResources(c *fiber.Ctx) error {
 	if limit < 1 || limit > 100 {
 		limit = 10
 	}
-	resources, total, err := h.resourceService.ListResources(page, limit)
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	query, err := services.ResourceListSpec.Parse(c.Queries(), userID)
+	if err != nil {
+		return listQueryError(c, err)
+	}
+	if query.CursorMode() {
+		resources, cursors, err := h.resourceService.ListResourcesByCursor(c.UserContext(), query, limit, c.QueryBool("count"))
+		if err != nil {
+			utils.LogCtx(c.UserContext(), "Resource").Error("List resources failed", "error", err)
+			return utils.InternalErrorResponse(c, "Failed to list resources")
+		}
+		return utils.CursorPaginatedResponse(c, "Resources retrieved successfully", resources, limit, cursors.NextCursor, cursors.PrevCursor, cursors.Total)
+	}
+	resources, total, err := h.resourceService.ListResources(c.UserContext(), query, page, limit)
 	if err != nil {
 		utils.LogCtx(c.UserContext(), "Resource").Error("List resources failed", "error", err)
 		return utils.InternalErrorResponse(c, "Failed to list resources")
@@ -49,25 +83,28 @@ func (h *Resource) ListResources(c *fiber.Ctx) error {
 // GetResource godoc
 //
 //	@Summary		Get resource
+//	@Description	Members get the resources they created or that were shared with them; organization owners and admins get any. Others are reported as not found. The ETag header carries the resource's version.
 //	@Tags			Resources
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			id	path		int	true	"Resource ID"
-//	@Success		200	{object}	models.APIResponse
-//	@Failure		404	{object}	models.APIResponse
+//	@Param			X-Organization-ID	header		int		false	"Active organization; defaults to the token's organization"
+//	@Param			If-None-Match		header		string	false	"ETag of a cached copy"
+//	@Param			id					path		int		true	"Resource ID"
+//	@Success		200					{object}	models.APIResponse
+//	@Success		304					"The cached copy is current"
+//	@Failure		404					{object}	models.APIResponse
 //	@Router			/resources/{id} [get]
 func (h *Resource) GetResource(c *fiber.Ctx) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
 		return utils.BadRequestResponse(c, "Invalid resource ID")
 	}
-	resource, err := h.resourceService.GetResource(id)
+	resource, err := h.resourceService.GetResource(c.UserContext(), id)
 	if err != nil {
-		if errors.Is(err, services.ErrResourceNotFound) {
-			return utils.NotFoundResponse(c, "Resource not found")
-		}
-		utils.LogCtx(c.UserContext(), "Resource").Error("Get resource failed", "id", id, "error", err)
-		return utils.InternalErrorResponse(c, "Failed to get resource")
+		return resourceError(c, err, "Get resource", id)
+	}
+	if middleware.SetETag(c, resource.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 	return utils.SuccessResponse(c, fiber.StatusOK, "Resource retrieved successfully", resource)
 }
@@ -79,8 +116,9 @@ func (h *Resource) GetResource(c *fiber.Ctx) error {
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			request	body		dto.CreateResourceRequest	true	"Resource creation data"
-//	@Success		201		{object}	models.APIResponse
+//	@Param			X-Organization-ID	header		int							false	"Active organization; defaults to the token's organization"
+//	@Param			request				body		dto.CreateResourceRequest	true	"Resource creation data"
+//	@Success		201					{object}	models.APIResponse
 //	@Router			/resources [post]
 func (h *Resource) CreateResource(c *fiber.Ctx) error {
 	userID, err := middleware.GetUserIDFromContext(c)
@@ -94,24 +132,32 @@ func (h *Resource) CreateResource(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	resource, err := h.resourceService.CreateResource(userID, &req)
+	resource, err := h.resourceService.CreateResource(c.UserContext(), userID, &req)
 	if err != nil {
 		utils.LogCtx(c.UserContext(), "Resource").Error("Create resource failed", "error", err)
 		return utils.InternalErrorResponse(c, "Failed to create resource")
 	}
+	middleware.SetETag(c, resource.Version)
 	return utils.CreatedResponse(c, "Resource created successfully", resource)
 }
 
 // UpdateResource godoc
 //
 //	@Summary		Update resource
+//	@Description	Members update the resources they created; organization owners and admins update any. Other resources get a 403, or a 404 when POLICY_HIDE_DENIED is set. The update only applies while the resource is at the ETag in If-Match, which is required unless IF_MATCH_REQUIRED is false.
 //	@Tags			Resources
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			id		path		int							true	"Resource ID"
-//	@Param			request	body		dto.UpdateResourceRequest	true	"Resource update data"
-//	@Success		200		{object}	models.APIResponse
+//	@Param			X-Organization-ID	header		int							false	"Active organization; defaults to the token's organization"
+//	@Param			If-Match			header		string						false	"ETag the resource must still have; required unless IF_MATCH_REQUIRED is false"
+//	@Param			id					path		int							true	"Resource ID"
+//	@Param			request				body		dto.UpdateResourceRequest	true	"Resource update data"
+//	@Success		200					{object}	models.APIResponse
+//	@Failure		403					{object}	models.APIResponse			"Not allowed to modify this resource"
+//	@Failure		404					{object}	models.APIResponse			"Resource not found"
+//	@Failure		412					{object}	models.APIResponse			"Resource was modified since the ETag in If-Match"
+//	@Failure		428					{object}	models.APIResponse			"If-Match header is missing"
 //	@Router			/resources/{id} [put]
 func (h *Resource) UpdateResource(c *fiber.Ctx) error {
 	id, err := parseIDParam(c, "id")
@@ -125,41 +171,336 @@ func (h *Resource) UpdateResource(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	resource, err := h.resourceService.UpdateResource(id, &req)
+	resource, err := h.resourceService.UpdateResource(c.UserContext(), id, &req)
 	if err != nil {
-		if errors.Is(err, services.ErrResourceNotFound) {
-			return utils.NotFoundResponse(c, "Resource not found")
-		}
-		utils.LogCtx(c.UserContext(), "Resource").Error("Update resource failed", "id", id, "error", err)
-		return utils.InternalErrorResponse(c, "Failed to update resource")
+		return resourceError(c, err, "Update resource", id)
 	}
+	middleware.SetETag(c, resource.Version)
 	return utils.SuccessResponse(c, fiber.StatusOK, "Resource updated successfully", resource)
 }
 
 // DeleteResource godoc
 //
 //	@Summary		Delete resource
+//	@Description	Members delete the resources they created; organization owners and admins delete any. Other resources get a 403, or a 404 when POLICY_HIDE_DENIED is set. The delete only applies while the resource is at the ETag in If-Match, which is required unless IF_MATCH_REQUIRED is false.
 //	@Tags			Resources
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			id	path		int	true	"Resource ID"
-//	@Success		200	{object}	models.APIResponse
+//	@Param			X-Organization-ID	header		int					false	"Active organization; defaults to the token's organization"
+//	@Param			If-Match			header		string				false	"ETag the resource must still have; required unless IF_MATCH_REQUIRED is false"
+//	@Param			id					path		int					true	"Resource ID"
+//	@Success		200					{object}	models.APIResponse
+//	@Failure		403					{object}	models.APIResponse	"Not allowed to modify this resource"
+//	@Failure		404					{object}	models.APIResponse	"Resource not found"
+//	@Failure		412					{object}	models.APIResponse	"Resource was modified since the ETag in If-Match"
+//	@Failure		428					{object}	models.APIResponse	"If-Match header is missing"
 //	@Router			/resources/{id} [delete]
 func (h *Resource) DeleteResource(c *fiber.Ctx) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
 		return utils.BadRequestResponse(c, "Invalid resource ID")
 	}
-	if err := h.resourceService.DeleteResource(id); err != nil {
-		if errors.Is(err, services.ErrResourceNotFound) {
-			return utils.NotFoundResponse(c, "Resource not found")
-		}
-		utils.LogCtx(c.UserContext(), "Resource").Error("Delete resource failed", "id", id, "error", err)
-		return utils.InternalErrorResponse(c, "Failed to delete resource")
+	if err := h.resourceService.DeleteResource(c.UserContext(), id); err != nil {
+		return resourceError(c, err, "Delete resource", id)
 	}
 	return utils.SuccessResponse(c, fiber.StatusOK, "Resource deleted successfully", nil)
 }
 
+// BulkCreateResources godoc
+//
+//	@Summary		Create resources in bulk
+//	@Description	Create up to 1000 resources. In atomic mode (the default) all are created or none is; in partial mode each valid item is created on its own. Each item is reported by its index with the status it would get on its own. Answers 201 when all were created, 207 when some failed in partial mode, and 422 when an atomic request was rolled back. Answers 413 above 1000 items, and 503 when a batch of a partial request could not be committed; the items before it stay applied.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int								false	"Active organization; defaults to the token's organization"
+//	@Param			request				body		dto.BulkCreateResourcesRequest	true	"Resources"
+//	@Success		201					{object}	models.APIResponse
+//	@Success		207					{object}	models.APIResponse				"Some items failed"
+//	@Failure		413					{object}	models.APIResponse				"More than 1000 items"
+//	@Failure		422					{object}	models.APIResponse				"Rolled back because an item failed"
+//	@Failure		503					{object}	models.APIResponse				"A partial batch could not be committed"
+//	@Router			/resources/bulk [post]
+func (h *Resource) BulkCreateResources(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	var req dto.BulkCreateResourcesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if len(req.Items) > dto.MaxBulkItems {
+		return bulkTooLarge(c)
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	results, err := h.resourceService.BulkCreate(c.UserContext(), userID, req.Items, req.Mode != dto.BulkModePartial)
+	if err != nil {
+		return bulkError(c, err, "Bulk create resources", "Failed to create resources")
+	}
+	return bulkResponse(c, req.Mode, results, fiber.StatusCreated, "Resources created")
+}
+
+// BulkUpdateResources godoc
+//
+//	@Summary		Update resources in bulk
+//	@Description	Update up to 1000 resources, with the same modes and statuses as bulk create. An item with a version only applies while the resource is at it, like If-Match, and otherwise gets 412.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int								false	"Active organization; defaults to the token's organization"
+//	@Param			request				body		dto.BulkUpdateResourcesRequest	true	"Updates"
+//	@Success		200					{object}	models.APIResponse
+//	@Success		207					{object}	models.APIResponse				"Some items failed"
+//	@Failure		413					{object}	models.APIResponse				"More than 1000 items"
+//	@Failure		422					{object}	models.APIResponse				"Rolled back because an item failed"
+//	@Failure		503					{object}	models.APIResponse				"A partial batch could not be committed"
+//	@Router			/resources/bulk [patch]
+func (h *Resource) BulkUpdateResources(c *fiber.Ctx) error {
+	var req dto.BulkUpdateResourcesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if len(req.Items) > dto.MaxBulkItems {
+		return bulkTooLarge(c)
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	results, err := h.resourceService.BulkUpdate(c.UserContext(), req.Items, req.Mode != dto.BulkModePartial)
+	if err != nil {
+		return bulkError(c, err, "Bulk update resources", "Failed to update resources")
+	}
+	return bulkResponse(c, req.Mode, results, fiber.StatusOK, "Resources updated")
+}
+
+// BulkDeleteResources godoc
+//
+//	@Summary		Delete resources in bulk
+//	@Description	Delete up to 1000 resources by ID, with the same modes and statuses as bulk create.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int								false	"Active organization; defaults to the token's organization"
+//	@Param			request				body		dto.BulkDeleteResourcesRequest	true	"Resource IDs"
+//	@Success		200					{object}	models.APIResponse
+//	@Success		207					{object}	models.APIResponse				"Some items failed"
+//	@Failure		413					{object}	models.APIResponse				"More than 1000 items"
+//	@Failure		422					{object}	models.APIResponse				"Rolled back because an item failed"
+//	@Failure		503					{object}	models.APIResponse				"A partial batch could not be committed"
+//	@Router			/resources/bulk [delete]
+func (h *Resource) BulkDeleteResources(c *fiber.Ctx) error {
+	var req dto.BulkDeleteResourcesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if len(req.IDs) > dto.MaxBulkItems {
+		return bulkTooLarge(c)
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	results, err := h.resourceService.BulkDelete(c.UserContext(), req.IDs, req.Mode != dto.BulkModePartial)
+	if err != nil {
+		return bulkError(c, err, "Bulk delete resources", "Failed to delete resources")
+	}
+	return bulkResponse(c, req.Mode, results, fiber.StatusOK, "Resources deleted")
+}
+
+// bulkTooLarge rejects a bulk request with more than dto.MaxBulkItems items
+func bulkTooLarge(c *fiber.Ctx) error {
+	return utils.ErrorResponse(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("A bulk request takes at most %d items; split it", dto.MaxBulkItems))
+}
+
+// bulkError answers a bulk request the service could not run. A partial
+// request stopped by a failed batch gets 503 and says which items were
+// applied; any other error gets 500.
+func bulkError(c *fiber.Ctx, err error, action, message string) error {
+	utils.LogCtx(c.UserContext(), "Resource").Error(action+" failed", "error", err)
+	var batchErr *services.BulkBatchError
+	if errors.As(err, &batchErr) {
+		return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, fmt.Sprintf("%s: the items before index %d were applied, retry the others", message, batchErr.Index))
+	}
+	return utils.InternalErrorResponse(c, message)
+}
+
+// bulkResponse reports the results of a bulk request. It answers okStatus
+// when every item succeeded, 207 when some failed in partial mode, and 422
+// when an atomic request was rolled back.
+func bulkResponse(c *fiber.Ctx, mode string, results []services.BulkResult, okStatus int, message string) error {
+	if mode == "" {
+		mode = dto.BulkModeAtomic
+	}
+	resp := dto.BulkResourcesResponse{Mode: mode, Results: make([]dto.BulkItemResult, len(results))}
+	for i, result := range results {
+		item := dto.BulkItemResult{Index: result.Index, ID: result.ID, Status: okStatus}
+		if result.Err != nil {
+			status, itemMessage, ok := resourceErrorStatus(result.Err)
+			if !ok {
+				utils.LogCtx(c.UserContext(), "Resource").Error("Bulk item failed", "index", result.Index, "id", result.ID, "error", result.Err)
+				status, itemMessage = fiber.StatusInternalServerError, "Internal error"
+			}
+			item.Status, item.Error = status, itemMessage
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results[i] = item
+	}
+
+	switch {
+	case resp.Failed == 0:
+		return utils.SuccessResponse(c, okStatus, message+" successfully", resp)
+	case mode == dto.BulkModePartial:
+		return utils.SuccessResponse(c, fiber.StatusMultiStatus, fmt.Sprintf("%s with %d of %d failed", message, resp.Failed, len(results)), resp)
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(models.APIResponse{
+		Status:  fiber.StatusUnprocessableEntity,
+		Message: "No changes were made because an item failed",
+		Error:   "No changes were made because an item failed",
+		Data:    resp,
+	})
+}
+
+// ListShares godoc
+//
+//	@Summary		List resource shares
+//	@Description	List the users and organization roles the resource is shared with. Requires being its creator or an organization owner or admin.
+//	@Tags			Resources
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int					false	"Active organization; defaults to the token's organization"
+//	@Param			id					path		int					true	"Resource ID"
+//	@Success		200					{object}	models.APIResponse	"Shares retrieved successfully"
+//	@Failure		403					{object}	models.APIResponse	"Not allowed to modify this resource"
+//	@Failure		404					{object}	models.APIResponse	"Resource not found"
+//	@Router			/resources/{id}/shares [get]
+func (h *Resource) ListShares(c *fiber.Ctx) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid resource ID")
+	}
+	shares, err := h.resourceService.ListShares(c.UserContext(), id)
+	if err != nil {
+		return resourceError(c, err, "List shares", id)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Shares retrieved successfully", shares)
+}
+
+// ShareResource godoc
+//
+//	@Summary		Share a resource
+//	@Description	Grant viewer or editor access to a member by email, or to every member holding an organization role. Sharing again with the same recipient changes the level. Recipients are notified by email.
+//	@Tags			Resources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int							false	"Active organization; defaults to the token's organization"
+//	@Param			id					path		int							true	"Resource ID"
+//	@Param			request				body		dto.ShareResourceRequest	true	"Share"
+//	@Success		200					{object}	models.APIResponse			"Resource shared successfully"
+//	@Failure		400					{object}	models.APIResponse			"Invalid request"
+//	@Failure		403					{object}	models.APIResponse			"Not allowed to modify this resource"
+//	@Failure		404					{object}	models.APIResponse			"Resource or member not found"
+//	@Router			/resources/{id}/shares [post]
+func (h *Resource) ShareResource(c *fiber.Ctx) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid resource ID")
+	}
+	var req dto.ShareResourceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if err := req.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	share, err := h.resourceService.Share(c.UserContext(), id, &req)
+	if err != nil {
+		return resourceError(c, err, "Share resource", id)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Resource shared successfully", share)
+}
+
+// RevokeShare godoc
+//
+//	@Summary		Revoke a resource share
+//	@Tags			Resources
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int					false	"Active organization; defaults to the token's organization"
+//	@Param			id					path		int					true	"Resource ID"
+//	@Param			shareId				path		int					true	"Share ID"
+//	@Success		200					{object}	models.APIResponse	"Share revoked successfully"
+//	@Failure		403					{object}	models.APIResponse	"Not allowed to modify this resource"
+//	@Failure		404					{object}	models.APIResponse	"Resource or share not found"
+//	@Router			/resources/{id}/shares/{shareId} [delete]
+func (h *Resource) RevokeShare(c *fiber.Ctx) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid resource ID")
+	}
+	shareID, err := parseIDParam(c, "shareId")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid share ID")
+	}
+	if err := h.resourceService.RevokeShare(c.UserContext(), id, shareID); err != nil {
+		return resourceError(c, err, "Revoke share", id)
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Share revoked successfully", nil)
+}
+
+func resourceError(c *fiber.Ctx, err error, action string, id uint) error {
+	if status, message, ok := resourceErrorStatus(err); ok {
+		return utils.ErrorResponse(c, status, message)
+	}
+	utils.LogCtx(c.UserContext(), "Resource").Error(action+" failed", "id", id, "error", err)
+	return utils.InternalErrorResponse(c, "Failed to "+strings.ToLower(action))
+}
+
+// resourceErrorStatus maps the errors of the resource service to a status
+// and message; ok is false for unexpected errors
+func resourceErrorStatus(err error) (status int, message string, ok bool) {
+	var invalid *services.InvalidItemError
+	switch {
+	case errors.Is(err, services.ErrResourceNotFound):
+		return fiber.StatusNotFound, "Resource not found", true
+	case errors.Is(err, services.ErrShareNotFound):
+		return fiber.StatusNotFound, "Share not found", true
+	case errors.Is(err, services.ErrMemberNotFound):
+		return fiber.StatusNotFound, "Member not found", true
+	case errors.Is(err, services.ErrResourceForbidden):
+		return fiber.StatusForbidden, err.Error(), true
+	case errors.Is(err, services.ErrShareWithCreator):
+		return fiber.StatusBadRequest, err.Error(), true
+	case errors.Is(err, versioning.ErrPreconditionFailed):
+		return fiber.StatusPreconditionFailed, "Resource was modified; fetch it again and retry", true
+	case errors.As(err, &invalid):
+		return fiber.StatusBadRequest, err.Error(), true
+	case errors.Is(err, services.ErrBulkAborted):
+		return fiber.StatusFailedDependency, err.Error(), true
+	}
+	return 0, "", false
+}
+
+// listQueryError reports the parameters rejected by a listquery.Spec
+func listQueryError(c *fiber.Ctx, err error) error {
+	var queryErr *listquery.Error
+	if !errors.As(err, &queryErr) {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	fieldErrors := make([]models.FieldError, len(queryErr.Fields))
+	for i, f := range queryErr.Fields {
+		fieldErrors[i] = models.FieldError{Field: f.Field, Code: f.Code, Message: f.Message}
+	}
+	return utils.ValidationErrorResponse(c, "Invalid query parameters", fieldErrors)
+}
+
 func parseIDParam(c *fiber.Ctx, name string) (uint, error) {
 	id, err := strconv.ParseUint(c.Params(name), 10, 32)
 	if err != nil {
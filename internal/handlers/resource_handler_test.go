@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/listquery"
+	"go-fiber-boilerplate/pkg/utils"
+	"go-fiber-boilerplate/pkg/versioning"
+)
+
+func TestResourceErrorStatuses(t *testing.T) {
+	utils.InitLogger()
+	for err, want := range map[error]int{
+		services.ErrResourceForbidden:                              fiber.StatusForbidden,
+		services.ErrResourceNotFound:                               fiber.StatusNotFound,
+		fmt.Errorf("update: %w", versioning.ErrPreconditionFailed): fiber.StatusPreconditionFailed,
+		errors.New("database is locked"):                           fiber.StatusInternalServerError,
+	} {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			return resourceError(c, err, "Update resource", 1)
+		})
+		resp, testErr := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		testutil.AssertNoError(t, testErr)
+		resp.Body.Close()
+		testutil.AssertEqual(t, want, resp.StatusCode, "%v", err)
+	}
+}
+
+func TestListQueryErrorReportsFields(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, err := services.ResourceListSpec.Parse(c.Queries(), 1); err != nil {
+			return listQueryError(c, err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	request := func(target string) (int, models.APIResponse) {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+		testutil.AssertNoError(t, err)
+		defer resp.Body.Close()
+		var body models.APIResponse
+		if resp.StatusCode != fiber.StatusOK {
+			testutil.ParseJSONResponse(t, resp.Body, &body)
+		}
+		return resp.StatusCode, body
+	}
+
+	status, _ := request("/?status=active&sort=-name&page=2&limit=5&created_by=me")
+	testutil.AssertEqual(t, fiber.StatusOK, status)
+
+	status, body := request("/?status=gone&sort=size&colour=red")
+	testutil.AssertEqual(t, fiber.StatusBadRequest, status)
+	testutil.AssertEqual(t, "validation_failed", body.Code)
+	testutil.AssertLen(t, body.Errors, 3)
+	codes := map[string]string{}
+	for _, e := range body.Errors {
+		codes[e.Field] = e.Code
+	}
+	testutil.AssertEqual(t, map[string]string{
+		"colour": listquery.CodeUnknownParameter,
+		"sort":   listquery.CodeUnknownSortField,
+		"status": listquery.CodeInvalidValue,
+	}, codes)
+
+	status, body = request("/?cursor=forged")
+	testutil.AssertEqual(t, fiber.StatusBadRequest, status)
+	testutil.AssertEqual(t, listquery.CursorParam, body.Errors[0].Field)
+}
+
+type bulkResponseBody struct {
+	Status int                       `json:"status"`
+	Data   dto.BulkResourcesResponse `json:"data"`
+}
+
+// respondBulk answers a request with bulkResponse and decodes the response
+func respondBulk(t *testing.T, mode string, results []services.BulkResult) (int, dto.BulkResourcesResponse) {
+	t.Helper()
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		return bulkResponse(c, mode, results, fiber.StatusCreated, "Resources created")
+	})
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/", nil))
+	testutil.AssertNoError(t, err)
+	defer resp.Body.Close()
+	var body bulkResponseBody
+	testutil.ParseJSONResponse(t, resp.Body, &body)
+	testutil.AssertEqual(t, resp.StatusCode, body.Status)
+	return resp.StatusCode, body.Data
+}
+
+func statuses(resp dto.BulkResourcesResponse) []int {
+	out := make([]int, len(resp.Results))
+	for i, result := range resp.Results {
+		out[i] = result.Status
+	}
+	return out
+}
+
+func TestBulkResponseStatuses(t *testing.T) {
+	utils.InitLogger()
+
+	status, resp := respondBulk(t, "", []services.BulkResult{{Index: 0, ID: 1}, {Index: 1, ID: 2}})
+	testutil.AssertEqual(t, fiber.StatusCreated, status)
+	testutil.AssertEqual(t, dto.BulkModeAtomic, resp.Mode)
+	testutil.AssertEqual(t, 2, resp.Succeeded)
+
+	status, resp = respondBulk(t, dto.BulkModePartial, []services.BulkResult{
+		{Index: 0, ID: 1},
+		{Index: 1, ID: 2, Err: versioning.ErrPreconditionFailed},
+		{Index: 2, Err: &services.InvalidItemError{Err: errors.New("name is required")}},
+	})
+	testutil.AssertEqual(t, fiber.StatusMultiStatus, status)
+	testutil.AssertEqual(t, []int{fiber.StatusCreated, fiber.StatusPreconditionFailed, fiber.StatusBadRequest}, statuses(resp))
+	testutil.AssertEqual(t, 1, resp.Succeeded)
+	testutil.AssertEqual(t, 2, resp.Failed)
+
+	status, resp = respondBulk(t, dto.BulkModeAtomic, []services.BulkResult{
+		{Index: 0, ID: 1, Err: services.ErrBulkAborted},
+		{Index: 1, ID: 2, Err: services.ErrResourceNotFound},
+	})
+	testutil.AssertEqual(t, fiber.StatusUnprocessableEntity, status)
+	testutil.AssertEqual(t, []int{fiber.StatusFailedDependency, fiber.StatusNotFound}, statuses(resp))
+}
+
+func TestBulkRequestErrors(t *testing.T) {
+	utils.InitLogger()
+	app := fiber.New()
+	// The size check comes before the service, which is never called
+	app.Delete("/bulk", NewResource(nil).BulkDeleteResources)
+	app.Get("/batch", func(c *fiber.Ctx) error {
+		return bulkError(c, &services.BulkBatchError{Index: 100, Err: errors.New("commit failed")}, "Bulk create resources", "Failed to create resources")
+	})
+	app.Get("/other", func(c *fiber.Ctx) error {
+		return bulkError(c, errors.New("database is locked"), "Bulk create resources", "Failed to create resources")
+	})
+
+	ids := make([]string, dto.MaxBulkItems+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i + 1)
+	}
+	request := httptest.NewRequest(fiber.MethodDelete, "/bulk", strings.NewReader(`{"ids":[`+strings.Join(ids, ",")+`]}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for target, want := range map[string]int{
+		"/batch": fiber.StatusServiceUnavailable,
+		"/other": fiber.StatusInternalServerError,
+	} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+		testutil.AssertNoError(t, err)
+		resp.Body.Close()
+		testutil.AssertEqual(t, want, resp.StatusCode, target)
+	}
+	resp, err := app.Test(request)
+	testutil.AssertNoError(t, err)
+	resp.Body.Close()
+	testutil.AssertEqual(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+}
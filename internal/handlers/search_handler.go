@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type Search struct {
+	searchService services.SearchService
+}
+
+func NewSearch(searchService services.SearchService) *Search {
+	return &Search{searchService: searchService}
+}
+
+// SearchResources godoc
+//
+//	@Summary		Search resources
+//	@Description	Full-text search over the names and descriptions of the resources the caller can view, best matches first. Every word must match. Each result carries highlight.name and a highlight.description snippet, HTML escaped, with matches wrapped in <mark> tags.
+//	@Tags			Resources
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			X-Organization-ID	header		int					false	"Active organization; defaults to the token's organization"
+//	@Param			q					query		string				true	"Search words"
+//	@Param			page				query		int					false	"Page number"
+//	@Param			limit				query		int					false	"Items per page"
+//	@Success		200					{object}	models.PaginatedResponse
+//	@Failure		400					{object}	models.APIResponse	"Missing or invalid search query"
+//	@Router			/resources/search [get]
+func (h *Search) SearchResources(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return utils.BadRequestResponse(c, "Search query is required")
+	}
+	if len(query) > 255 {
+		return utils.BadRequestResponse(c, "Search query must be at most 255 characters long")
+	}
+	results, total, err := h.searchService.SearchResources(c.UserContext(), query, page, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearch) {
+			return utils.BadRequestResponse(c, "Search query must contain a word")
+		}
+		utils.LogCtx(c.UserContext(), "Search").Error("Search resources failed", "error", err)
+		return utils.InternalErrorResponse(c, "Failed to search resources")
+	}
+	return utils.PaginatedResponse(c, "Resources retrieved successfully", results, page, limit, total)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+type Session struct {
+	sessionService services.SessionService
+}
+
+func NewSession(sessionService services.SessionService) *Session {
+	return &Session{sessionService: sessionService}
+}
+
+// ListSessions godoc
+//
+//	@Summary		List active sessions
+//	@Description	List the devices the user is logged in on. The session of the calling access token is marked as current.
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.APIResponse	"Sessions retrieved successfully"
+//	@Failure		401	{object}	models.APIResponse	"Unauthorized"
+//	@Router			/user/sessions [get]
+func (h *Session) ListSessions(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	sessions, err := h.sessionService.ListActive(userID, middleware.GetSessionIDFromContext(c))
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Session").Error("List sessions failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to list sessions")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+//
+//	@Summary		Revoke a session
+//	@Description	Log out a single device. Its refresh token stops working immediately.
+//	@Tags			Users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Session ID"
+//	@Success		200	{object}	models.APIResponse	"Session revoked successfully"
+//	@Failure		404	{object}	models.APIResponse	"Session not found"
+//	@Router			/user/sessions/{id} [delete]
+func (h *Session) RevokeSession(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		return utils.UnauthorizedResponse(c, "Invalid user")
+	}
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Invalid session ID")
+	}
+	if err := h.sessionService.RevokeForUser(userID, id, services.SessionRevokedByUser); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return utils.NotFoundResponse(c, "Session not found")
+		}
+		utils.LogCtx(c.UserContext(), "Session").Error("Revoke session failed", "user_id", userID, "session_id", id, "error", err)
+		return utils.InternalErrorResponse(c, "Failed to revoke session")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
@@ -12,10 +12,11 @@ import (
 
 type User struct {
 	userService services.UserService
+	authService services.AuthService
 }
 
-func NewUser(userService services.UserService) *User {
-	return &User{userService: userService}
+func NewUser(userService services.UserService, authService services.AuthService) *User {
+	return &User{userService: userService, authService: authService}
 }
 
 // GetProfile godoc
@@ -78,13 +79,14 @@ func (h *User) UpdateProfile(c *fiber.Ctx) error {
 // ChangePassword godoc
 //
 //	@Summary		Change user password
+//	@Description	Changing the password invalidates every token issued so far; revoke_other_sessions also signs out all other sessions. The response carries a new token pair for the current session.
 //	@Tags			Users
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			request	body		dto.ChangePasswordRequest	true	"Password change data"
 //	@Success		200		{object}	models.APIResponse			"Password changed successfully"
-//	@Failure		400		{object}	models.APIResponse			"Invalid request"
+//	@Failure		400		{object}	models.APIResponse			"Invalid request or password rejected by the password policy"
 //	@Failure		401		{object}	models.APIResponse			"Invalid current password"
 //	@Router			/user/change-password [post]
 func (h *User) ChangePassword(c *fiber.Ctx) error {
@@ -99,12 +101,25 @@ func (h *User) ChangePassword(c *fiber.Ctx) error {
 	if err := req.Validate(); err != nil {
 		return utils.BadRequestResponse(c, err.Error())
 	}
-	if err := h.userService.ChangePassword(userID, req.OldPassword, req.NewPassword); err != nil {
+	sessionID := middleware.GetSessionIDFromContext(c)
+	if err := h.userService.ChangePassword(userID, sessionID, &req); err != nil {
 		if errors.Is(err, services.ErrInvalidPassword) || errors.Is(err, services.ErrNoPasswordSet) {
 			return utils.UnauthorizedResponse(c, err.Error())
 		}
+		if errors.Is(err, services.ErrPasswordPolicy) {
+			return passwordPolicyResponse(c, err)
+		}
 		utils.LogCtx(c.UserContext(), "User").Error("Change password failed", "user_id", userID, "error", err)
 		return utils.InternalErrorResponse(c, "Failed to change password")
 	}
-	return utils.SuccessResponse(c, fiber.StatusOK, "Password changed successfully", nil)
+	// Tokens from external providers carry no session to keep signed in
+	if sessionID == 0 {
+		return utils.SuccessResponse(c, fiber.StatusOK, "Password changed successfully", nil)
+	}
+	tokens, err := h.authService.ReissueTokens(userID, sessionID)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "User").Error("Reissue tokens after password change failed", "user_id", userID, "error", err)
+		return utils.InternalErrorResponse(c, "Password changed, please log in again")
+	}
+	return utils.SuccessResponse(c, fiber.StatusOK, "Password changed successfully", tokens)
 }
@@ -1,41 +1,89 @@
 package middleware
 
 import (
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/services"
 	"go-fiber-boilerplate/pkg/jwt"
 	"go-fiber-boilerplate/pkg/utils"
 )
 
+var (
+	tokenManager        *jwt.TokenManager
+	tokenDenylist       *cache.TokenDenylist
+	tokenVersions       services.TokenVersionService
+	externalVerifier    *jwt.RemoteVerifier
+	externalAuthService services.ExternalAuthService
+	apiKeyService       services.APIKeyService
+	roleService         services.RoleService
+)
+
+func InitTokenManager(tm *jwt.TokenManager) {
+	tokenManager = tm
+}
+
+func InitTokenDenylist(denylist *cache.TokenDenylist) {
+	tokenDenylist = denylist
+}
+
+// InitTokenVersions enables rejecting access tokens issued before the user's
+// last password change.
+func InitTokenVersions(service services.TokenVersionService) {
+	tokenVersions = service
+}
+
+// InitExternalAuth enables accepting tokens from an external identity provider.
+// Tokens whose iss matches the verifier are checked against its remote JWKS.
+func InitExternalAuth(verifier *jwt.RemoteVerifier, service services.ExternalAuthService) {
+	externalVerifier = verifier
+	externalAuthService = service
+}
+
+// InitAPIKeyAuth enables authenticating with API keys, passed either as a
+// bearer token or in the X-API-Key header.
+func InitAPIKeyAuth(service services.APIKeyService) {
+	apiKeyService = service
+}
+
+// InitPermissions provides the permission lookup used by RequirePermissions
+func InitPermissions(service services.RoleService) {
+	roleService = service
+}
+
 func AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return utils.UnauthorizedResponse(c, "missing authorization header")
-		}
+		token := c.Get("X-API-Key")
+		if token == "" {
+			authHeader := c.Get("Authorization")
+			if authHeader == "" {
+				return utils.UnauthorizedResponse(c, "missing authorization header")
+			}
 
-		token, err := jwt.ExtractTokenFromHeader(authHeader)
-		if err != nil {
-			return utils.UnauthorizedResponse(c, "invalid authorization header format")
+			var err error
+			token, err = jwt.ExtractTokenFromHeader(authHeader)
+			if err != nil {
+				return utils.UnauthorizedResponse(c, "invalid authorization header format")
+			}
 		}
 
-		tm := jwt.NewTokenManager(config.AppConfig.JWTSecret)
-		claims, err := tm.ValidateAccessToken(token)
-		if err != nil {
+		if err := authenticate(c, token); err != nil {
 			return utils.UnauthorizedResponse(c, "invalid or expired token")
 		}
-
-		c.Locals("user_id", claims.UserID)
-		c.Locals("email", claims.Email)
-		c.Locals("role", claims.Role)
 		return c.Next()
 	}
 }
 
 func OptionalAuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get("X-API-Key"); apiKey != "" {
+			_ = authenticate(c, apiKey)
+			return c.Next()
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Next()
@@ -46,38 +94,213 @@ func OptionalAuthMiddleware() fiber.Handler {
 			return c.Next()
 		}
 
-		tm := jwt.NewTokenManager(config.AppConfig.JWTSecret)
-		claims, err := tm.ValidateAccessToken(token)
-		if err != nil {
-			return c.Next()
+		_ = authenticate(c, token)
+		return c.Next()
+	}
+}
+
+// authenticate validates the bearer token and populates the auth locals.
+func authenticate(c *fiber.Ctx, token string) error {
+	if strings.HasPrefix(token, services.APIKeyPrefix) {
+		return authenticateAPIKey(c, token)
+	}
+	if externalVerifier != nil && externalVerifier.Handles(token) {
+		return authenticateExternal(c, token)
+	}
+
+	claims, err := tokenManager.ValidateAccessToken(token)
+	if err != nil {
+		return err
+	}
+	if tokenDenylist.IsRevoked(c.UserContext(), claims.ID, claims.SessionID) {
+		return fiber.ErrUnauthorized
+	}
+	if tokenVersions != nil {
+		if err := tokenVersions.Check(c.UserContext(), claims.UserID, claims.TokenVersion); err != nil {
+			utils.LogCtx(c.UserContext(), "Auth").Debug("Access token rejected", "user_id", claims.UserID, "error", err)
+			return err
 		}
+	}
 
-		c.Locals("user_id", claims.UserID)
-		c.Locals("email", claims.Email)
-		c.Locals("role", claims.Role)
-		return c.Next()
+	c.Locals("user_id", claims.UserID)
+	c.Locals("email", claims.Email)
+	c.Locals("email_verified", claims.EmailVerified)
+	c.Locals("roles", claims.Roles)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("token_organization_id", claims.OrganizationID)
+	c.Locals("token_id", claims.ID)
+	if claims.ExpiresAt != nil {
+		c.Locals("token_expires_at", claims.ExpiresAt.Time)
+	}
+	c.Locals("auth_method", "jwt")
+	return nil
+}
+
+func authenticateExternal(c *fiber.Ctx, token string) error {
+	identity, err := externalVerifier.Verify(c.UserContext(), token)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Debug("External token rejected", "error", err)
+		return err
+	}
+	if tokenDenylist.IsRevoked(c.UserContext(), identity.TokenID, 0) {
+		return fiber.ErrUnauthorized
+	}
+	principal, err := externalAuthService.ResolvePrincipal(c.UserContext(), identity)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Warn("External identity not accepted", "issuer", identity.Issuer, "subject", identity.Subject, "error", err)
+		return err
 	}
+
+	c.Locals("user_id", principal.UserID)
+	c.Locals("email", principal.Email)
+	c.Locals("email_verified", principal.EmailVerified)
+	c.Locals("roles", principal.Roles)
+	c.Locals("token_id", identity.TokenID)
+	c.Locals("token_expires_at", identity.ExpiresAt)
+	c.Locals("auth_method", "external")
+	return nil
+}
+
+func authenticateAPIKey(c *fiber.Ctx, key string) error {
+	if apiKeyService == nil {
+		return fiber.ErrUnauthorized
+	}
+	principal, err := apiKeyService.Authenticate(c.UserContext(), key)
+	if err != nil {
+		utils.LogCtx(c.UserContext(), "Auth").Debug("API key rejected", "error", err)
+		return err
+	}
+
+	c.Locals("user_id", principal.UserID)
+	c.Locals("email", principal.Email)
+	c.Locals("email_verified", principal.EmailVerified)
+	c.Locals("roles", principal.Roles)
+	c.Locals("api_key_id", principal.KeyID)
+	c.Locals("scopes", principal.Scopes)
+	if principal.ExpiresAt != nil {
+		c.Locals("token_expires_at", *principal.ExpiresAt)
+	}
+	c.Locals("auth_method", "api_key")
+	if len(principal.Scopes) > 0 {
+		c.Locals(scopesPendingKey, true)
+	}
+	return nil
+}
+
+// scopesPendingKey marks a request made with a scoped API key that no
+// RequireScopes has admitted yet. Scoped keys are denied by default: until a
+// RequireScopes grants the route, the context getters report no user, so
+// routes without one reject the key.
+const scopesPendingKey = "scopes_pending"
+
+func scopesPending(c *fiber.Ctx) bool {
+	pending, _ := c.Locals(scopesPendingKey).(bool)
+	return pending
 }
 
 func AdminMiddleware() fiber.Handler {
 	return RequireRoles("admin")
 }
 
+// RequireRoles allows users holding at least one of the roles
 func RequireRoles(roles ...string) fiber.Handler {
 	allowed := make(map[string]bool, len(roles))
 	for _, role := range roles {
 		allowed[strings.ToLower(role)] = true
 	}
 	return func(c *fiber.Ctx) error {
-		role, _ := c.Locals("role").(string)
-		if !allowed[strings.ToLower(role)] {
+		held := GetRolesFromContext(c)
+		for _, role := range held {
+			if allowed[strings.ToLower(role)] {
+				return c.Next()
+			}
+		}
+		return utils.ForbiddenResponse(c, "access denied")
+	}
+}
+
+// RequirePermissions allows users whose roles grant all of the permissions.
+// Permissions are looked up per request through the cached RoleService, so
+// changes to a role apply without new tokens. API keys are further limited by
+// RequireScopes.
+func RequirePermissions(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, err := GetUserIDFromContext(c)
+		if err != nil || roleService == nil {
 			return utils.ForbiddenResponse(c, "access denied")
 		}
+		granted, err := roleService.Permissions(c.UserContext(), userID)
+		if err != nil {
+			utils.LogCtx(c.UserContext(), "Auth").Error("Resolve permissions failed", "user_id", userID, "error", err)
+			return utils.InternalErrorResponse(c, "Failed to check permissions")
+		}
+		for _, permission := range permissions {
+			if !slices.Contains(granted, strings.ToLower(permission)) {
+				return utils.ForbiddenResponse(c, "missing permission "+permission)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RequireVerifiedEmail rejects users whose email address has not been verified.
+// The flag comes from the access token, so users have to refresh their token
+// after verifying.
+func RequireVerifiedEmail() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if verified, _ := c.Locals("email_verified").(bool); !verified || scopesPending(c) {
+			return utils.ForbiddenResponse(c, "email address is not verified")
+		}
 		return c.Next()
 	}
 }
 
+// RequireScopes admits API keys granted all of the scopes. Scoped keys are
+// rejected on routes without it, so it has to run before anything that reads
+// the user, such as RequirePermissions. Keys without scopes and other
+// authentication methods have full access.
+func RequireScopes(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("auth_method") != "api_key" {
+			return c.Next()
+		}
+		granted, _ := c.Locals("scopes").([]string)
+		if len(granted) == 0 {
+			return c.Next()
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(granted, strings.ToLower(scope)) {
+				return utils.ForbiddenResponse(c, "api key is missing scope "+scope)
+			}
+		}
+		c.Locals(scopesPendingKey, false)
+		return c.Next()
+	}
+}
+
+// DenyAPIKeys rejects requests authenticated with an API key, so a leaked key
+// cannot mint further keys or change account security settings.
+func DenyAPIKeys() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("auth_method") == "api_key" {
+			return utils.ForbiddenResponse(c, "not available with api key authentication")
+		}
+		return c.Next()
+	}
+}
+
+// GetUserIDFromContext returns the authenticated user. It fails for scoped
+// API keys on routes without RequireScopes.
 func GetUserIDFromContext(c *fiber.Ctx) (uint, error) {
+	if scopesPending(c) {
+		return 0, fiber.ErrForbidden
+	}
+	return authenticatedUserID(c)
+}
+
+// authenticatedUserID returns the user of any valid credential, for
+// middleware that has to run before RequireScopes
+func authenticatedUserID(c *fiber.Ctx) (uint, error) {
 	userID := c.Locals("user_id")
 	if userID == nil {
 		return 0, fiber.ErrUnauthorized
@@ -90,6 +313,9 @@ func GetUserIDFromContext(c *fiber.Ctx) (uint, error) {
 }
 
 func GetEmailFromContext(c *fiber.Ctx) string {
+	if scopesPending(c) {
+		return ""
+	}
 	email := c.Locals("email")
 	if email == nil {
 		return ""
@@ -100,3 +326,24 @@ func GetEmailFromContext(c *fiber.Ctx) string {
 	}
 	return s
 }
+
+// GetRolesFromContext returns the role names of the authenticated user
+func GetRolesFromContext(c *fiber.Ctx) []string {
+	if scopesPending(c) {
+		return nil
+	}
+	roles, _ := c.Locals("roles").([]string)
+	return roles
+}
+
+func GetSessionIDFromContext(c *fiber.Ctx) uint {
+	id, _ := c.Locals("session_id").(uint)
+	return id
+}
+
+// GetTokenFromContext returns the jti and expiry of the access token used for the request.
+func GetTokenFromContext(c *fiber.Ctx) (string, time.Time) {
+	tokenID, _ := c.Locals("token_id").(string)
+	expiresAt, _ := c.Locals("token_expires_at").(time.Time)
+	return tokenID, expiresAt
+}
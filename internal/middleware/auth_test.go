@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/password"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+func TestLogoutRejectsTokenBeforeExpiry(t *testing.T) {
+	config.AppConfig = &config.Config{JWTSecret: "test-secret-key-that-is-long-enough", JWTExpiry: 15 * time.Minute, JWTRefreshExpiry: time.Hour}
+	db := testutil.NewTestDB(t)
+	denylist := cache.NewTokenDenylist(nil)
+	tm := jwt.NewTokenManager(config.AppConfig.JWTSecret)
+	InitTokenDenylist(denylist)
+	InitTokenManager(tm)
+	t.Cleanup(func() {
+		InitTokenDenylist(nil)
+		InitTokenManager(nil)
+	})
+	auth := services.NewAuthService(db, nil, services.NewSessionService(db, denylist), services.NewMFAService(db, nil, "Test"),
+		services.NewLockoutService(db, nil, services.LockoutOptions{}), services.NewTokenVersionService(db, nil),
+		services.NewPasswordPolicyService(db, password.NewPolicy(), 0), denylist, tm)
+	testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	app := fiber.New()
+	app.Get("/me", AuthMiddleware(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/logout", AuthMiddleware(), func(c *fiber.Ctx) error {
+		userID, _ := GetUserIDFromContext(c)
+		tokenID, expiresAt := GetTokenFromContext(c)
+		return auth.Logout(userID, GetSessionIDFromContext(c), tokenID, expiresAt)
+	})
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	login := func() string {
+		tokens, err := auth.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, services.ClientInfo{})
+		testutil.AssertNoError(t, err)
+		return tokens.Token
+	}
+
+	token, other := login(), login()
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodGet, "/me", token))
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodPost, "/logout", token))
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(fiber.MethodGet, "/me", token))
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodGet, "/me", other), "another session stays signed in")
+}
+
+func TestAccessTokenRejectedAfterTokenVersionBump(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	versions := services.NewTokenVersionService(db, nil)
+	manager := jwt.NewTokenManager("test-secret-key-that-is-long-enough")
+	InitTokenManager(manager)
+	InitTokenVersions(versions)
+	t.Cleanup(func() {
+		InitTokenManager(nil)
+		InitTokenVersions(nil)
+	})
+
+	app := fiber.New()
+	app.Get("/profile", AuthMiddleware(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	user := testutil.CreateStandardUserFixture(db)
+	request := func(version int) int {
+		token, err := manager.GenerateAccessToken(jwt.AccessTokenClaims{
+			UserID: user.ID, Email: user.Email, EmailVerified: true, Roles: []string{"user"}, TokenVersion: version,
+		}, time.Minute)
+		testutil.AssertNoError(t, err)
+		req := httptest.NewRequest(fiber.MethodGet, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		return resp.StatusCode
+	}
+
+	testutil.AssertEqual(t, fiber.StatusOK, request(user.TokenVersion))
+	testutil.AssertNoError(t, versions.Bump(db, user.ID))
+	versions.Invalidate(context.Background(), user.ID)
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(user.TokenVersion))
+	testutil.AssertEqual(t, fiber.StatusOK, request(user.TokenVersion+1))
+
+	testutil.AssertNoError(t, db.Delete(user).Error)
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(user.TokenVersion+1))
+}
+
+// apiKeyRequests routes requests like the resource and API key routes do and
+// returns a function sending one with an API key, answering its status
+func apiKeyRequests(t *testing.T, db *gorm.DB, keys services.APIKeyService) func(method, path, key string) int {
+	t.Helper()
+	InitAPIKeyAuth(keys)
+	InitPermissions(services.NewRoleService(db, nil))
+	t.Cleanup(func() {
+		InitAPIKeyAuth(nil)
+		InitPermissions(nil)
+	})
+
+	whoAmI := func(c *fiber.Ctx) error {
+		userID, err := GetUserIDFromContext(c)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, "Invalid user")
+		}
+		return c.JSON(fiber.Map{"user_id": userID})
+	}
+	app := fiber.New()
+	app.Get("/resources", AuthMiddleware(), RequireScopes("resources:read"), RequirePermissions("resources:read"), whoAmI)
+	app.Post("/resources", AuthMiddleware(), RequireScopes("resources:write"), RequirePermissions("resources:write"), whoAmI)
+	app.Get("/profile", AuthMiddleware(), whoAmI)
+	app.Post("/api-keys", AuthMiddleware(), DenyAPIKeys(), whoAmI)
+	return func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		return resp.StatusCode
+	}
+}
+
+func createAPIKey(t *testing.T, keys services.APIKeyService, userID uint, scopes ...string) *dto.CreateAPIKeyResponse {
+	t.Helper()
+	key, err := keys.Create(userID, &dto.CreateAPIKeyRequest{Name: "test", Scopes: scopes})
+	testutil.AssertNoError(t, err)
+	return key
+}
+
+func TestAPIKeyWithoutScopesHasFullAccess(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	keys := services.NewAPIKeyService(db)
+	request := apiKeyRequests(t, db, keys)
+	key := createAPIKey(t, keys, testutil.CreateStandardUserFixture(db).ID)
+
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodGet, "/resources", key.Key))
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodPost, "/resources", key.Key))
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodGet, "/profile", key.Key))
+	testutil.AssertEqual(t, fiber.StatusForbidden, request(fiber.MethodPost, "/api-keys", key.Key))
+}
+
+func TestScopedAPIKeyIsDeniedByDefault(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	keys := services.NewAPIKeyService(db)
+	request := apiKeyRequests(t, db, keys)
+	key := createAPIKey(t, keys, testutil.CreateStandardUserFixture(db).ID, "resources:read")
+
+	testutil.AssertEqual(t, fiber.StatusOK, request(fiber.MethodGet, "/resources", key.Key))
+	testutil.AssertEqual(t, fiber.StatusForbidden, request(fiber.MethodPost, "/resources", key.Key))
+	// No RequireScopes on the route, so the key does not reach the user
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(fiber.MethodGet, "/profile", key.Key))
+	testutil.AssertEqual(t, fiber.StatusForbidden, request(fiber.MethodPost, "/api-keys", key.Key))
+}
+
+func TestRevokedAndUnknownAPIKeysAreRejected(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	keys := services.NewAPIKeyService(db)
+	request := apiKeyRequests(t, db, keys)
+	user := testutil.CreateStandardUserFixture(db)
+	key := createAPIKey(t, keys, user.ID)
+	testutil.AssertNoError(t, keys.Revoke(user.ID, key.ID))
+
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(fiber.MethodGet, "/resources", key.Key))
+	testutil.AssertEqual(t, fiber.StatusUnauthorized, request(fiber.MethodGet, "/resources", services.APIKeyPrefix+"unknown"))
+}
+
+func TestCreateAPIKeyRequestOnlyAcceptsKnownScopes(t *testing.T) {
+	valid := &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"resources:read", "resources:write"}}
+	testutil.AssertNoError(t, valid.Validate())
+
+	for _, scope := range []string{"users:admin", "resources:*", "Resources:Read"} {
+		req := &dto.CreateAPIKeyRequest{Name: "ci", Scopes: []string{scope}}
+		testutil.AssertError(t, req.Validate(), "scope %q", scope)
+	}
+}
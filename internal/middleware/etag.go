@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/pkg/utils"
+	"go-fiber-boilerplate/pkg/versioning"
+)
+
+// IfMatch passes the request's If-Match header to the services as a
+// versioning precondition, so an update or delete of a record whose version
+// it does not name fails with versioning.ErrPreconditionFailed. When required,
+// requests without the header get 428 Precondition Required; otherwise they
+// are unconditional.
+func IfMatch(required bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderIfMatch)
+		if header == "" {
+			if required {
+				return utils.ErrorResponse(c, fiber.StatusPreconditionRequired, "If-Match header is required; send the ETag from the last read")
+			}
+			return c.Next()
+		}
+		c.SetUserContext(versioning.WithPrecondition(c.UserContext(), versioning.ParseIfMatch(header)))
+		return c.Next()
+	}
+}
+
+// SetETag sets the response's ETag to the version. It reports whether the
+// request's If-None-Match already names it, in which case the handler should
+// answer 304 Not Modified.
+func SetETag(c *fiber.Ctx, version uint) (notModified bool) {
+	c.Set(fiber.HeaderETag, versioning.ETag(version))
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && versioning.ParseIfNoneMatch(header).Matches(version)
+}
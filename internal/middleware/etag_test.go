@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/versioning"
+)
+
+func TestSetETagAnswersNotModified(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if SetETag(c, 3) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return c.SendString("body")
+	})
+	request := func(ifNoneMatch string) (int, string) {
+		req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+	}
+
+	status, etag := request("")
+	testutil.AssertEqual(t, fiber.StatusOK, status)
+	testutil.AssertEqual(t, `"3"`, etag)
+
+	status, etag = request(`W/"3"`)
+	testutil.AssertEqual(t, fiber.StatusNotModified, status)
+	testutil.AssertEqual(t, `"3"`, etag)
+
+	status, _ = request(`"2"`)
+	testutil.AssertEqual(t, fiber.StatusOK, status)
+}
+
+// ifMatchRequests returns a request function for a route whose record is at
+// version 3, guarded by IfMatch(required)
+func ifMatchRequests(t *testing.T, required bool) func(ifMatch string) int {
+	app := fiber.New()
+	app.Put("/", IfMatch(required), func(c *fiber.Ctx) error {
+		if err := versioning.Check(c.UserContext(), 3); errors.Is(err, versioning.ErrPreconditionFailed) {
+			return c.SendStatus(fiber.StatusPreconditionFailed)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return func(ifMatch string) int {
+		req := httptest.NewRequest(fiber.MethodPut, "/", nil)
+		if ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		}
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+}
+
+func TestIfMatchCarriesPrecondition(t *testing.T) {
+	request := ifMatchRequests(t, true)
+
+	testutil.AssertEqual(t, fiber.StatusOK, request(`"3"`))
+	testutil.AssertEqual(t, fiber.StatusOK, request("*"))
+	testutil.AssertEqual(t, fiber.StatusPreconditionFailed, request(`"2"`))
+	testutil.AssertEqual(t, fiber.StatusPreconditionFailed, request(`W/"3"`))
+}
+
+func TestIfMatchRequiresHeader(t *testing.T) {
+	testutil.AssertEqual(t, fiber.StatusPreconditionRequired, ifMatchRequests(t, true)(""))
+	testutil.AssertEqual(t, fiber.StatusOK, ifMatchRequests(t, false)(""), "optional If-Match leaves the request unconditional")
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -65,3 +66,27 @@ func NewPublicLimiter() fiber.Handler {
 		},
 	})
 }
+
+// NewEmailLimiter limits requests per submitted email address, so a single
+// account cannot be flooded from many IPs. Requests without an email fall back
+// to the client IP.
+func NewEmailLimiter(name string, max int, expiration time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Storage:    limiterStorage,
+		Max:        max,
+		Expiration: expiration,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			var body struct {
+				Email string `json:"email"`
+			}
+			if err := c.BodyParser(&body); err == nil && body.Email != "" {
+				return name + ":email:" + strings.ToLower(strings.TrimSpace(body.Email))
+			}
+			return name + ":ip:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			utils.Log("Security").Warn("Email rate limit exceeded", "ip", c.IP(), "path", c.Path())
+			return utils.TooManyRequestsResponse(c, "Too many requests for this email address, please try again later")
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+func TestEmailLimiterLimitsPerAddress(t *testing.T) {
+	utils.InitLogger()
+	app := fiber.New()
+	app.Post("/magic-link", NewEmailLimiter("magic_link", 3, 15*time.Minute), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	request := func(email string) int {
+		req := httptest.NewRequest(fiber.MethodPost, "/magic-link", strings.NewReader(`{"email":"`+email+`"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Case and surrounding spaces do not make a new address
+	for _, email := range []string{"jane@example.com", "Jane@Example.com", " jane@example.com"} {
+		testutil.AssertEqual(t, fiber.StatusOK, request(email))
+	}
+	testutil.AssertEqual(t, fiber.StatusTooManyRequests, request("jane@example.com"))
+	testutil.AssertEqual(t, fiber.StatusOK, request("john@example.com"), "other addresses have their own limit")
+}
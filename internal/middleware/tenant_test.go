@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/policy"
+)
+
+func TestOrganizationActorHoldsGlobalRoles(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	InitTenancy(services.NewOrganizationService(db, nil, nil), "")
+	t.Cleanup(func() { InitTenancy(nil, "") })
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	admin := testutil.CreateAdminUserFixture(db)
+	member := testutil.CreateStandardUserFixture(db)
+	organization := testutil.CreateOrganizationWithMembers(db, "acme", owner, admin, member)
+	ownersResource := &services.ResourceAccess{Resource: &models.Resource{CreatedByID: owner.ID}}
+
+	app := fiber.New()
+	app.Put("/resource", func(c *fiber.Ctx) error {
+		// Stands in for AuthMiddleware
+		if c.Get("X-User") == "admin" {
+			c.Locals("user_id", admin.ID)
+			c.Locals("roles", []string{"admin"})
+		} else {
+			c.Locals("user_id", member.ID)
+			c.Locals("roles", []string{"user"})
+		}
+		return c.Next()
+	}, RequireOrganization(), func(c *fiber.Ctx) error {
+		if err := policy.Authorize(c.UserContext(), services.ResourcePolicy{}, policy.Update, ownersResource); err != nil {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	request := func(user string) int {
+		req := httptest.NewRequest(fiber.MethodPut, "/resource", nil)
+		req.Header.Set(OrganizationHeader, strconv.FormatUint(uint64(organization.ID), 10))
+		req.Header.Set("X-User", user)
+		resp, err := app.Test(req)
+		testutil.AssertNoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	testutil.AssertEqual(t, fiber.StatusForbidden, request("member"))
+	testutil.AssertEqual(t, fiber.StatusOK, request("admin"), "a global admin overrides the resource policy")
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey is a long-lived personal access token for machine clients. Only the
+// SHA-256 hash of the secret is stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(20);not null" json:"prefix"`
+	KeyHash    string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"type:text;not null;default:''" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the granted scopes; an empty list means full access
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, " ")
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
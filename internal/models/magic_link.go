@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MagicLink is a single-use passwordless login token. Only its hash is stored.
+type MagicLink struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MagicLink) TableName() string {
+	return "magic_links"
+}
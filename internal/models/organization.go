@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization roles, from most to least privileged
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization is a tenant. Tenant-owned models carry its ID in
+// organization_id and are scoped to it by the tenant GORM plugin.
+type Organization struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"type:varchar(120);not null" json:"name"`
+	Slug      string         `gorm:"type:varchar(63);uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMember grants a user access to an organization with a per-org role
+type OrganizationMember struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_organization_members_org_user" json:"organization_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_organization_members_org_user;index" json:"user_id"`
+	Role           string    `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	User         *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
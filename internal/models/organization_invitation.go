@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OrganizationInvitation invites an email address into an organization. Only
+// the hash of the emailed token is stored, and the token works once.
+type OrganizationInvitation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrganizationID uint       `gorm:"not null;index" json:"organization_id"`
+	Email          string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	Role           string     `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	TokenHash      string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	InvitedByID    *uint      `gorm:"index" json:"invited_by_id,omitempty"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	AcceptedByID   *uint      `json:"accepted_by_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+}
+
+func (OrganizationInvitation) TableName() string {
+	return "organization_invitations"
+}
+
+// TenantOwned scopes invitations to their organization
+func (OrganizationInvitation) TenantOwned() {}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PasswordHistory keeps hashes of a user's recent passwords so they cannot be reused
+type PasswordHistory struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -6,15 +6,20 @@ import (
 	"gorm.io/gorm"
 )
 
+// Resource is tenant-owned: queries are scoped to the organization in the
+// statement's context. Its full-text index, the search_vector column on
+// Postgres or the resources_fts table on SQLite, is kept current by triggers.
 type Resource struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Name        string         `gorm:"type:varchar(120);not null;index" json:"name"`
-	Description *string        `gorm:"type:text" json:"description,omitempty"`
-	Status      string         `gorm:"type:varchar(40);not null;default:'active';index" json:"status"`
-	CreatedByID uint           `gorm:"not null;index" json:"created_by_id"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	OrganizationID uint           `gorm:"not null;index" json:"organization_id"`
+	Name           string         `gorm:"type:varchar(120);not null;index" json:"name"`
+	Description    *string        `gorm:"type:text" json:"description,omitempty"`
+	Status         string         `gorm:"type:varchar(40);not null;default:'active';index" json:"status"`
+	Version        uint           `gorm:"not null;default:1" json:"version"`
+	CreatedByID    uint           `gorm:"not null;index" json:"created_by_id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
 	CreatedBy *User `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
 }
@@ -22,3 +27,5 @@ type Resource struct {
 func (Resource) TableName() string {
 	return "resources"
 }
+
+func (Resource) TenantOwned() {}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Share levels, from least to most privileged
+const (
+	ShareLevelViewer = "viewer"
+	ShareLevelEditor = "editor"
+)
+
+// ResourceShare grants a user, or every member holding an organization role,
+// access to one resource. Exactly one of UserID and Role is set.
+type ResourceShare struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrganizationID uint      `gorm:"not null;index" json:"organization_id"`
+	ResourceID     uint      `gorm:"not null;uniqueIndex:idx_resource_shares_resource_user;uniqueIndex:idx_resource_shares_resource_role" json:"resource_id"`
+	UserID         *uint     `gorm:"uniqueIndex:idx_resource_shares_resource_user;index" json:"user_id,omitempty"`
+	Role           *string   `gorm:"type:varchar(20);uniqueIndex:idx_resource_shares_resource_role" json:"role,omitempty"`
+	Level          string    `gorm:"type:varchar(20);not null" json:"level"`
+	GrantedByID    *uint     `json:"granted_by_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (ResourceShare) TableName() string {
+	return "resource_shares"
+}
+
+// TenantOwned scopes shares to the organization of their resource
+func (ResourceShare) TenantOwned() {}
@@ -2,11 +2,19 @@ package models
 
 // APIResponse is the standard API response wrapper
 type APIResponse struct {
-	Status  int         `json:"status" example:"200"`
-	Message string      `json:"message" example:"Success"`
-	Code    string      `json:"code,omitempty" example:""`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty" example:""`
+	Status  int          `json:"status" example:"200"`
+	Message string       `json:"message" example:"Success"`
+	Code    string       `json:"code,omitempty" example:""`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty" example:""`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field" example:"password"`
+	Code    string `json:"code" example:"too_short"`
+	Message string `json:"message" example:"must be at least 8 characters long"`
 }
 
 // PaginatedResponse is the response wrapper for paginated data
@@ -19,3 +27,16 @@ type PaginatedResponse struct {
 	Total      int64       `json:"total" example:"100"`
 	TotalPages int         `json:"total_pages" example:"10"`
 }
+
+// CursorPaginatedResponse is the response wrapper for cursor paginated data.
+// A null cursor means there is no page in that direction; total is only
+// present when requested.
+type CursorPaginatedResponse struct {
+	Status     int         `json:"status" example:"200"`
+	Message    string      `json:"message" example:"Success"`
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit" example:"10"`
+	NextCursor *string     `json:"next_cursor" example:"eyJxIjoi...Rk"`
+	PrevCursor *string     `json:"prev_cursor"`
+	Total      *int64      `json:"total,omitempty" example:"100"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Role groups permissions and is assigned to users through user_roles
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
+	Description string       `gorm:"type:varchar(255);not null;default:''" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Permission is a named capability such as resources:write
+type Permission struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:varchar(255);not null;default:''" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+type UserRole struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint      `gorm:"primaryKey;index" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
@@ -11,19 +11,28 @@ type User struct {
 	Email               string         `gorm:"uniqueIndex;size:255;not null" json:"email"`
 	Password            *string        `gorm:"size:255" json:"-"`
 	PasswordIsSetByUser bool           `gorm:"not null;default:false" json:"password_is_set_by_user"`
-	Role                *string        `gorm:"size:50;index" json:"role"`
 	IsActive            bool           `gorm:"not null;default:true" json:"is_active"`
+	EmailVerifiedAt     *time.Time     `json:"email_verified_at,omitempty"`
+	FailedLoginAttempts int            `gorm:"not null;default:0" json:"-"`
+	LastFailedLoginAt   *time.Time     `json:"-"`
+	LockedUntil         *time.Time     `json:"locked_until,omitempty"`
+	TokenVersion        int            `gorm:"not null;default:0" json:"-"`
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Profile *UserProfile `gorm:"foreignKey:UserID" json:"profile,omitempty"`
+	Roles   []Role       `gorm:"many2many:user_roles" json:"roles,omitempty"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 type UserProfile struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	UserID    uint      `gorm:"uniqueIndex;not null" json:"user_id"`
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UserIdentity links an account at an external OAuth/OIDC provider to a user.
+// Identities of externally issued access tokens use the issuer as provider.
+type UserIdentity struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Provider string `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject  string `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email    string `gorm:"type:varchar(255)" json:"email"`
+	// Provisioned is set when an external token created the account, so the
+	// provider's role claim manages the user's roles
+	Provisioned bool       `gorm:"not null;default:false" json:"-"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// UserMFA holds a user's TOTP enrollment. The secret is encrypted at rest and
+// the enrollment only takes effect once EnabledAt is set.
+type UserMFA struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	SecretEncrypted string     `gorm:"type:varchar(255);not null" json:"-"`
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+type MFARecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(128);uniqueIndex;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// UserSession is a login on one device. OrganizationID is the active
+// organization chosen for the session and carried in its access tokens.
+type UserSession struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	OrganizationID *uint      `json:"organization_id,omitempty"`
+	RefreshTokenID string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt      *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason  *string    `gorm:"type:varchar(40)" json:"revoked_reason,omitempty"`
+	UserAgent      string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IPAddress      string     `gorm:"type:varchar(64)" json:"ip_address"`
+	LastSeenAt     *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
@@ -1,12 +1,16 @@
 package routes
 
 import (
+	"time"
+
 	"go-fiber-boilerplate/config"
 	"go-fiber-boilerplate/internal/cache"
 	"go-fiber-boilerplate/internal/database"
 	"go-fiber-boilerplate/internal/handlers"
 	"go-fiber-boilerplate/internal/middleware"
+	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/internal/services"
+	"go-fiber-boilerplate/pkg/jwt"
 	"go-fiber-boilerplate/pkg/mailer"
 	"go-fiber-boilerplate/pkg/utils"
 
@@ -14,7 +18,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, _ *cache.Client) {
+func SetupRoutes(app *fiber.App, cacheClient *cache.Client, tokenManager *jwt.TokenManager) {
 	emailService := services.NewNoopEmailService()
 	if config.AppConfig.SMTPHost == "" {
 		utils.Log("Routes").Warn("SMTP Host not configured, email service disabled")
@@ -31,20 +35,75 @@ func SetupRoutes(app *fiber.App, _ *cache.Client) {
 			smtpMailer,
 			config.AppConfig.AppName,
 			config.AppConfig.PasswordResetURL,
+			config.AppConfig.EmailVerificationURL,
+			config.AppConfig.MagicLinkURL,
+			config.AppConfig.InvitationURL,
+			config.AppConfig.ResourceURL,
 		)
 		utils.Log("Routes").Info("SMTP email service initialized", "host", config.AppConfig.SMTPHost, "port", config.AppConfig.SMTPPort)
 	}
 	_ = services.NewNoopStorageService()
 
-	authService := services.NewAuthService(database.GetDB(), emailService)
-	userService := services.NewUserService(database.GetDB())
-	resourceService := services.NewResourceService(database.GetDB())
+	tokenDenylist := cache.NewTokenDenylist(cacheClient)
+	tokenVersions := services.NewTokenVersionService(database.GetDB(), cacheClient)
+	roleService := services.NewRoleService(database.GetDB(), cacheClient)
+	middleware.InitTokenManager(tokenManager)
+	middleware.InitTokenDenylist(tokenDenylist)
+	middleware.InitTokenVersions(tokenVersions)
+	middleware.InitPermissions(roleService)
+	if verifier := config.AppConfig.GetExternalVerifier(); verifier != nil {
+		externalAuthService := services.NewExternalAuthService(database.GetDB(), cacheClient, roleService, tokenVersions, services.ExternalAuthOptions{
+			AutoProvision: config.AppConfig.ExternalAuthAutoProvision,
+			DefaultRole:   config.AppConfig.ExternalAuthDefaultRole,
+		})
+		middleware.InitExternalAuth(verifier, externalAuthService)
+		utils.Log("Routes").Info("External token validation enabled", "issuer", verifier.Issuer())
+	}
+
+	mfaCipher := config.AppConfig.GetMFACipher()
+	if mfaCipher == nil {
+		utils.Log("Routes").Warn("MFA_ENCRYPTION_KEY not configured, two-factor enrollment disabled")
+	}
+
+	sessionService := services.NewSessionService(database.GetDB(), tokenDenylist)
+	mfaService := services.NewMFAService(database.GetDB(), mfaCipher, config.AppConfig.GetMFAIssuer())
+	lockoutService := services.NewLockoutService(database.GetDB(), cacheClient, services.LockoutOptions{
+		Threshold:    config.AppConfig.LoginLockoutThreshold,
+		Duration:     config.AppConfig.LoginLockoutDuration,
+		BackoffAfter: config.AppConfig.LoginBackoffAfter,
+		BackoffBase:  config.AppConfig.LoginBackoffBase,
+	})
+	passwordPolicy := services.NewPasswordPolicyService(database.GetDB(), config.AppConfig.GetPasswordPolicy(), config.AppConfig.PasswordHistory)
+	authService := services.NewAuthService(database.GetDB(), emailService, sessionService, mfaService, lockoutService, tokenVersions, passwordPolicy, tokenDenylist, tokenManager)
+	apiKeyService := services.NewAPIKeyService(database.GetDB())
+	middleware.InitAPIKeyAuth(apiKeyService)
+	oauthService := services.NewOAuthService(database.GetDB(), config.AppConfig.GetOAuthProviders(), authService, tokenManager, config.AppConfig.OAuthStateTTL)
+	userService := services.NewUserService(database.GetDB(), sessionService, tokenVersions, passwordPolicy)
+	resourceService := services.NewResourceService(database.GetDB(), emailService, services.ResourcePolicy{}, config.AppConfig.PolicyHideDenied)
+	searchService := services.NewSearchService(database.GetDB())
+	adminUserService := services.NewAdminUserService(database.GetDB(), emailService, sessionService, tokenVersions, roleService)
+	organizationService := services.NewOrganizationService(database.GetDB(), cacheClient, authService)
+	middleware.InitTenancy(organizationService, config.AppConfig.TenantBaseDomain)
+	invitationService := services.NewInvitationService(database.GetDB(), emailService, organizationService, authService)
+	if interval := config.AppConfig.InvitationCleanupInterval; interval > 0 {
+		go purgeInvitations(invitationService, interval)
+	}
 
 	authHandler := handlers.NewAuth(authService)
-	userHandler := handlers.NewUser(userService)
+	userHandler := handlers.NewUser(userService, authService)
+	mfaHandler := handlers.NewMFA(mfaService)
+	oauthHandler := handlers.NewOAuth(oauthService)
+	apiKeyHandler := handlers.NewAPIKey(apiKeyService)
+	sessionHandler := handlers.NewSession(sessionService)
+	adminHandler := handlers.NewAdmin(lockoutService, adminUserService, roleService)
+	organizationHandler := handlers.NewOrganization(organizationService)
+	invitationHandler := handlers.NewInvitation(invitationService)
 	resourceHandler := handlers.NewResource(resourceService)
+	searchHandler := handlers.NewSearch(searchService)
+	jwksHandler := handlers.NewJWKS(tokenManager)
 
 	app.Get("/health", handlers.HealthCheck)
+	app.Get("/.well-known/jwks.json", middleware.NewPublicLimiter(), jwksHandler.GetJWKS)
 
 	if !config.AppConfig.IsProduction() {
 		app.Static("/swagger.json", "./docs/swagger.json")
@@ -76,9 +135,19 @@ func SetupRoutes(app *fiber.App, _ *cache.Client) {
 	{
 		authGroup.Post("/register", authHandler.Register)
 		authGroup.Post("/login", authHandler.Login)
+		authGroup.Post("/mfa/verify", authHandler.VerifyMFA)
+		authGroup.Post("/magic-link", middleware.NewEmailLimiter("magic_link", 3, 15*time.Minute), authHandler.RequestMagicLink)
+		authGroup.Post("/magic-link/verify", authHandler.VerifyMagicLink)
+		authGroup.Get("/oauth/providers", oauthHandler.ListProviders)
+		authGroup.Get("/oauth/:provider/authorize", oauthHandler.Authorize)
+		authGroup.Post("/oauth/:provider/callback", oauthHandler.Callback)
 		authGroup.Post("/refresh", authHandler.RefreshToken)
 		authGroup.Post("/forgot-password", authHandler.ForgotPassword)
 		authGroup.Post("/reset-password", authHandler.ResetPassword)
+		authGroup.Post("/verify-email", authHandler.VerifyEmail)
+		authGroup.Post("/resend-verification", authHandler.ResendVerification)
+		authGroup.Post("/logout", middleware.AuthMiddleware(), authHandler.Logout)
+		authGroup.Post("/logout-all", middleware.AuthMiddleware(), authHandler.LogoutAll)
 	}
 
 	userGroup := api.Group("/user")
@@ -86,20 +155,97 @@ func SetupRoutes(app *fiber.App, _ *cache.Client) {
 	{
 		userGroup.Get("/profile", userHandler.GetProfile)
 		userGroup.Put("/profile", userHandler.UpdateProfile)
-		userGroup.Post("/change-password", userHandler.ChangePassword)
+		userGroup.Post("/change-password", middleware.DenyAPIKeys(), userHandler.ChangePassword)
+		userGroup.Get("/sessions", sessionHandler.ListSessions)
+		userGroup.Delete("/sessions/:id", middleware.DenyAPIKeys(), sessionHandler.RevokeSession)
+		userGroup.Get("/mfa", mfaHandler.GetStatus)
+		userGroup.Post("/mfa/enroll", middleware.DenyAPIKeys(), mfaHandler.Enroll)
+		userGroup.Post("/mfa/confirm", middleware.DenyAPIKeys(), mfaHandler.Confirm)
+		userGroup.Post("/mfa/recovery-codes", middleware.DenyAPIKeys(), mfaHandler.RegenerateRecoveryCodes)
+		userGroup.Post("/mfa/disable", middleware.DenyAPIKeys(), mfaHandler.Disable)
+		userGroup.Get("/identities", oauthHandler.ListIdentities)
+		userGroup.Get("/identities/:provider/authorize", middleware.DenyAPIKeys(), oauthHandler.AuthorizeLink)
+		userGroup.Post("/identities/:provider/callback", middleware.DenyAPIKeys(), oauthHandler.Link)
+		userGroup.Delete("/identities/:id", middleware.DenyAPIKeys(), oauthHandler.Unlink)
+		userGroup.Get("/api-keys", apiKeyHandler.ListAPIKeys)
+		userGroup.Post("/api-keys", middleware.DenyAPIKeys(), apiKeyHandler.CreateAPIKey)
+		userGroup.Delete("/api-keys/:id", middleware.DenyAPIKeys(), apiKeyHandler.RevokeAPIKey)
+	}
+
+	adminGroup := api.Group("/admin")
+	adminGroup.Use(middleware.AuthMiddleware(), middleware.DenyAPIKeys())
+	{
+		adminGroup.Get("/roles", middleware.RequirePermissions("users:read"), adminHandler.ListRoles)
+		adminGroup.Get("/users", middleware.RequirePermissions("users:read"), adminHandler.ListUsers)
+		adminGroup.Get("/users/:id", middleware.RequirePermissions("users:read"), adminHandler.GetUser)
+		adminGroup.Put("/users/:id/roles", middleware.RequirePermissions("users:admin"), adminHandler.UpdateUserRoles)
+		adminGroup.Put("/users/:id/status", middleware.RequirePermissions("users:admin"), adminHandler.UpdateUserStatus)
+		adminGroup.Post("/users/:id/password-reset", middleware.RequirePermissions("users:admin"), adminHandler.SendPasswordReset)
+		adminGroup.Post("/users/:id/unlock", middleware.RequirePermissions("users:admin"), adminHandler.UnlockUser)
+		adminGroup.Delete("/users/:id", middleware.RequirePermissions("users:admin"), adminHandler.DeleteUser)
+		adminGroup.Post("/users/:id/restore", middleware.RequirePermissions("users:admin"), adminHandler.RestoreUser)
+	}
+
+	orgGroup := api.Group("/organizations")
+	orgGroup.Use(middleware.AuthMiddleware())
+	{
+		manageMembers := middleware.RequireOrganizationRole(models.OrganizationRoleOwner, models.OrganizationRoleAdmin)
+		orgGroup.Get("/", organizationHandler.ListOrganizations)
+		orgGroup.Post("/", middleware.DenyAPIKeys(), organizationHandler.CreateOrganization)
+		orgGroup.Get("/:id", middleware.OrganizationParam("id"), organizationHandler.GetOrganization)
+		orgGroup.Post("/:id/switch", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), organizationHandler.SwitchOrganization)
+		orgGroup.Get("/:id/members", middleware.OrganizationParam("id"), organizationHandler.ListMembers)
+		orgGroup.Post("/:id/members", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), manageMembers, organizationHandler.AddMember)
+		orgGroup.Put("/:id/members/:userId", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), manageMembers, organizationHandler.UpdateMember)
+		orgGroup.Delete("/:id/members/:userId", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), organizationHandler.RemoveMember)
+		orgGroup.Get("/:id/invitations", middleware.OrganizationParam("id"), manageMembers, invitationHandler.ListInvitations)
+		orgGroup.Post("/:id/invitations", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), manageMembers, invitationHandler.CreateInvitation)
+		orgGroup.Post("/:id/invitations/:invitationId/resend", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), manageMembers, invitationHandler.ResendInvitation)
+		orgGroup.Delete("/:id/invitations/:invitationId", middleware.DenyAPIKeys(), middleware.OrganizationParam("id"), manageMembers, invitationHandler.RevokeInvitation)
+	}
+
+	invitationGroup := api.Group("/invitations")
+	invitationGroup.Use(middleware.NewAuthLimiter())
+	{
+		invitationGroup.Post("/lookup", invitationHandler.LookupInvitation)
+		invitationGroup.Post("/register", invitationHandler.RegisterInvitation)
+		invitationGroup.Post("/accept", middleware.AuthMiddleware(), middleware.DenyAPIKeys(), invitationHandler.AcceptInvitation)
 	}
 
 	resourcesGroup := api.Group("/resources")
-	resourcesGroup.Use(middleware.AuthMiddleware())
+	resourcesGroup.Use(middleware.AuthMiddleware(), middleware.RequireOrganization())
 	{
-		resourcesGroup.Get("/", resourceHandler.ListResources)
-		resourcesGroup.Post("/", resourceHandler.CreateResource)
-		resourcesGroup.Get("/:id", resourceHandler.GetResource)
-		resourcesGroup.Put("/:id", resourceHandler.UpdateResource)
-		resourcesGroup.Delete("/:id", resourceHandler.DeleteResource)
+		resourcesGroup.Get("/", middleware.RequireScopes("resources:read"), middleware.RequirePermissions("resources:read"), resourceHandler.ListResources)
+		resourcesGroup.Post("/", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.CreateResource)
+		resourcesGroup.Post("/bulk", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.BulkCreateResources)
+		resourcesGroup.Patch("/bulk", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.BulkUpdateResources)
+		resourcesGroup.Delete("/bulk", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.BulkDeleteResources)
+		resourcesGroup.Get("/search", middleware.RequireScopes("resources:read"), middleware.RequirePermissions("resources:read"), searchHandler.SearchResources)
+		resourcesGroup.Get("/:id", middleware.RequireScopes("resources:read"), middleware.RequirePermissions("resources:read"), resourceHandler.GetResource)
+		resourcesGroup.Put("/:id", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), middleware.IfMatch(config.AppConfig.IfMatchRequired), resourceHandler.UpdateResource)
+		resourcesGroup.Delete("/:id", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), middleware.IfMatch(config.AppConfig.IfMatchRequired), resourceHandler.DeleteResource)
+		resourcesGroup.Get("/:id/shares", middleware.RequireScopes("resources:read"), middleware.RequirePermissions("resources:read"), resourceHandler.ListShares)
+		resourcesGroup.Post("/:id/shares", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.ShareResource)
+		resourcesGroup.Delete("/:id/shares/:shareId", middleware.RequireScopes("resources:write"), middleware.RequirePermissions("resources:write"), resourceHandler.RevokeShare)
 	}
 
 	app.Use(func(c *fiber.Ctx) error {
 		return utils.NotFoundResponse(c, "endpoint not found")
 	})
 }
+
+// purgeInvitations periodically deletes invitations past their retention
+func purgeInvitations(invitationService services.InvitationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		purged, err := invitationService.PurgeExpired()
+		if err != nil {
+			utils.Log("Invitation").Error("Purge expired invitations failed", "error", err)
+			continue
+		}
+		if purged > 0 {
+			utils.Log("Invitation").Info("Purged expired invitations", "count", purged)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCannotModifySelf = errors.New("administrators cannot change their own roles, status or account")
+	ErrUserNotDeleted   = errors.New("user is not deleted")
+)
+
+// likeEscaper escapes LIKE wildcards in user supplied search terms
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// AdminUserService manages user accounts on behalf of administrators. Every
+// change is logged under the Security module with the acting admin's ID.
+type AdminUserService interface {
+	ListUsers(query *dto.AdminUserListQuery) ([]dto.AdminUserResponse, int64, error)
+	GetUser(id uint) (*dto.AdminUserResponse, error)
+	SetRoles(adminID, userID uint, roles []string) (*dto.AdminUserResponse, error)
+	SetActive(adminID, userID uint, active bool) (*dto.AdminUserResponse, error)
+	SendPasswordReset(adminID, userID uint) error
+	DeleteUser(adminID, userID uint) error
+	RestoreUser(adminID, userID uint) (*dto.AdminUserResponse, error)
+}
+
+type adminUserService struct {
+	db             *gorm.DB
+	emailService   EmailService
+	sessionService SessionService
+	tokenVersions  TokenVersionService
+	roleService    RoleService
+}
+
+func NewAdminUserService(db *gorm.DB, emailService EmailService, sessionService SessionService, tokenVersions TokenVersionService, roleService RoleService) AdminUserService {
+	return &adminUserService{
+		db:             db,
+		emailService:   emailService,
+		sessionService: sessionService,
+		tokenVersions:  tokenVersions,
+		roleService:    roleService,
+	}
+}
+
+// ListUsers searches email, first and last name case-insensitively
+func (s *adminUserService) ListUsers(query *dto.AdminUserListQuery) ([]dto.AdminUserResponse, int64, error) {
+	db := s.db.Model(&models.User{})
+	if query.Deleted {
+		db = db.Unscoped().Where("users.deleted_at IS NOT NULL")
+	}
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(search)) + "%"
+		db = db.Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id").
+			Where(`LOWER(users.email) LIKE ? ESCAPE '\' OR LOWER(user_profiles.first_name) LIKE ? ESCAPE '\' OR LOWER(user_profiles.last_name) LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+	}
+	if query.Role != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id WHERE user_roles.user_id = users.id AND roles.name = ?)",
+			strings.ToLower(query.Role))
+	}
+	if query.IsActive != nil {
+		db = db.Where("users.is_active = ?", *query.IsActive)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	offset := (query.Page - 1) * query.Limit
+	if err := db.Preload("Profile").Preload("Roles").Order("users.created_at DESC, users.id DESC").
+		Offset(offset).Limit(query.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]dto.AdminUserResponse, 0, len(users))
+	for i := range users {
+		out = append(out, *toAdminUserResponse(&users[i]))
+	}
+	return out, total, nil
+}
+
+// GetUser returns a user, including a soft-deleted one
+func (s *adminUserService) GetUser(id uint) (*dto.AdminUserResponse, error) {
+	user, err := s.findUser(s.db.Unscoped(), id)
+	if err != nil {
+		return nil, err
+	}
+	return toAdminUserResponse(user), nil
+}
+
+// SetRoles replaces the user's roles. Roles are carried in access tokens, so
+// the user is signed out everywhere and picks up the new roles on login.
+func (s *adminUserService) SetRoles(adminID, userID uint, roles []string) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	current := roleNames(user.Roles)
+	roles = normalizeRoleNames(roles)
+	if slices.Equal(current, roles) {
+		return toAdminUserResponse(user), nil
+	}
+	err = s.updateAndSignOut(userID, SessionRevokedRoleChange, func(tx *gorm.DB) error {
+		return s.roleService.SetUserRoles(tx, userID, roles)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.roleService.Invalidate(context.Background(), userID)
+	utils.Log("Security").Info("User roles changed", "user_id", userID, "admin_id", adminID, "from", current, "to", roles)
+	return s.GetUser(userID)
+}
+
+// SetActive activates or deactivates the user. Deactivation signs the user
+// out everywhere; API keys stop working while the account is inactive.
+func (s *adminUserService) SetActive(adminID, userID uint, active bool) (*dto.AdminUserResponse, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsActive == active {
+		return toAdminUserResponse(user), nil
+	}
+	if active {
+		err = s.db.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"is_active": true, "updated_at": time.Now()}).Error
+	} else {
+		err = s.updateAndSignOut(userID, SessionRevokedDeactivated, func(tx *gorm.DB) error {
+			return tx.Model(&models.User{}).Where("id = ?", userID).
+				Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now()}).Error
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("User status changed", "user_id", userID, "admin_id", adminID, "is_active", active)
+	return s.GetUser(userID)
+}
+
+// SendPasswordReset emails the user a password reset link, as if they had
+// used the forgot password flow. The current password keeps working until
+// the reset is completed.
+func (s *adminUserService) SendPasswordReset(adminID, userID uint) error {
+	if s.emailService == nil || !s.emailService.Enabled() {
+		return ErrPasswordResetDisabled
+	}
+	user, err := s.findUser(s.db, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return ErrInactiveAccount
+	}
+	token, err := createPasswordReset(s.db, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.emailService.SendPasswordReset(user.Email, token); err != nil {
+		return err
+	}
+	utils.Log("Security").Info("Password reset sent by admin", "user_id", userID, "admin_id", adminID)
+	return nil
+}
+
+// DeleteUser soft-deletes the user and signs them out everywhere. The row
+// keeps its email address, so it cannot be registered again until the user
+// is restored or purged.
+func (s *adminUserService) DeleteUser(adminID, userID uint) error {
+	if adminID == userID {
+		return ErrCannotModifySelf
+	}
+	if _, err := s.findUser(s.db, userID); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.tokenVersions.Bump(tx, userID); err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, userID).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.tokenVersions.Invalidate(context.Background(), userID)
+	if err := s.sessionService.RevokeAllForUser(userID, SessionRevokedUserDeleted); err != nil {
+		return err
+	}
+	utils.Log("Security").Info("User deleted", "user_id", userID, "admin_id", adminID)
+	return nil
+}
+
+// RestoreUser undoes a soft delete. The user has to log in again.
+func (s *adminUserService) RestoreUser(adminID, userID uint) (*dto.AdminUserResponse, error) {
+	user, err := s.findUser(s.db.Unscoped(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+	if err := s.db.Unscoped().Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"deleted_at": nil, "updated_at": time.Now()}).Error; err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("User restored", "user_id", userID, "admin_id", adminID)
+	return s.GetUser(userID)
+}
+
+func (s *adminUserService) findUser(db *gorm.DB, id uint) (*models.User, error) {
+	var user models.User
+	if err := db.Preload("Profile").Preload("Roles").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// updateAndSignOut runs update and bumps the token version in one
+// transaction, then revokes every session of the user
+func (s *adminUserService) updateAndSignOut(userID uint, reason string, update func(tx *gorm.DB) error) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := update(tx); err != nil {
+			return err
+		}
+		return s.tokenVersions.Bump(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+	s.tokenVersions.Invalidate(context.Background(), userID)
+	return s.sessionService.RevokeAllForUser(userID, reason)
+}
+
+func toAdminUserResponse(user *models.User) *dto.AdminUserResponse {
+	resp := &dto.AdminUserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Roles:           roleNames(user.Roles),
+		IsActive:        user.IsActive,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		LockedUntil:     user.LockedUntil,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		resp.DeletedAt = &deletedAt
+	}
+	if user.Profile != nil {
+		resp.Profile = &dto.UserProfileResponse{
+			FirstName: user.Profile.FirstName,
+			LastName:  user.Profile.LastName,
+		}
+	}
+	return resp
+}
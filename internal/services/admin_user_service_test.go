@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+// adminUserIDs returns the IDs of the users ListUsers finds for query
+func adminUserIDs(t *testing.T, service AdminUserService, query dto.AdminUserListQuery) []uint {
+	t.Helper()
+	if query.Page == 0 {
+		query.Page, query.Limit = 1, 20
+	}
+	users, total, err := service.ListUsers(&query)
+	testutil.AssertNoError(t, err)
+	ids := make([]uint, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	testutil.AssertTrue(t, int(total) >= len(ids), "total %d is below the page size %d", total, len(ids))
+	return ids
+}
+
+func TestAdminListUsersSearchesAndFilters(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	service := NewAdminUserService(db, nil, NewSessionService(db, nil), NewTokenVersionService(db, nil), NewRoleService(db, nil))
+	admin := testutil.CreateAdminUserFixture(db)
+	jane := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	bob := testutil.CreateUserFixture(db, "Bob", "b_smith@example.com", "password123", "user")
+
+	testutil.AssertEqual(t, []uint{jane.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{Search: " JANE@example "}))
+	testutil.AssertEqual(t, []uint{bob.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{Search: "bob"}))
+	// Wildcards in the search term match literally
+	testutil.AssertEqual(t, []uint{bob.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{Search: "b_"}))
+	testutil.AssertLen(t, adminUserIDs(t, service, dto.AdminUserListQuery{Search: "%"}), 0)
+	testutil.AssertEqual(t, []uint{admin.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{Role: "admin"}))
+
+	_, err := service.SetActive(admin.ID, bob.ID, false)
+	testutil.AssertNoError(t, err)
+	inactive := false
+	testutil.AssertEqual(t, []uint{bob.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{IsActive: &inactive}))
+
+	users, total, err := service.ListUsers(&dto.AdminUserListQuery{Page: 2, Limit: 2})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int64(3), total)
+	testutil.AssertLen(t, users, 1)
+}
+
+func TestAdminDeactivateSignsUserOut(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	sessions := NewSessionService(db, nil)
+	versions := NewTokenVersionService(db, nil)
+	service := NewAdminUserService(db, nil, sessions, versions, NewRoleService(db, nil))
+	admin := testutil.CreateAdminUserFixture(db)
+	user := testutil.CreateStandardUserFixture(db)
+	session, err := sessions.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	resp, err := service.SetActive(admin.ID, user.ID, false)
+	testutil.AssertNoError(t, err)
+	testutil.AssertFalse(t, resp.IsActive, "user must be inactive")
+	err = versions.Check(context.Background(), user.ID, user.TokenVersion)
+	testutil.AssertTrue(t, errors.Is(err, ErrTokenVersionMismatch), "token issued before deactivation: %v", err)
+	var stored models.UserSession
+	testutil.AssertNoError(t, db.First(&stored, session.ID).Error)
+	testutil.AssertEqual(t, SessionRevokedDeactivated, *stored.RevokedReason)
+
+	// Reactivating does not invalidate tokens again
+	resp, err = service.SetActive(admin.ID, user.ID, true)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, resp.IsActive, "user must be active again")
+	testutil.AssertNoError(t, versions.Check(context.Background(), user.ID, user.TokenVersion+1))
+}
+
+func TestAdminDeleteAndRestoreUser(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	versions := NewTokenVersionService(db, nil)
+	service := NewAdminUserService(db, nil, NewSessionService(db, nil), versions, NewRoleService(db, nil))
+	admin := testutil.CreateAdminUserFixture(db)
+	user := testutil.CreateStandardUserFixture(db)
+
+	testutil.AssertNoError(t, service.DeleteUser(admin.ID, user.ID))
+	err := versions.Check(context.Background(), user.ID, user.TokenVersion)
+	testutil.AssertError(t, err, "tokens of a deleted user must be rejected")
+	testutil.AssertEqual(t, []uint{admin.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{}))
+	testutil.AssertEqual(t, []uint{user.ID}, adminUserIDs(t, service, dto.AdminUserListQuery{Deleted: true}))
+	deleted, err := service.GetUser(user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotNil(t, deleted.DeletedAt)
+	err = service.DeleteUser(admin.ID, user.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrUserNotFound), "deleted twice: %v", err)
+
+	restored, err := service.RestoreUser(admin.ID, user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, restored.DeletedAt == nil, "restored user must not be deleted")
+	_, err = service.RestoreUser(admin.ID, user.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrUserNotDeleted), "restored twice: %v", err)
+}
+
+func TestAdminCannotModifySelf(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewAdminUserService(db, nil, NewSessionService(db, nil), NewTokenVersionService(db, nil), NewRoleService(db, nil))
+	admin := testutil.CreateAdminUserFixture(db)
+
+	_, err := service.SetActive(admin.ID, admin.ID, false)
+	testutil.AssertTrue(t, errors.Is(err, ErrCannotModifySelf), "deactivate self: %v", err)
+	_, err = service.SetRoles(admin.ID, admin.ID, []string{"user"})
+	testutil.AssertTrue(t, errors.Is(err, ErrCannotModifySelf), "demote self: %v", err)
+	err = service.DeleteUser(admin.ID, admin.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrCannotModifySelf), "delete self: %v", err)
+
+	stored, err := service.GetUser(admin.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, stored.IsActive, "admin must stay active")
+	testutil.AssertEqual(t, []string{"admin"}, stored.Roles)
+	testutil.AssertTrue(t, stored.DeletedAt == nil, "admin must not be deleted")
+}
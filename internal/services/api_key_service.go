@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix marks API keys so they can be told apart from JWTs and found
+// by secret scanners.
+const APIKeyPrefix = "fbk_"
+
+const (
+	apiKeyDisplayLength = len(APIKeyPrefix) + 8
+	maxAPIKeysPerUser   = 50
+	// lastUsedResolution limits last_used_at writes to one per key and interval
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrInvalidAPIKey      = errors.New("invalid or expired api key")
+	ErrAPIKeyLimitReached = errors.New("api key limit reached")
+	ErrAPIKeyExpiry       = errors.New("expires_at must be in the future")
+)
+
+// APIKeyPrincipal is the user an API key authenticates as
+type APIKeyPrincipal struct {
+	KeyID         uint
+	UserID        uint
+	Email         string
+	EmailVerified bool
+	Roles         []string
+	Scopes        []string
+	ExpiresAt     *time.Time
+}
+
+type APIKeyService interface {
+	Create(userID uint, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	List(userID uint) ([]dto.APIKeyResponse, error)
+	Revoke(userID, keyID uint) error
+	Authenticate(ctx context.Context, rawKey string) (*APIKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) APIKeyService {
+	return &apiKeyService{db: db}
+}
+
+// Create issues a new key. The secret is returned once and only its hash is stored.
+func (s *apiKeyService) Create(userID uint, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrAPIKeyExpiry
+	}
+	var count int64
+	if err := s.db.Model(&models.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	raw := APIKeyPrefix + utils.RandomString(32)
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    raw[:apiKeyDisplayLength],
+		KeyHash:   hashToken(raw),
+		Scopes:    normalizeScopes(req.Scopes),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.db.Create(key).Error; err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("API key created", "user_id", userID, "key_id", key.ID, "prefix", key.Prefix)
+	return &dto.CreateAPIKeyResponse{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+func (s *apiKeyService) List(userID uint) ([]dto.APIKeyResponse, error) {
+	var keys []models.APIKey
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	resp := make([]dto.APIKeyResponse, len(keys))
+	for i := range keys {
+		resp[i] = toAPIKeyResponse(&keys[i])
+	}
+	return resp, nil
+}
+
+func (s *apiKeyService) Revoke(userID, keyID uint) error {
+	result := s.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	utils.Log("Security").Info("API key revoked", "user_id", userID, "key_id", keyID)
+	return nil
+}
+
+// Authenticate resolves a raw key to its owner. Revoked and expired keys and
+// keys of inactive users are rejected.
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*APIKeyPrincipal, error) {
+	if !strings.HasPrefix(rawKey, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	var key models.APIKey
+	if err := s.db.WithContext(ctx).Where("key_hash = ?", hashToken(rawKey)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, key.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrInvalidAPIKey
+	}
+	roles, err := loadRoleNames(s.db.WithContext(ctx), user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.db.WithContext(ctx).Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			utils.LogCtx(ctx, "Auth").Warn("Failed to update api key last use", "key_id", key.ID, "error", err)
+		}
+	}
+
+	return &APIKeyPrincipal{
+		KeyID:         key.ID,
+		UserID:        user.ID,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		Roles:         roles,
+		Scopes:        key.ScopeList(),
+		ExpiresAt:     key.ExpiresAt,
+	}, nil
+}
+
+// normalizeScopes lowercases, deduplicates and space-joins the requested scopes
+func normalizeScopes(scopes []string) string {
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		out = append(out, scope)
+	}
+	return strings.Join(out, " ")
+}
+
+func toAPIKeyResponse(key *models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
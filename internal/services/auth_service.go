@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
 	"go-fiber-boilerplate/internal/dto"
 	"go-fiber-boilerplate/internal/models"
 	"go-fiber-boilerplate/pkg/jwt"
@@ -22,26 +24,69 @@ var (
 	ErrInvalidRefreshToken    = errors.New("invalid refresh token")
 	ErrPasswordResetDisabled  = errors.New("password reset email service is not configured")
 	ErrInvalidResetToken      = errors.New("invalid or expired reset token")
+	ErrEmailNotVerified       = errors.New("email address is not verified")
+	ErrInvalidVerifyToken     = errors.New("invalid or expired verification token")
+	ErrInvalidMFAToken        = errors.New("invalid or expired mfa token")
+	ErrMagicLinkDisabled      = errors.New("magic link email service is not configured")
+	ErrInvalidMagicLink       = errors.New("invalid or expired login link")
 )
 
 type AuthService interface {
 	Register(req *dto.RegisterRequest) (*models.User, error)
-	Login(req *dto.LoginRequest) (*dto.LoginResponse, error)
-	RefreshToken(refreshTokenString string) (string, error)
+	RegisterInvited(req *dto.RegisterRequest, accept func(tx *gorm.DB, user *models.User) error) (*models.User, error)
+	Login(req *dto.LoginRequest, client ClientInfo) (*dto.LoginResponse, error)
+	VerifyMFA(mfaToken, code string, client ClientInfo) (*dto.LoginResponse, error)
+	CompleteLogin(user *models.User, client ClientInfo) (*dto.LoginResponse, error)
+	RefreshToken(refreshTokenString string, client ClientInfo) (*dto.RefreshTokenResponse, error)
+	ReissueTokens(userID, sessionID uint) (*dto.RefreshTokenResponse, error)
+	Logout(userID, sessionID uint, tokenID string, tokenExpiresAt time.Time) error
+	LogoutAll(userID uint, tokenID string, tokenExpiresAt time.Time) error
 	ForgotPassword(email string) error
-	ResetPassword(token, newPassword string) error
+	ResetPassword(token, newPassword string, revokeSessions bool) error
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	RequestMagicLink(email string) error
+	LoginWithMagicLink(token string, client ClientInfo) (*dto.LoginResponse, error)
 }
 
 type authService struct {
-	db           *gorm.DB
-	emailService EmailService
+	db             *gorm.DB
+	emailService   EmailService
+	sessionService SessionService
+	mfaService     MFAService
+	lockoutService LockoutService
+	tokenVersions  TokenVersionService
+	passwordPolicy PasswordPolicyService
+	denylist       *cache.TokenDenylist
+	tokenManager   *jwt.TokenManager
 }
 
-func NewAuthService(db *gorm.DB, emailService EmailService) AuthService {
-	return &authService{db: db, emailService: emailService}
+func NewAuthService(db *gorm.DB, emailService EmailService, sessionService SessionService, mfaService MFAService, lockoutService LockoutService, tokenVersions TokenVersionService, passwordPolicy PasswordPolicyService, denylist *cache.TokenDenylist, tokenManager *jwt.TokenManager) AuthService {
+	return &authService{
+		db:             db,
+		emailService:   emailService,
+		sessionService: sessionService,
+		mfaService:     mfaService,
+		lockoutService: lockoutService,
+		tokenVersions:  tokenVersions,
+		passwordPolicy: passwordPolicy,
+		denylist:       denylist,
+		tokenManager:   tokenManager,
+	}
 }
 
 func (s *authService) Register(req *dto.RegisterRequest) (*models.User, error) {
+	return s.register(req, nil)
+}
+
+// RegisterInvited registers a user who followed an emailed invitation. The
+// invitation proves ownership of the address, so the email starts out
+// verified; accept runs in the registration transaction to consume it.
+func (s *authService) RegisterInvited(req *dto.RegisterRequest, accept func(tx *gorm.DB, user *models.User) error) (*models.User, error) {
+	return s.register(req, accept)
+}
+
+func (s *authService) register(req *dto.RegisterRequest, accept func(tx *gorm.DB, user *models.User) error) (*models.User, error) {
 	var existingUser models.User
 	if err := s.db.Where("email = ?", strings.ToLower(req.Email)).First(&existingUser).Error; err == nil {
 		return nil, ErrEmailAlreadyRegistered
@@ -49,24 +94,38 @@ func (s *authService) Register(req *dto.RegisterRequest) (*models.User, error) {
 		return nil, err
 	}
 
+	candidate := &models.User{
+		Email:   strings.ToLower(req.Email),
+		Profile: &models.UserProfile{FirstName: req.FirstName, LastName: req.LastName},
+	}
+	if err := s.passwordPolicy.Check(context.Background(), "password", req.Password, candidate); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	role := "user"
 	tx := s.db.Begin()
 	user := &models.User{
 		Email:               strings.ToLower(req.Email),
 		Password:            &hashedPassword,
 		PasswordIsSetByUser: true,
-		Role:                &role,
 		IsActive:            true,
 	}
+	if accept != nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
 	if err := tx.Create(user).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
+	if err := assignRoles(tx, user.ID, defaultRole); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	profile := &models.UserProfile{
 		UserID:    user.ID,
@@ -78,14 +137,41 @@ func (s *authService) Register(req *dto.RegisterRequest) (*models.User, error) {
 		return nil, err
 	}
 
+	if err := s.passwordPolicy.Remember(tx, user.ID, hashedPassword); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if accept != nil {
+		if err := accept(tx, user); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit().Error; err != nil {
+			return nil, err
+		}
+		user.Profile = profile
+		return user, nil
+	}
+
+	verifyToken, err := createEmailVerification(tx, user.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 	user.Profile = profile
+
+	if err := s.emailService.SendEmailVerification(user.Email, verifyToken); err != nil {
+		utils.Log("Auth").Error("Failed to send verification email", "user_id", user.ID, "error", err)
+	}
 	return user, nil
 }
 
-func (s *authService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
+func (s *authService) Login(req *dto.LoginRequest, client ClientInfo) (*dto.LoginResponse, error) {
 	var user models.User
 	if err := s.db.Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -96,46 +182,195 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 	if !user.IsActive {
 		return nil, ErrInactiveAccount
 	}
+	if err := s.lockoutService.Check(&user); err != nil {
+		return nil, err
+	}
 	if user.Password == nil {
 		return nil, ErrInvalidCredentials
 	}
 	if err := utils.VerifyPassword(req.Password, *user.Password); err != nil {
+		s.lockoutService.RecordFailure(user.ID)
 		return nil, ErrInvalidCredentials
 	}
+	if utils.PasswordNeedsRehash(*user.Password) {
+		s.rehashPassword(&user, req.Password)
+	}
 
-	tm := jwt.NewTokenManager(config.AppConfig.JWTSecret)
-	role := roleString(user.Role)
-	accessToken, err := tm.GenerateAccessToken(user.ID, user.Email, role, config.AppConfig.JWTExpiry)
+	resp, err := s.CompleteLogin(&user, client)
 	if err != nil {
 		return nil, err
 	}
-	refreshToken, err := tm.GenerateRefreshToken(user.ID, user.Email, config.AppConfig.JWTRefreshExpiry)
+	// Failures only reset once the second factor is passed as well
+	if !resp.MFARequired {
+		s.lockoutService.Reset(user.ID)
+	}
+	return resp, nil
+}
+
+// CompleteLogin finishes a login for a user whose first factor has already
+// been checked, by password or an external provider. It enforces email
+// verification, returns an MFA challenge when 2FA is enabled, and otherwise
+// starts a session and issues the token pair.
+func (s *authService) CompleteLogin(user *models.User, client ClientInfo) (*dto.LoginResponse, error) {
+	if !user.IsActive {
+		return nil, ErrInactiveAccount
+	}
+	if config.AppConfig.EmailVerificationRequired && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	mfaEnabled, err := s.mfaService.IsEnabled(user.ID)
 	if err != nil {
 		return nil, err
 	}
-	return &dto.LoginResponse{
-		Token:        accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(config.AppConfig.JWTExpiry.Seconds()),
-		Role:         role,
-	}, nil
+	if mfaEnabled {
+		challenge, err := s.tokenManager.GenerateMFAChallengeToken(user.ID, config.AppConfig.MFAChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{MFARequired: true, MFAToken: challenge}, nil
+	}
+
+	session, err := s.sessionService.Create(user.ID, config.AppConfig.JWTRefreshExpiry, client)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, session)
 }
 
-func (s *authService) RefreshToken(refreshTokenString string) (string, error) {
-	tm := jwt.NewTokenManager(config.AppConfig.JWTSecret)
-	claims, err := tm.ValidateRefreshToken(refreshTokenString)
+// VerifyMFA completes a two-step login by exchanging the challenge token from
+// Login and a TOTP or recovery code for a token pair. Each challenge can be
+// used once: it is claimed before the code is checked, so concurrent requests
+// cannot both redeem it, and released again only when the code was wrong.
+func (s *authService) VerifyMFA(mfaToken, code string, client ClientInfo) (*dto.LoginResponse, error) {
+	claims, err := s.tokenManager.ValidateMFAChallengeToken(mfaToken)
+	if err != nil || claims.ExpiresAt == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	ctx := context.Background()
+	if !s.denylist.ClaimToken(ctx, claims.ID, claims.ExpiresAt.Time) {
+		return nil, ErrInvalidMFAToken
+	}
+
+	var user models.User
+	if err := s.db.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrInactiveAccount
+	}
+
+	if err := s.lockoutService.Check(&user); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.VerifyCode(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			utils.Log("Security").Warn("Invalid MFA code", "user_id", user.ID)
+			s.lockoutService.RecordFailure(user.ID)
+			s.denylist.ReleaseToken(ctx, claims.ID)
+		}
+		return nil, err
+	}
+	s.lockoutService.Reset(user.ID)
+
+	session, err := s.sessionService.Create(user.ID, config.AppConfig.JWTRefreshExpiry, client)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(&user, session)
+}
+
+func (s *authService) RefreshToken(refreshTokenString string, client ClientInfo) (*dto.RefreshTokenResponse, error) {
+	claims, err := s.tokenManager.ValidateRefreshToken(refreshTokenString)
 	if err != nil {
-		return "", ErrInvalidRefreshToken
+		return nil, ErrInvalidRefreshToken
 	}
 
 	var user models.User
 	if err := s.db.First(&user, claims.UserID).Error; err != nil {
-		return "", err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
 	}
 	if !user.IsActive {
-		return "", ErrInactiveAccount
+		return nil, ErrInactiveAccount
+	}
+	// Tokens issued before the last password change are dead, checked before
+	// rotating so a stale token cannot touch the session
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionService.Rotate(claims.SessionID, claims.ID, config.AppConfig.JWTRefreshExpiry, client)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionRevoked) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if session.UserID != user.ID {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tokens, err := s.issueTokens(&user, session)
+	if err != nil {
+		return nil, err
+	}
+	return toRefreshTokenResponse(tokens), nil
+}
+
+// ReissueTokens issues a new token pair for one of the user's active sessions,
+// e.g. to keep the current device signed in after a password change bumped
+// the token version.
+func (s *authService) ReissueTokens(userID, sessionID uint) (*dto.RefreshTokenResponse, error) {
+	var session models.UserSession
+	if err := s.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	tokens, err := s.issueTokens(&user, &session)
+	if err != nil {
+		return nil, err
 	}
-	return tm.GenerateAccessToken(user.ID, user.Email, roleString(user.Role), config.AppConfig.JWTExpiry)
+	return toRefreshTokenResponse(tokens), nil
+}
+
+// Logout revokes the session behind the presented access token and denylists
+// the token itself until it expires.
+func (s *authService) Logout(userID, sessionID uint, tokenID string, tokenExpiresAt time.Time) error {
+	s.denylist.RevokeToken(context.Background(), tokenID, tokenExpiresAt)
+	if sessionID == 0 {
+		return nil
+	}
+	var session models.UserSession
+	if err := s.db.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := s.sessionService.Revoke(session.ID, SessionRevokedLogout); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// LogoutAll revokes every session of the user, signing them out on all devices.
+func (s *authService) LogoutAll(userID uint, tokenID string, tokenExpiresAt time.Time) error {
+	s.denylist.RevokeToken(context.Background(), tokenID, tokenExpiresAt)
+	return s.sessionService.RevokeAllForUser(userID, SessionRevokedLogoutAll)
 }
 
 func (s *authService) ForgotPassword(email string) error {
@@ -151,19 +386,17 @@ func (s *authService) ForgotPassword(email string) error {
 		return err
 	}
 
-	token := utils.RandomString(32)
-	reset := &models.PasswordReset{
-		UserID:    user.ID,
-		TokenHash: hashToken(token),
-		ExpiresAt: time.Now().Add(30 * time.Minute),
-	}
-	if err := s.db.Create(reset).Error; err != nil {
+	token, err := createPasswordReset(s.db, user.ID)
+	if err != nil {
 		return err
 	}
 	return s.emailService.SendPasswordReset(user.Email, token)
 }
 
-func (s *authService) ResetPassword(token, newPassword string) error {
+// ResetPassword sets a new password from a reset token and bumps the token
+// version, which invalidates every token issued so far. With revokeSessions
+// every session is signed out as well.
+func (s *authService) ResetPassword(token, newPassword string, revokeSessions bool) error {
 	var reset models.PasswordReset
 	if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).First(&reset).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -172,13 +405,24 @@ func (s *authService) ResetPassword(token, newPassword string) error {
 		return err
 	}
 
+	var user models.User
+	if err := s.db.First(&user, reset.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := s.passwordPolicy.Check(context.Background(), "new_password", newPassword, &user); err != nil {
+		return err
+	}
+
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
 		return err
 	}
 
 	now := time.Now()
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&models.User{}).Where("id = ?", reset.UserID).Updates(map[string]interface{}{
 			"password":                hashedPassword,
 			"password_is_set_by_user": true,
@@ -186,18 +430,240 @@ func (s *authService) ResetPassword(token, newPassword string) error {
 		}).Error; err != nil {
 			return err
 		}
+		if err := s.tokenVersions.Bump(tx, reset.UserID); err != nil {
+			return err
+		}
+		if err := s.passwordPolicy.Remember(tx, reset.UserID, hashedPassword); err != nil {
+			return err
+		}
 		return tx.Model(&reset).Update("used_at", now).Error
 	})
+	if err != nil {
+		return err
+	}
+	s.tokenVersions.Invalidate(context.Background(), reset.UserID)
+	if revokeSessions {
+		return s.sessionService.RevokeAllForUser(reset.UserID, SessionRevokedPasswordReset)
+	}
+	return nil
+}
+
+// VerifyEmail consumes a verification token and marks the user's email as verified.
+func (s *authService) VerifyEmail(token string) error {
+	var verification models.EmailVerification
+	if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).First(&verification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerifyToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", verification.UserID).
+			Updates(map[string]interface{}{"email_verified_at": now, "updated_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.EmailVerification{}).
+			Where("user_id = ? AND used_at IS NULL", verification.UserID).
+			Update("used_at", now).Error
+	})
+}
+
+// ResendVerification issues a fresh verification token. Unknown and already
+// verified addresses are ignored so the endpoint cannot be used to probe accounts.
+func (s *authService) ResendVerification(email string) error {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsEmailVerified() || !user.IsActive {
+		return nil
+	}
+
+	var token string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.EmailVerification{}).Error; err != nil {
+			return err
+		}
+		var err error
+		token, err = createEmailVerification(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return s.emailService.SendEmailVerification(user.Email, token)
+}
+
+// RequestMagicLink emails a single-use login link. Like ForgotPassword it does
+// not reveal whether the address belongs to an account.
+func (s *authService) RequestMagicLink(email string) error {
+	if s.emailService == nil || !s.emailService.Enabled() {
+		return ErrMagicLinkDisabled
+	}
+
+	var user models.User
+	if err := s.db.Where("email = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	token := utils.RandomString(32)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.MagicLink{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.MagicLink{
+			UserID:    user.ID,
+			TokenHash: hashToken(token),
+			ExpiresAt: time.Now().Add(config.AppConfig.MagicLinkTTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	return s.emailService.SendMagicLink(user.Email, token)
+}
+
+// LoginWithMagicLink consumes a login link and logs the user in. Opening the
+// link proves ownership of the address, so the email is marked verified.
+func (s *authService) LoginWithMagicLink(token string, client ClientInfo) (*dto.LoginResponse, error) {
+	var link models.MagicLink
+	if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.MagicLink{}).Where("id = ? AND used_at IS NULL", link.ID).Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidMagicLink
+	}
+
+	var user models.User
+	if err := s.db.First(&user, link.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	if !user.IsEmailVerified() {
+		if err := s.db.Model(&user).Updates(map[string]interface{}{"email_verified_at": now, "updated_at": now}).Error; err != nil {
+			return nil, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+	return s.CompleteLogin(&user, client)
+}
+
+// rehashPassword upgrades a verified password to the configured algorithm
+// and parameters. Failures are logged and never fail the login.
+func (s *authService) rehashPassword(user *models.User, plain string) {
+	hashed, err := utils.HashPassword(plain)
+	if err != nil {
+		utils.Log("Auth").Error("Failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	// Only replace the hash that was verified, in case the password changed meanwhile
+	result := s.db.Model(&models.User{}).Where("id = ? AND password = ?", user.ID, *user.Password).UpdateColumn("password", hashed)
+	if result.Error != nil {
+		utils.Log("Auth").Error("Failed to store rehashed password", "user_id", user.ID, "error", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		user.Password = &hashed
+		utils.Log("Auth").Info("Password rehashed", "user_id", user.ID)
+	}
+}
+
+// issueTokens signs an access token and a refresh token carrying the session's
+// current refresh token ID. The access token carries the session's active
+// organization.
+func (s *authService) issueTokens(user *models.User, session *models.UserSession) (*dto.LoginResponse, error) {
+	roles, err := loadRoleNames(s.db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var organizationID uint
+	if session.OrganizationID != nil {
+		organizationID = *session.OrganizationID
+	}
+	accessToken, err := s.tokenManager.GenerateAccessToken(jwt.AccessTokenClaims{
+		UserID:         user.ID,
+		Email:          user.Email,
+		EmailVerified:  user.IsEmailVerified(),
+		Roles:          roles,
+		OrganizationID: organizationID,
+		SessionID:      session.ID,
+		TokenVersion:   user.TokenVersion,
+	}, config.AppConfig.JWTExpiry)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.tokenManager.GenerateRefreshToken(user.ID, user.Email, session.ID, session.RefreshTokenID, user.TokenVersion, config.AppConfig.JWTRefreshExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AppConfig.JWTExpiry.Seconds()),
+		Roles:        roles,
+	}, nil
 }
 
-func roleString(role *string) string {
-	if role == nil {
-		return ""
+func toRefreshTokenResponse(tokens *dto.LoginResponse) *dto.RefreshTokenResponse {
+	return &dto.RefreshTokenResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}
-	return *role
 }
 
 func hashToken(token string) string {
 	sum := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(sum[:])
 }
+
+// createPasswordReset stores a hashed password reset token and returns the raw token
+func createPasswordReset(tx *gorm.DB, userID uint) (string, error) {
+	token := utils.RandomString(32)
+	reset := &models.PasswordReset{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(30 * time.Minute),
+	}
+	if err := tx.Create(reset).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// createEmailVerification stores a hashed verification token and returns the raw token
+func createEmailVerification(tx *gorm.DB, userID uint) (string, error) {
+	token := utils.RandomString(32)
+	verification := &models.EmailVerification{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(config.AppConfig.EmailVerificationTTL),
+	}
+	if err := tx.Create(verification).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
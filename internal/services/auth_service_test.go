@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/password"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var testAuthConfig = &config.Config{
+	JWTSecret:        "test-secret-key-that-is-long-enough",
+	JWTExpiry:        15 * time.Minute,
+	JWTRefreshExpiry: time.Hour,
+}
+
+// newAuthService returns an auth service on db whose sessions use denylist
+func newAuthService(db *gorm.DB, emailService EmailService, denylist *cache.TokenDenylist) AuthService {
+	return NewAuthService(db, emailService, NewSessionService(db, denylist), NewMFAService(db, nil, "Test"),
+		NewLockoutService(db, nil, LockoutOptions{Threshold: 5, Duration: time.Minute}), NewTokenVersionService(db, nil),
+		NewPasswordPolicyService(db, password.NewPolicy(), 0), denylist, jwt.NewTokenManager(testAuthConfig.JWTSecret))
+}
+
+func TestRefreshTokenRotatesAndDetectsReuse(t *testing.T) {
+	config.AppConfig = testAuthConfig
+	db := testutil.NewTestDB(t)
+	service := newAuthService(db, nil, nil)
+	testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	login, err := service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	refreshed, err := service.RefreshToken(login.RefreshToken, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	again, err := service.RefreshToken(refreshed.RefreshToken, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	// Replaying a rotated token revokes the session, so the newest token of
+	// the same family stops working as well
+	_, err = service.RefreshToken(login.RefreshToken, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrRefreshTokenReused), "replayed token: %v", err)
+	_, err = service.RefreshToken(again.RefreshToken, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidRefreshToken), "token of the revoked family: %v", err)
+
+	// Other sessions of the user are a separate family and keep working
+	other, err := service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	_, err = service.RefreshToken(other.RefreshToken, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	_, err = service.RefreshToken("not-a-token", ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidRefreshToken), "malformed token: %v", err)
+}
+
+func TestLogoutAllRevokesEverySession(t *testing.T) {
+	config.AppConfig = testAuthConfig
+	db := testutil.NewTestDB(t)
+	denylist := cache.NewTokenDenylist(nil)
+	service := newAuthService(db, nil, denylist)
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	other := testutil.CreateUserFixture(db, "John", "john@example.com", "password123", "user")
+	login := func(email string) *dto.LoginResponse {
+		tokens, err := service.Login(&dto.LoginRequest{Email: email, Password: "password123"}, ClientInfo{})
+		testutil.AssertNoError(t, err)
+		return tokens
+	}
+	first, second, foreign := login("jane@example.com"), login("jane@example.com"), login("john@example.com")
+
+	testutil.AssertNoError(t, service.LogoutAll(user.ID, "current-jti", time.Now().Add(time.Minute)))
+	testutil.AssertTrue(t, denylist.IsRevoked(context.Background(), "current-jti", 0), "the presented token is denylisted")
+	for _, tokens := range []*dto.LoginResponse{first, second} {
+		_, err := service.RefreshToken(tokens.RefreshToken, ClientInfo{})
+		testutil.AssertTrue(t, errors.Is(err, ErrInvalidRefreshToken), "refresh after logout-all: %v", err)
+	}
+	var sessions []models.UserSession
+	testutil.AssertNoError(t, db.Where("user_id = ?", user.ID).Find(&sessions).Error)
+	for _, session := range sessions {
+		testutil.AssertEqual(t, SessionRevokedLogoutAll, *session.RevokedReason)
+		testutil.AssertTrue(t, denylist.IsRevoked(context.Background(), "", session.ID), "access tokens of session %d", session.ID)
+	}
+
+	_, err := service.RefreshToken(foreign.RefreshToken, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, service.LogoutAll(other.ID, "", time.Time{}))
+}
+
+func TestResetPasswordRevokesSessionsOnRequest(t *testing.T) {
+	config.AppConfig = testAuthConfig
+	db := testutil.NewTestDB(t)
+	service := newAuthService(db, nil, nil)
+	sessions := NewSessionService(db, nil)
+	versions := NewTokenVersionService(db, nil)
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	_, err := sessions.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	token, err := createPasswordReset(db, user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, service.ResetPassword(token, "new-password-456", false))
+	err = versions.Check(context.Background(), user.ID, user.TokenVersion)
+	testutil.AssertTrue(t, errors.Is(err, ErrTokenVersionMismatch), "token issued before the reset: %v", err)
+	testutil.AssertLen(t, activeSessionIDs(t, sessions, user.ID), 1)
+	err = service.ResetPassword(token, "another-password-789", true)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidResetToken), "token used twice: %v", err)
+
+	token, err = createPasswordReset(db, user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, service.ResetPassword(token, "another-password-789", true))
+	testutil.AssertLen(t, activeSessionIDs(t, sessions, user.ID), 0)
+}
+
+// verificationMailer records the verification tokens it sends
+type verificationMailer struct {
+	noopEmailService
+	tokens []string
+}
+
+func (m *verificationMailer) SendEmailVerification(_, token string) error {
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func newVerificationService(t *testing.T) (AuthService, *verificationMailer, *gorm.DB) {
+	t.Helper()
+	cfg := *testAuthConfig
+	cfg.EmailVerificationTTL = time.Hour
+	cfg.EmailVerificationRequired = true
+	config.AppConfig = &cfg
+	db := testutil.NewTestDB(t)
+	mailer := &verificationMailer{}
+	service := newAuthService(db, mailer, nil)
+	return service, mailer, db
+}
+
+func TestVerifyMFARedeemsChallengeOnce(t *testing.T) {
+	cfg := *testAuthConfig
+	cfg.MFAChallengeTTL = time.Minute
+	config.AppConfig = &cfg
+	db := testutil.NewTestDB(t)
+	service := newAuthService(db, nil, cache.NewTokenDenylist(nil))
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	enabledAt := time.Now()
+	testutil.AssertNoError(t, db.Create(&models.UserMFA{UserID: user.ID, SecretEncrypted: "unused", EnabledAt: &enabledAt}).Error)
+	codes, err := replaceRecoveryCodes(db, user.ID)
+	testutil.AssertNoError(t, err)
+
+	login, err := service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, login.MFARequired, "login must ask for a second factor")
+
+	// A wrong code leaves the challenge usable
+	_, err = service.VerifyMFA(login.MFAToken, "wrong-code", ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMFACode), "wrong code: %v", err)
+
+	// Concurrent requests with valid codes redeem the challenge once
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.VerifyMFA(login.MFAToken, codes[i], ClientInfo{})
+		}(i)
+	}
+	wg.Wait()
+	redeemed := 0
+	for _, err := range errs {
+		if err == nil {
+			redeemed++
+			continue
+		}
+		testutil.AssertTrue(t, errors.Is(err, ErrInvalidMFAToken), "challenge already redeemed: %v", err)
+	}
+	testutil.AssertEqual(t, 1, redeemed)
+
+	_, err = service.VerifyMFA(login.MFAToken, codes[5], ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMFAToken), "replayed challenge: %v", err)
+}
+
+func TestVerifyEmailUnlocksLogin(t *testing.T) {
+	service, mailer, _ := newVerificationService(t)
+	credentials := &dto.LoginRequest{Email: "jane@example.com", Password: "password123"}
+	_, err := service.Register(&dto.RegisterRequest{Email: "Jane@example.com", Password: "password123", FirstName: "Jane"})
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, mailer.tokens, 1)
+
+	_, err = service.Login(credentials, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrEmailNotVerified), "unverified login: %v", err)
+	testutil.AssertTrue(t, errors.Is(service.VerifyEmail("forged"), ErrInvalidVerifyToken), "unknown token")
+
+	testutil.AssertNoError(t, service.VerifyEmail(mailer.tokens[0]))
+	err = service.VerifyEmail(mailer.tokens[0])
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidVerifyToken), "token used twice: %v", err)
+	tokens, err := service.Login(credentials, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	claims, err := jwt.NewTokenManager(testAuthConfig.JWTSecret).ValidateAccessToken(tokens.Token)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, claims.EmailVerified, "access token carries the verified flag")
+}
+
+func TestVerifyEmailRejectsExpiredToken(t *testing.T) {
+	service, mailer, db := newVerificationService(t)
+	_, err := service.Register(&dto.RegisterRequest{Email: "jane@example.com", Password: "password123", FirstName: "Jane"})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, db.Model(&models.EmailVerification{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	err = service.VerifyEmail(mailer.tokens[0])
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidVerifyToken), "expired token: %v", err)
+}
+
+func TestResendVerification(t *testing.T) {
+	service, mailer, _ := newVerificationService(t)
+	_, err := service.Register(&dto.RegisterRequest{Email: "jane@example.com", Password: "password123", FirstName: "Jane"})
+	testutil.AssertNoError(t, err)
+
+	// Unknown addresses are ignored without revealing it
+	testutil.AssertNoError(t, service.ResendVerification("nobody@example.com"))
+	testutil.AssertLen(t, mailer.tokens, 1)
+
+	// A resend replaces the outstanding token
+	testutil.AssertNoError(t, service.ResendVerification("JANE@example.com"))
+	testutil.AssertLen(t, mailer.tokens, 2)
+	err = service.VerifyEmail(mailer.tokens[0])
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidVerifyToken), "replaced token: %v", err)
+	testutil.AssertNoError(t, service.VerifyEmail(mailer.tokens[1]))
+
+	// Verified addresses get no further emails
+	testutil.AssertNoError(t, service.ResendVerification("jane@example.com"))
+	testutil.AssertLen(t, mailer.tokens, 2)
+}
+
+func TestCompleteLoginRequiresVerifiedEmail(t *testing.T) {
+	service, _, db := newVerificationService(t)
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	// Logins without a password, like social login, pass the same gate
+	_, err := service.CompleteLogin(user, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrEmailNotVerified), "unverified user: %v", err)
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	tokens, err := service.CompleteLogin(user, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, "", tokens.Token)
+}
+
+// magicLinkMailer records the login links it sends
+type magicLinkMailer struct {
+	noopEmailService
+	tokens []string
+}
+
+func (m *magicLinkMailer) Enabled() bool {
+	return true
+}
+
+func (m *magicLinkMailer) SendMagicLink(_, token string) error {
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func newMagicLinkService(t *testing.T) (AuthService, *magicLinkMailer, *gorm.DB) {
+	t.Helper()
+	cfg := *testAuthConfig
+	cfg.MagicLinkTTL = 15 * time.Minute
+	config.AppConfig = &cfg
+	db := testutil.NewTestDB(t)
+	mailer := &magicLinkMailer{}
+	return newAuthService(db, mailer, nil), mailer, db
+}
+
+func TestMagicLinkWorksOnce(t *testing.T) {
+	service, mailer, db := newMagicLinkService(t)
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	// Unknown addresses are ignored without revealing it
+	testutil.AssertNoError(t, service.RequestMagicLink("nobody@example.com"))
+	testutil.AssertLen(t, mailer.tokens, 0)
+
+	testutil.AssertNoError(t, service.RequestMagicLink("Jane@Example.com"))
+	testutil.AssertLen(t, mailer.tokens, 1)
+	tokens, err := service.LoginWithMagicLink(mailer.tokens[0], ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, "", tokens.RefreshToken)
+	_, err = service.LoginWithMagicLink(mailer.tokens[0], ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMagicLink), "link used twice: %v", err)
+
+	// Opening the link proves the address
+	var stored models.User
+	testutil.AssertNoError(t, db.First(&stored, user.ID).Error)
+	testutil.AssertTrue(t, stored.IsEmailVerified(), "email is verified after a magic link login")
+
+	_, err = service.LoginWithMagicLink("forged", ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMagicLink), "unknown link: %v", err)
+	err = newAuthService(db, NewNoopEmailService(), nil).RequestMagicLink("jane@example.com")
+	testutil.AssertTrue(t, errors.Is(err, ErrMagicLinkDisabled), "without email: %v", err)
+}
+
+func TestMagicLinkExpiresAndIsReplaced(t *testing.T) {
+	service, mailer, db := newMagicLinkService(t)
+	testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	// Requesting a new link invalidates the previous one
+	testutil.AssertNoError(t, service.RequestMagicLink("jane@example.com"))
+	testutil.AssertNoError(t, service.RequestMagicLink("jane@example.com"))
+	_, err := service.LoginWithMagicLink(mailer.tokens[0], ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMagicLink), "replaced link: %v", err)
+
+	testutil.AssertNoError(t, db.Model(&models.MagicLink{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Second)).Error)
+	_, err = service.LoginWithMagicLink(mailer.tokens[1], ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMagicLink), "expired link: %v", err)
+}
+
+func TestLoginRehashesOutdatedPasswords(t *testing.T) {
+	config.AppConfig = testAuthConfig
+	utils.SetPasswordHasher(utils.NewArgon2idHasher(utils.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}))
+	t.Cleanup(func() { utils.SetPasswordHasher(utils.NewArgon2idHasher(utils.DefaultArgon2idParams)) })
+	db := testutil.NewTestDB(t)
+	service := newAuthService(db, NewNoopEmailService(), cache.NewTokenDenylist(nil))
+	// The fixture stores a bcrypt hash, as accounts created before argon2id have
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	stored := func() string {
+		var u models.User
+		testutil.AssertNoError(t, db.First(&u, user.ID).Error)
+		return *u.Password
+	}
+	bcryptHash := stored()
+
+	_, err := service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "wrong-password"}, ClientInfo{})
+	testutil.AssertError(t, err)
+	testutil.AssertEqual(t, bcryptHash, stored(), "a failed login must not rehash")
+
+	_, err = service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	rehashed := stored()
+	testutil.AssertTrue(t, strings.HasPrefix(rehashed, "$argon2id$v=19$m=1024,t=1,p=1$"), "hash after login = %q", rehashed)
+
+	_, err = service.Login(&dto.LoginRequest{Email: "jane@example.com", Password: "password123"}, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, rehashed, stored(), "a current hash is kept")
+}
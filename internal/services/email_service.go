@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 	"html"
+	"strconv"
 	"strings"
 
 	"go-fiber-boilerplate/pkg/mailer"
@@ -12,6 +13,10 @@ import (
 type EmailService interface {
 	Enabled() bool
 	SendPasswordReset(email, token string) error
+	SendEmailVerification(email, token string) error
+	SendMagicLink(email, token string) error
+	SendOrganizationInvitation(email, organizationName, token string) error
+	SendResourceShared(email, resourceName string, resourceID uint, level string) error
 }
 
 type noopEmailService struct{}
@@ -29,17 +34,45 @@ func (noopEmailService) SendPasswordReset(email, _ string) error {
 	return nil
 }
 
+func (noopEmailService) SendEmailVerification(email, _ string) error {
+	utils.Log("Email").Warn("Verification email skipped because email service is disabled", "email", email)
+	return nil
+}
+
+func (noopEmailService) SendMagicLink(email, _ string) error {
+	utils.Log("Email").Warn("Magic link email skipped because email service is disabled", "email", email)
+	return nil
+}
+
+func (noopEmailService) SendOrganizationInvitation(email, organizationName, _ string) error {
+	utils.Log("Email").Warn("Invitation email skipped because email service is disabled", "email", email, "organization", organizationName)
+	return nil
+}
+
+func (noopEmailService) SendResourceShared(email, _ string, resourceID uint, _ string) error {
+	utils.Log("Email").Warn("Share notification skipped because email service is disabled", "email", email, "resource_id", resourceID)
+	return nil
+}
+
 type smtpEmailService struct {
-	mailer           mailer.Mailer
-	appName          string
-	passwordResetURL string
+	mailer               mailer.Mailer
+	appName              string
+	passwordResetURL     string
+	emailVerificationURL string
+	magicLinkURL         string
+	invitationURL        string
+	resourceURL          string
 }
 
-func NewEmailService(m mailer.Mailer, appName, passwordResetURL string) EmailService {
+func NewEmailService(m mailer.Mailer, appName, passwordResetURL, emailVerificationURL, magicLinkURL, invitationURL, resourceURL string) EmailService {
 	return &smtpEmailService{
-		mailer:           m,
-		appName:          appName,
-		passwordResetURL: passwordResetURL,
+		mailer:               m,
+		appName:              appName,
+		passwordResetURL:     passwordResetURL,
+		emailVerificationURL: emailVerificationURL,
+		magicLinkURL:         magicLinkURL,
+		invitationURL:        invitationURL,
+		resourceURL:          resourceURL,
 	}
 }
 
@@ -48,7 +81,7 @@ func (s *smtpEmailService) Enabled() bool {
 }
 
 func (s *smtpEmailService) SendPasswordReset(email, token string) error {
-	resetURL := s.buildPasswordResetURL(token)
+	resetURL := buildTokenURL(s.passwordResetURL, token)
 	msg := &mailer.EmailMessage{
 		To:       []string{email},
 		Subject:  fmt.Sprintf("Reset your %s password", s.appName),
@@ -58,15 +91,59 @@ func (s *smtpEmailService) SendPasswordReset(email, token string) error {
 	return s.mailer.SendEmail(msg)
 }
 
-func (s *smtpEmailService) buildPasswordResetURL(token string) string {
-	if strings.Contains(s.passwordResetURL, "{token}") {
-		return strings.ReplaceAll(s.passwordResetURL, "{token}", token)
+func (s *smtpEmailService) SendEmailVerification(email, token string) error {
+	verifyURL := buildTokenURL(s.emailVerificationURL, token)
+	msg := &mailer.EmailMessage{
+		To:       []string{email},
+		Subject:  fmt.Sprintf("Verify your %s email address", s.appName),
+		HTMLBody: s.emailVerificationHTML(verifyURL),
+		TextBody: s.emailVerificationText(verifyURL),
+	}
+	return s.mailer.SendEmail(msg)
+}
+
+func (s *smtpEmailService) SendMagicLink(email, token string) error {
+	loginURL := buildTokenURL(s.magicLinkURL, token)
+	msg := &mailer.EmailMessage{
+		To:       []string{email},
+		Subject:  fmt.Sprintf("Your %s sign-in link", s.appName),
+		HTMLBody: s.magicLinkHTML(loginURL),
+		TextBody: s.magicLinkText(loginURL),
+	}
+	return s.mailer.SendEmail(msg)
+}
+
+func (s *smtpEmailService) SendOrganizationInvitation(email, organizationName, token string) error {
+	acceptURL := buildTokenURL(s.invitationURL, token)
+	msg := &mailer.EmailMessage{
+		To:       []string{email},
+		Subject:  fmt.Sprintf("You have been invited to join %s on %s", organizationName, s.appName),
+		HTMLBody: s.organizationInvitationHTML(organizationName, acceptURL),
+		TextBody: s.organizationInvitationText(organizationName, acceptURL),
+	}
+	return s.mailer.SendEmail(msg)
+}
+
+func (s *smtpEmailService) SendResourceShared(email, resourceName string, resourceID uint, level string) error {
+	resourceURL := strings.ReplaceAll(s.resourceURL, "{id}", strconv.FormatUint(uint64(resourceID), 10))
+	msg := &mailer.EmailMessage{
+		To:       []string{email},
+		Subject:  fmt.Sprintf("%s was shared with you on %s", resourceName, s.appName),
+		HTMLBody: s.resourceSharedHTML(resourceName, level, resourceURL),
+		TextBody: s.resourceSharedText(resourceName, level, resourceURL),
+	}
+	return s.mailer.SendEmail(msg)
+}
+
+func buildTokenURL(baseURL, token string) string {
+	if strings.Contains(baseURL, "{token}") {
+		return strings.ReplaceAll(baseURL, "{token}", token)
 	}
 	separator := "?"
-	if strings.Contains(s.passwordResetURL, "?") {
+	if strings.Contains(baseURL, "?") {
 		separator = "&"
 	}
-	return s.passwordResetURL + separator + "token=" + token
+	return baseURL + separator + "token=" + token
 }
 
 func (s *smtpEmailService) passwordResetHTML(resetURL string) string {
@@ -100,3 +177,128 @@ Open this link to reset your password:
 This link will expire soon. If you did not request a password reset, you can ignore this email.
 `, s.appName, resetURL)
 }
+
+func (s *smtpEmailService) emailVerificationHTML(verifyURL string) string {
+	appName := html.EscapeString(s.appName)
+	escapedURL := html.EscapeString(verifyURL)
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #111827; line-height: 1.5;">
+  <h2>Verify your %s email address</h2>
+  <p>Thanks for signing up. Please confirm that this is your email address.</p>
+  <p>
+    <a href="%s" style="display: inline-block; padding: 10px 16px; background: #111827; color: #ffffff; text-decoration: none; border-radius: 6px;">
+      Verify email
+    </a>
+  </p>
+  <p>If the button does not work, copy and paste this link into your browser:</p>
+  <p><a href="%s">%s</a></p>
+  <p>If you did not create an account, you can ignore this email.</p>
+</body>
+</html>`, appName, escapedURL, escapedURL, escapedURL)
+}
+
+func (s *smtpEmailService) emailVerificationText(verifyURL string) string {
+	return fmt.Sprintf(`Verify your %s email address
+
+Thanks for signing up. Please confirm that this is your email address.
+
+Open this link to verify your email:
+%s
+
+If you did not create an account, you can ignore this email.
+`, s.appName, verifyURL)
+}
+
+func (s *smtpEmailService) magicLinkHTML(loginURL string) string {
+	appName := html.EscapeString(s.appName)
+	escapedURL := html.EscapeString(loginURL)
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #111827; line-height: 1.5;">
+  <h2>Sign in to %s</h2>
+  <p>Use the button below to sign in. The link works once.</p>
+  <p>
+    <a href="%s" style="display: inline-block; padding: 10px 16px; background: #111827; color: #ffffff; text-decoration: none; border-radius: 6px;">
+      Sign in
+    </a>
+  </p>
+  <p>If the button does not work, copy and paste this link into your browser:</p>
+  <p><a href="%s">%s</a></p>
+  <p>This link will expire soon. If you did not request it, you can ignore this email.</p>
+</body>
+</html>`, appName, escapedURL, escapedURL, escapedURL)
+}
+
+func (s *smtpEmailService) magicLinkText(loginURL string) string {
+	return fmt.Sprintf(`Sign in to %s
+
+Use the link below to sign in. The link works once.
+%s
+
+This link will expire soon. If you did not request it, you can ignore this email.
+`, s.appName, loginURL)
+}
+
+func (s *smtpEmailService) organizationInvitationHTML(organizationName, acceptURL string) string {
+	appName := html.EscapeString(s.appName)
+	organization := html.EscapeString(organizationName)
+	escapedURL := html.EscapeString(acceptURL)
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #111827; line-height: 1.5;">
+  <h2>Join %s on %s</h2>
+  <p>You have been invited to join the %s organization. The invitation works once.</p>
+  <p>
+    <a href="%s" style="display: inline-block; padding: 10px 16px; background: #111827; color: #ffffff; text-decoration: none; border-radius: 6px;">
+      Accept invitation
+    </a>
+  </p>
+  <p>If the button does not work, copy and paste this link into your browser:</p>
+  <p><a href="%s">%s</a></p>
+  <p>This invitation will expire. If you were not expecting it, you can ignore this email.</p>
+</body>
+</html>`, organization, appName, organization, escapedURL, escapedURL, escapedURL)
+}
+
+func (s *smtpEmailService) organizationInvitationText(organizationName, acceptURL string) string {
+	return fmt.Sprintf(`Join %s on %s
+
+You have been invited to join the %s organization. The invitation works once.
+
+Open this link to accept the invitation:
+%s
+
+This invitation will expire. If you were not expecting it, you can ignore this email.
+`, organizationName, s.appName, organizationName, acceptURL)
+}
+
+func (s *smtpEmailService) resourceSharedHTML(resourceName, level, resourceURL string) string {
+	appName := html.EscapeString(s.appName)
+	name := html.EscapeString(resourceName)
+	escapedURL := html.EscapeString(resourceURL)
+	return fmt.Sprintf(`<!doctype html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #111827; line-height: 1.5;">
+  <h2>%s was shared with you</h2>
+  <p>You now have %s access to %s on %s.</p>
+  <p>
+    <a href="%s" style="display: inline-block; padding: 10px 16px; background: #111827; color: #ffffff; text-decoration: none; border-radius: 6px;">
+      Open
+    </a>
+  </p>
+  <p>If the button does not work, copy and paste this link into your browser:</p>
+  <p><a href="%s">%s</a></p>
+</body>
+</html>`, name, html.EscapeString(level), name, appName, escapedURL, escapedURL, escapedURL)
+}
+
+func (s *smtpEmailService) resourceSharedText(resourceName, level, resourceURL string) string {
+	return fmt.Sprintf(`%s was shared with you
+
+You now have %s access to %s on %s.
+
+Open it here:
+%s
+`, resourceName, level, resourceName, s.appName, resourceURL)
+}
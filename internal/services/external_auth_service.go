@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExternalUserNotFound    = errors.New("no local account for external identity")
+	ErrExternalEmailUnverified = errors.New("external identity email is not verified")
+)
+
+// externalPrincipalCacheTTL bounds how long role changes made outside the
+// identity provider take to reach cached principals
+const externalPrincipalCacheTTL = 5 * time.Minute
+
+// ExternalPrincipal is the local user an external token maps to
+type ExternalPrincipal struct {
+	UserID        uint     `json:"user_id"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Roles         []string `json:"roles"`
+	// TokenVersion is the user's token version when the principal was
+	// resolved; deactivation, deletion and password changes bump it
+	TokenVersion int `json:"token_version"`
+	// Provisioned is set when the provider's role claim manages the roles
+	Provisioned bool `json:"provisioned"`
+	// RoleClaim is the normalized role claim the roles were last synced from
+	RoleClaim []string `json:"role_claim,omitempty"`
+}
+
+type ExternalAuthOptions struct {
+	AutoProvision bool
+	DefaultRole   string
+}
+
+type ExternalAuthService interface {
+	ResolvePrincipal(ctx context.Context, identity *jwt.ExternalIdentity) (*ExternalPrincipal, error)
+}
+
+type externalAuthService struct {
+	db            *gorm.DB
+	cache         *cache.Client
+	roleService   RoleService
+	tokenVersions TokenVersionService
+	opts          ExternalAuthOptions
+}
+
+func NewExternalAuthService(db *gorm.DB, cacheClient *cache.Client, roleService RoleService, tokenVersions TokenVersionService, opts ExternalAuthOptions) ExternalAuthService {
+	return &externalAuthService{db: db, cache: cacheClient, roleService: roleService, tokenVersions: tokenVersions, opts: opts}
+}
+
+// ResolvePrincipal maps a verified external identity onto the local user
+// linked to its issuer and subject. An identity seen for the first time is
+// linked to the user with its email only when the provider verified that
+// email, or provisions a user when enabled. For provisioned users the roles
+// claimed by the identity provider replace the local roles, so that
+// permissions follow the provider; roles unknown locally are ignored.
+func (s *externalAuthService) ResolvePrincipal(ctx context.Context, identity *jwt.ExternalIdentity) (*ExternalPrincipal, error) {
+	cacheKey := "external_user:" + identity.Issuer + ":" + identity.Subject
+	var principal ExternalPrincipal
+	cached := s.cache.GetJSON(ctx, cacheKey, &principal)
+	if cached {
+		// A bumped token version means the user was deactivated, deleted or
+		// changed their password since the principal was cached
+		if err := s.tokenVersions.Check(ctx, principal.UserID, principal.TokenVersion); err != nil {
+			if !errors.Is(err, ErrTokenVersionMismatch) {
+				return nil, err
+			}
+			s.cache.Delete(ctx, cacheKey)
+			cached = false
+		}
+	}
+	if !cached {
+		link, user, err := s.findOrProvision(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsActive {
+			return nil, ErrInactiveAccount
+		}
+		roles, err := loadRoleNames(s.db.WithContext(ctx), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		principal = ExternalPrincipal{
+			UserID:        user.ID,
+			Email:         user.Email,
+			EmailVerified: user.IsEmailVerified(),
+			Roles:         roles,
+			TokenVersion:  user.TokenVersion,
+			Provisioned:   link.Provisioned,
+		}
+		s.cache.SetJSON(ctx, cacheKey, principal, externalPrincipalCacheTTL)
+	}
+
+	if claimed := normalizeRoleNames(identity.Roles); principal.Provisioned && len(claimed) > 0 && !slices.Equal(principal.RoleClaim, claimed) {
+		if err := s.syncRoles(ctx, &principal, claimed); err != nil {
+			return nil, err
+		}
+		s.cache.SetJSON(ctx, cacheKey, principal, externalPrincipalCacheTTL)
+	}
+	if identity.EmailVerified {
+		principal.EmailVerified = true
+	}
+	return &principal, nil
+}
+
+// findOrProvision returns the identity linked to the issuer and subject with
+// its user, linking or provisioning one on first sight
+func (s *externalAuthService) findOrProvision(ctx context.Context, identity *jwt.ExternalIdentity) (*models.UserIdentity, *models.User, error) {
+	db := s.db.WithContext(ctx)
+	var link models.UserIdentity
+	err := db.Where("provider = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, link.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, ErrExternalUserNotFound
+			}
+			return nil, nil, err
+		}
+		return &link, &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if identity.Email == "" {
+		return nil, nil, ErrExternalUserNotFound
+	}
+
+	now := time.Now()
+	link = models.UserIdentity{
+		Provider:    identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	}
+	var user models.User
+	err = db.Where("email = ?", identity.Email).First(&user).Error
+	if err == nil {
+		// Anyone can claim an unverified address at some providers, so it
+		// must not open an existing account
+		if !identity.EmailVerified {
+			return nil, nil, ErrExternalEmailUnverified
+		}
+		link.UserID = user.ID
+		if err := db.Create(&link).Error; err != nil {
+			return nil, nil, err
+		}
+		utils.Log("Security").Info("External identity linked by verified email", "user_id", user.ID, "issuer", identity.Issuer)
+		return &link, &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if !s.opts.AutoProvision {
+		return nil, nil, ErrExternalUserNotFound
+	}
+
+	user = models.User{
+		Email:               identity.Email,
+		PasswordIsSetByUser: false,
+		IsActive:            true,
+	}
+	if identity.EmailVerified {
+		user.EmailVerifiedAt = &now
+	}
+	link.Provisioned = true
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := assignRoles(tx, user.ID, s.opts.DefaultRole); err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserProfile{UserID: user.ID, FirstName: externalFirstName(identity)}).Error; err != nil {
+			return err
+		}
+		link.UserID = user.ID
+		return tx.Create(&link).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	utils.Log("Auth").Info("Provisioned user from external identity", "user_id", user.ID, "issuer", identity.Issuer)
+	return &link, &user, nil
+}
+
+// syncRoles stores the claimed roles that exist locally as the principal's
+// roles. The local roles are kept when none of the claimed roles is known.
+func (s *externalAuthService) syncRoles(ctx context.Context, principal *ExternalPrincipal, claimed []string) error {
+	var known []string
+	if err := s.db.WithContext(ctx).Model(&models.Role{}).Where("name IN ?", claimed).Order("name").Pluck("name", &known).Error; err != nil {
+		return err
+	}
+	if len(known) < len(claimed) {
+		utils.LogCtx(ctx, "Auth").Debug("Ignoring unknown roles from identity provider", "user_id", principal.UserID, "claimed", claimed, "known", known)
+	}
+	principal.RoleClaim = claimed
+	if len(known) == 0 || slices.Equal(principal.Roles, known) {
+		return nil
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.roleService.SetUserRoles(tx, principal.UserID, known)
+	})
+	if err != nil {
+		return err
+	}
+	s.roleService.Invalidate(ctx, principal.UserID)
+	principal.Roles = known
+	utils.LogCtx(ctx, "Security").Info("User roles synced from identity provider", "user_id", principal.UserID, "roles", known)
+	return nil
+}
+
+func externalFirstName(identity *jwt.ExternalIdentity) string {
+	if given, _ := identity.Claims["given_name"].(string); given != "" {
+		return given
+	}
+	if identity.Name != "" {
+		return identity.Name
+	}
+	local, _, _ := strings.Cut(identity.Email, "@")
+	return local
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/jwt"
+	"gorm.io/gorm"
+)
+
+const testIssuer = "https://idp.example.com"
+
+// newExternalAuthService returns the service on db, provisioning unknown
+// users with the user role when autoProvision is set
+func newExternalAuthService(db *gorm.DB, autoProvision bool) ExternalAuthService {
+	return NewExternalAuthService(db, nil, NewRoleService(db, nil), NewTokenVersionService(db, nil), ExternalAuthOptions{
+		AutoProvision: autoProvision,
+		DefaultRole:   "user",
+	})
+}
+
+func TestExternalAuthLinksExistingUserOnlyByVerifiedEmail(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := newExternalAuthService(db, false)
+	ctx := context.Background()
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	_, err := service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "attacker", Email: "jane@example.com"})
+	testutil.AssertTrue(t, errors.Is(err, ErrExternalEmailUnverified), "unverified email must not link: %v", err)
+
+	principal, err := service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "jane", Email: "jane@example.com", EmailVerified: true})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, user.ID, principal.UserID)
+
+	var link models.UserIdentity
+	testutil.AssertNoError(t, db.Where("provider = ? AND subject = ?", testIssuer, "jane").First(&link).Error)
+	testutil.AssertEqual(t, user.ID, link.UserID)
+	testutil.AssertFalse(t, link.Provisioned)
+
+	// Later tokens match on the subject, whatever their email claim says
+	principal, err = service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "jane", Email: "renamed@example.com"})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, user.ID, principal.UserID)
+
+	// Another issuer with the same subject is a different identity
+	_, err = service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: "https://other.example.com", Subject: "jane", Email: "nobody@example.com", EmailVerified: true})
+	testutil.AssertTrue(t, errors.Is(err, ErrExternalUserNotFound), "unknown identity: %v", err)
+}
+
+func TestExternalAuthRoleClaimOnlyManagesProvisionedUsers(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := newExternalAuthService(db, true)
+	ctx := context.Background()
+	testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+
+	linked, err := service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "jane", Email: "jane@example.com", EmailVerified: true, Roles: []string{"admin"}})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"user"}, linked.Roles)
+	roles, err := loadRoleNames(db, linked.UserID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"user"}, roles)
+
+	provisioned, err := service.ResolvePrincipal(ctx, &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "new", Email: "new@example.com", EmailVerified: true, Roles: []string{"Admin"}})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, linked.UserID, provisioned.UserID)
+	testutil.AssertEqual(t, []string{"admin"}, provisioned.Roles)
+	roles, err = loadRoleNames(db, provisioned.UserID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"admin"}, roles)
+}
+
+func TestExternalAuthSyncsEveryKnownClaimedRole(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	testutil.CreateRoleFixture(db, "editor")
+	service := newExternalAuthService(db, true)
+	ctx := context.Background()
+	identity := &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "new", Email: "new@example.com", EmailVerified: true,
+		Roles: []string{"offline_access", "Editor", "admin"}}
+
+	principal, err := service.ResolvePrincipal(ctx, identity)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"admin", "editor"}, principal.Roles)
+	roles, err := loadRoleNames(db, principal.UserID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"admin", "editor"}, roles)
+
+	// Dropping a role at the provider drops it locally
+	identity.Roles = []string{"editor"}
+	principal, err = service.ResolvePrincipal(ctx, identity)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"editor"}, principal.Roles)
+
+	// A claim without any known role leaves the local roles alone
+	identity.Roles = []string{"offline_access"}
+	principal, err = service.ResolvePrincipal(ctx, identity)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"editor"}, principal.Roles)
+	roles, err = loadRoleNames(db, principal.UserID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"editor"}, roles)
+}
+
+func TestExternalAuthRejectsInactiveAndDeletedUsers(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := newExternalAuthService(db, true)
+	ctx := context.Background()
+	identity := &jwt.ExternalIdentity{Issuer: testIssuer, Subject: "jane", Email: "jane@example.com", EmailVerified: true}
+
+	principal, err := service.ResolvePrincipal(ctx, identity)
+	testutil.AssertNoError(t, err)
+
+	db.Model(&models.User{}).Where("id = ?", principal.UserID).Update("is_active", false)
+	_, err = service.ResolvePrincipal(ctx, identity)
+	testutil.AssertTrue(t, errors.Is(err, ErrInactiveAccount), "inactive user: %v", err)
+
+	db.Model(&models.User{}).Where("id = ?", principal.UserID).Update("is_active", true)
+	db.Delete(&models.User{}, principal.UserID)
+	_, err = service.ResolvePrincipal(ctx, identity)
+	testutil.AssertTrue(t, errors.Is(err, ErrExternalUserNotFound), "deleted user: %v", err)
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/tenant"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// invitationRetention is how long accepted, revoked and expired invitations
+// stay listed before PurgeExpired deletes them
+const invitationRetention = 7 * 24 * time.Hour
+
+var (
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvalidInvitation       = errors.New("invalid or expired invitation")
+	ErrInvitationPending       = errors.New("an invitation for this email is already pending")
+	ErrInvitationClosed        = errors.New("invitation has already been accepted or revoked")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email address")
+	ErrInvitationEmailDisabled = errors.New("invitation email service is not configured")
+	ErrInvitationEmailFailed   = errors.New("invitation email could not be sent")
+)
+
+// InvitationService invites people into an organization by email. Owners and
+// admins create, resend and revoke invitations; the invitee accepts with the
+// emailed token, either signed in or by registering with the invited address.
+type InvitationService interface {
+	Create(actorID, organizationID uint, req *dto.CreateInvitationRequest) (*dto.InvitationResponse, error)
+	List(organizationID uint) ([]dto.InvitationResponse, error)
+	Resend(actorID, organizationID, invitationID uint) (*dto.InvitationResponse, error)
+	Revoke(actorID, organizationID, invitationID uint) error
+	Lookup(token string) (*dto.InvitationPreviewResponse, error)
+	Accept(userID uint, token string) (*dto.OrganizationResponse, error)
+	Register(req *dto.RegisterInvitationRequest) (*models.User, error)
+	PurgeExpired() (int64, error)
+}
+
+type invitationService struct {
+	db                  *gorm.DB
+	emailService        EmailService
+	organizationService OrganizationService
+	authService         AuthService
+}
+
+func NewInvitationService(db *gorm.DB, emailService EmailService, organizationService OrganizationService, authService AuthService) InvitationService {
+	return &invitationService{
+		db:                  db,
+		emailService:        emailService,
+		organizationService: organizationService,
+		authService:         authService,
+	}
+}
+
+// Create invites an email address that is not yet a member. Only owners can
+// invite owners, and an address has at most one pending invitation. The email
+// is sent once the invitation is stored; if sending fails the invitation is
+// revoked, since nobody holds its token, and ErrInvitationEmailFailed is
+// returned.
+func (s *invitationService) Create(actorID, organizationID uint, req *dto.CreateInvitationRequest) (*dto.InvitationResponse, error) {
+	if s.emailService == nil || !s.emailService.Enabled() {
+		return nil, ErrInvitationEmailDisabled
+	}
+	role := req.Role
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if err := s.authorize(actorID, organizationID, role); err != nil {
+		return nil, err
+	}
+	organization, err := s.findOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	var members int64
+	if err := s.db.Model(&models.OrganizationMember{}).
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ? AND users.email = ?", organizationID, email).
+		Count(&members).Error; err != nil {
+		return nil, err
+	}
+	if members > 0 {
+		return nil, ErrAlreadyMember
+	}
+
+	ttl := config.AppConfig.InvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	token := utils.RandomString(32)
+	invitation := &models.OrganizationInvitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      hashToken(token),
+		InvitedByID:    &actorID,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	err = s.scoped(organizationID).Transaction(func(tx *gorm.DB) error {
+		var pending int64
+		if err := tx.Model(&models.OrganizationInvitation{}).
+			Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrInvitationPending
+		}
+		return tx.Create(invitation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emailService.SendOrganizationInvitation(email, organization.Name, token); err != nil {
+		utils.Log("Invitation").Error("Failed to send invitation", "organization_id", organizationID, "invitation_id", invitation.ID, "error", err)
+		now := time.Now()
+		if err := s.scoped(organizationID).Model(invitation).
+			Updates(map[string]interface{}{"revoked_at": now, "updated_at": now}).Error; err != nil {
+			utils.Log("Invitation").Error("Failed to revoke unsent invitation", "organization_id", organizationID, "invitation_id", invitation.ID, "error", err)
+		}
+		return nil, ErrInvitationEmailFailed
+	}
+	utils.Log("Invitation").Info("Invitation created", "organization_id", organizationID, "invitation_id", invitation.ID, "actor_id", actorID, "role", role)
+	return toInvitationResponse(invitation), nil
+}
+
+// List returns the organization's invitations, newest first
+func (s *invitationService) List(organizationID uint) ([]dto.InvitationResponse, error) {
+	var invitations []models.OrganizationInvitation
+	if err := s.scoped(organizationID).Order("created_at DESC, id DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		out = append(out, *toInvitationResponse(&invitations[i]))
+	}
+	return out, nil
+}
+
+// Resend emails a fresh token and restarts the expiry. The previous token
+// stops working. Expired invitations can be resent; closed ones cannot. When
+// the email cannot be sent, ErrInvitationEmailFailed is returned and the
+// invitation stays pending, to be resent again.
+func (s *invitationService) Resend(actorID, organizationID, invitationID uint) (*dto.InvitationResponse, error) {
+	if s.emailService == nil || !s.emailService.Enabled() {
+		return nil, ErrInvitationEmailDisabled
+	}
+	invitation, err := s.find(organizationID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(actorID, organizationID, invitation.Role); err != nil {
+		return nil, err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, ErrInvitationClosed
+	}
+	organization, err := s.findOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	token := utils.RandomString(32)
+	expiresAt := time.Now().Add(config.AppConfig.InvitationTTL)
+	err = s.scoped(organizationID).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.OrganizationInvitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			Updates(map[string]interface{}{"token_hash": hashToken(token), "expires_at": expiresAt, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationClosed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emailService.SendOrganizationInvitation(invitation.Email, organization.Name, token); err != nil {
+		utils.Log("Invitation").Error("Failed to resend invitation", "organization_id", organizationID, "invitation_id", invitation.ID, "error", err)
+		return nil, ErrInvitationEmailFailed
+	}
+	invitation.ExpiresAt = expiresAt
+	utils.Log("Invitation").Info("Invitation resent", "organization_id", organizationID, "invitation_id", invitation.ID, "actor_id", actorID)
+	return toInvitationResponse(invitation), nil
+}
+
+// Revoke cancels an invitation that has not been accepted
+func (s *invitationService) Revoke(actorID, organizationID, invitationID uint) error {
+	invitation, err := s.find(organizationID, invitationID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(actorID, organizationID, invitation.Role); err != nil {
+		return err
+	}
+	if invitation.AcceptedAt != nil {
+		return ErrInvitationClosed
+	}
+	if invitation.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	if err := s.scoped(organizationID).Model(&models.OrganizationInvitation{}).
+		Where("id = ? AND accepted_at IS NULL", invitation.ID).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now}).Error; err != nil {
+		return err
+	}
+	utils.Log("Invitation").Info("Invitation revoked", "organization_id", organizationID, "invitation_id", invitation.ID, "actor_id", actorID)
+	return nil
+}
+
+// Lookup describes a pending invitation so the client can offer to sign in
+// or register
+func (s *invitationService) Lookup(token string) (*dto.InvitationPreviewResponse, error) {
+	invitation, err := s.findPending(token)
+	if err != nil {
+		return nil, err
+	}
+	var accounts int64
+	if err := s.db.Model(&models.User{}).Where("email = ?", invitation.Email).Count(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return &dto.InvitationPreviewResponse{
+		OrganizationName: invitation.Organization.Name,
+		OrganizationSlug: invitation.Organization.Slug,
+		Email:            invitation.Email,
+		Role:             invitation.Role,
+		ExpiresAt:        invitation.ExpiresAt,
+		AccountExists:    accounts > 0,
+	}, nil
+}
+
+// Accept adds the signed-in user to the organization. The invitation must
+// have been sent to the user's email address, which it also verifies.
+func (s *invitationService) Accept(userID uint, token string) (*dto.OrganizationResponse, error) {
+	invitation, err := s.findPending(token)
+	if err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := consumeInvitation(tx, invitation, user.ID); err != nil {
+			return err
+		}
+		if user.IsEmailVerified() {
+			return nil
+		}
+		now := time.Now()
+		return tx.Model(&models.User{}).Where("id = ? AND email_verified_at IS NULL", user.ID).
+			Updates(map[string]interface{}{"email_verified_at": now, "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Invitation").Info("Invitation accepted", "organization_id", invitation.OrganizationID, "invitation_id", invitation.ID, "user_id", user.ID)
+	return toOrganizationResponse(invitation.Organization, invitation.Role), nil
+}
+
+// Register creates an account for the invited address and accepts the
+// invitation in the same transaction. The email starts out verified.
+func (s *invitationService) Register(req *dto.RegisterInvitationRequest) (*models.User, error) {
+	invitation, err := s.findPending(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authService.RegisterInvited(&dto.RegisterRequest{
+		Email:     invitation.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}, func(tx *gorm.DB, user *models.User) error {
+		return consumeInvitation(tx, invitation, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Invitation").Info("Invitation accepted", "organization_id", invitation.OrganizationID, "invitation_id", invitation.ID, "user_id", user.ID, "registered", true)
+	return user, nil
+}
+
+// PurgeExpired deletes invitations that were accepted, revoked or expired
+// more than invitationRetention ago, across all organizations
+func (s *invitationService) PurgeExpired() (int64, error) {
+	cutoff := time.Now().Add(-invitationRetention)
+	result := s.db.WithContext(tenant.Bypass(context.Background())).
+		Where("accepted_at < ? OR revoked_at < ? OR expires_at < ?", cutoff, cutoff, cutoff).
+		Delete(&models.OrganizationInvitation{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *invitationService) authorize(actorID, organizationID uint, roles ...string) error {
+	actorRole, err := s.organizationService.Membership(context.Background(), organizationID, actorID)
+	if err != nil {
+		return err
+	}
+	return checkManage(actorRole, roles...)
+}
+
+// scoped returns a session scoped to the organization's tenant
+func (s *invitationService) scoped(organizationID uint) *gorm.DB {
+	return s.db.WithContext(tenant.WithOrganization(context.Background(), organizationID))
+}
+
+func (s *invitationService) find(organizationID, invitationID uint) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	if err := s.scoped(organizationID).First(&invitation, invitationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// findPending resolves a token to a pending invitation of an existing
+// organization. Tokens are not tied to a tenant, so the lookup bypasses scoping.
+func (s *invitationService) findPending(token string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	if err := s.db.WithContext(tenant.Bypass(context.Background())).Preload("Organization").
+		Where("token_hash = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).
+		First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if invitation.Organization == nil {
+		return nil, ErrInvalidInvitation
+	}
+	return &invitation, nil
+}
+
+func (s *invitationService) findOrganization(organizationID uint) (*models.Organization, error) {
+	var organization models.Organization
+	if err := s.db.First(&organization, organizationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// consumeInvitation marks the invitation accepted by the user and adds the
+// membership. The conditional update makes the token single-use even when
+// two requests race.
+func consumeInvitation(tx *gorm.DB, invitation *models.OrganizationInvitation, userID uint) error {
+	now := time.Now()
+	result := tx.WithContext(tenant.WithOrganization(context.Background(), invitation.OrganizationID)).
+		Model(&models.OrganizationInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", invitation.ID, now).
+		Updates(map[string]interface{}{"accepted_at": now, "accepted_by_id": userID, "updated_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidInvitation
+	}
+	var members int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, userID).
+		Count(&members).Error; err != nil {
+		return err
+	}
+	if members > 0 {
+		return ErrAlreadyMember
+	}
+	return tx.Create(&models.OrganizationMember{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           invitation.Role,
+	}).Error
+}
+
+func invitationStatus(invitation *models.OrganizationInvitation) string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return "accepted"
+	case invitation.RevokedAt != nil:
+		return "revoked"
+	case !time.Now().Before(invitation.ExpiresAt):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+func toInvitationResponse(invitation *models.OrganizationInvitation) *dto.InvitationResponse {
+	return &dto.InvitationResponse{
+		ID:          invitation.ID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		Status:      invitationStatus(invitation),
+		InvitedByID: invitation.InvitedByID,
+		ExpiresAt:   invitation.ExpiresAt,
+		AcceptedAt:  invitation.AcceptedAt,
+		RevokedAt:   invitation.RevokedAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
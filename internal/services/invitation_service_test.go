@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/tenant"
+)
+
+// invitationMailer records the invitation tokens it sends, or fails while err
+// is set
+type invitationMailer struct {
+	noopEmailService
+	tokens []string
+	err    error
+}
+
+func (m *invitationMailer) Enabled() bool {
+	return true
+}
+
+func (m *invitationMailer) SendOrganizationInvitation(_, _, token string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+// inviteAs returns a function inviting an email address into organizationID
+// on behalf of ownerID
+func inviteAs(service InvitationService, ownerID, organizationID uint) func(email string) (*dto.InvitationResponse, error) {
+	return func(email string) (*dto.InvitationResponse, error) {
+		return service.Create(ownerID, organizationID, &dto.CreateInvitationRequest{Email: email})
+	}
+}
+
+func TestInvitationCreateRevokesWhenEmailFails(t *testing.T) {
+	config.AppConfig = &config.Config{InvitationTTL: time.Hour}
+	db := testutil.NewTestDB(t)
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	mailer := &invitationMailer{}
+	service := NewInvitationService(db, mailer, NewOrganizationService(db, nil, nil), nil)
+	invite := inviteAs(service, owner.ID, org.ID)
+	mailer.err = errors.New("smtp unavailable")
+
+	_, err := invite("jane@example.com")
+	testutil.AssertTrue(t, errors.Is(err, ErrInvitationEmailFailed), "failed send: %v", err)
+	var invitation models.OrganizationInvitation
+	testutil.AssertNoError(t, db.WithContext(tenant.Bypass(context.Background())).
+		Where("email = ?", "jane@example.com").First(&invitation).Error)
+	testutil.AssertNotNil(t, invitation.RevokedAt, "unsent invitation must be revoked")
+
+	// The revoked invitation does not block a retry
+	mailer.err = nil
+	_, err = invite("jane@example.com")
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, mailer.tokens, 1)
+}
+
+func TestInvitationResendReportsEmailFailure(t *testing.T) {
+	config.AppConfig = &config.Config{InvitationTTL: time.Hour}
+	db := testutil.NewTestDB(t)
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	mailer := &invitationMailer{}
+	service := NewInvitationService(db, mailer, NewOrganizationService(db, nil, nil), nil)
+	invite := inviteAs(service, owner.ID, org.ID)
+	invitation, err := invite("jane@example.com")
+	testutil.AssertNoError(t, err)
+
+	mailer.err = errors.New("smtp unavailable")
+	_, err = service.Resend(owner.ID, org.ID, invitation.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvitationEmailFailed), "failed resend: %v", err)
+
+	mailer.err = nil
+	_, err = service.Resend(owner.ID, org.ID, invitation.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, mailer.tokens, 2)
+
+	// Only the token of the last resend works
+	_, err = service.Lookup(mailer.tokens[0])
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidInvitation), "superseded token: %v", err)
+	_, err = service.Lookup(mailer.tokens[1])
+	testutil.AssertNoError(t, err)
+}
+
+func TestInvitationTokenIsSingleUse(t *testing.T) {
+	config.AppConfig = &config.Config{InvitationTTL: time.Hour}
+	db := testutil.NewTestDB(t)
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	mailer := &invitationMailer{}
+	service := NewInvitationService(db, mailer, NewOrganizationService(db, nil, nil), nil)
+	invite := inviteAs(service, owner.ID, org.ID)
+	_, err := invite("jane@example.com")
+	testutil.AssertNoError(t, err)
+	jane := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	other := testutil.CreateUserFixture(db, "Other", "other@example.com", "password123", "user")
+	token := mailer.tokens[0]
+
+	_, err = service.Accept(other.ID, token)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvitationEmailMismatch), "other address: %v", err)
+
+	organization, err := service.Accept(jane.ID, token)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, models.OrganizationRoleMember, organization.Role)
+	var verified models.User
+	testutil.AssertNoError(t, db.First(&verified, jane.ID).Error)
+	testutil.AssertTrue(t, verified.IsEmailVerified(), "accepting verifies the email")
+
+	_, err = service.Accept(jane.ID, token)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidInvitation), "second accept: %v", err)
+	_, err = service.Lookup(token)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidInvitation), "lookup after accept: %v", err)
+}
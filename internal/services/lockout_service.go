@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var ErrAccountLocked = errors.New("account temporarily locked after too many failed login attempts")
+
+// AccountLockedError is returned while an account is locked or backing off.
+// It matches ErrAccountLocked with errors.Is.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// RetryAfterSeconds rounds the wait up to whole seconds for the Retry-After header
+func (e *AccountLockedError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+type LockoutOptions struct {
+	// Threshold is the number of consecutive failures that lock the account
+	// for Duration; zero disables the lockout
+	Threshold int
+	// Duration is both the lockout length and the window after which an idle
+	// failure counter resets
+	Duration time.Duration
+	// BackoffAfter failures are allowed without delay; each further failure
+	// doubles the wait starting at BackoffBase. A zero base disables backoff.
+	BackoffAfter int
+	BackoffBase  time.Duration
+}
+
+// LockoutService tracks failed logins per account. Counters live in Redis when
+// the cache is enabled and in the users table otherwise.
+type LockoutService interface {
+	Check(user *models.User) error
+	RecordFailure(userID uint)
+	Reset(userID uint)
+	Unlock(userID, adminID uint) error
+}
+
+type lockoutService struct {
+	db    *gorm.DB
+	cache *cache.Client
+	opts  LockoutOptions
+}
+
+func NewLockoutService(db *gorm.DB, cacheClient *cache.Client, opts LockoutOptions) LockoutService {
+	return &lockoutService{db: db, cache: cacheClient, opts: opts}
+}
+
+// Check returns an AccountLockedError while the user has to wait before the
+// next attempt.
+func (s *lockoutService) Check(user *models.User) error {
+	var until time.Time
+	if s.cache.Enabled() {
+		var unix int64
+		if !s.cache.GetJSON(context.Background(), lockKey(user.ID), &unix) {
+			return nil
+		}
+		until = time.Unix(unix, 0)
+	} else if user.LockedUntil != nil {
+		until = *user.LockedUntil
+	}
+
+	if wait := time.Until(until); wait > 0 {
+		utils.Log("Security").Warn("Login attempt on locked account", "user_id", user.ID, "retry_after", wait.Round(time.Second).String())
+		return &AccountLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed password or second-factor attempt and locks
+// the account when the backoff or lockout threshold is reached.
+func (s *lockoutService) RecordFailure(userID uint) {
+	if !s.enabled() {
+		return
+	}
+	attempts, err := s.incrementFailures(userID)
+	if err != nil {
+		utils.Log("Security").Error("Failed to record failed login", "user_id", userID, "error", err)
+		return
+	}
+
+	delay := s.delay(attempts)
+	utils.Log("Security").Warn("Failed login attempt", "user_id", userID, "attempts", attempts)
+	if delay <= 0 {
+		return
+	}
+	until := time.Now().Add(delay)
+	if s.cache.Enabled() {
+		s.cache.SetJSON(context.Background(), lockKey(userID), until.Unix(), delay)
+	} else if err := s.db.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("locked_until", until).Error; err != nil {
+		utils.Log("Security").Error("Failed to lock account", "user_id", userID, "error", err)
+		return
+	}
+	if s.opts.Threshold > 0 && attempts >= int64(s.opts.Threshold) {
+		utils.Log("Security").Warn("Account locked", "user_id", userID, "attempts", attempts, "locked_until", until)
+	}
+}
+
+// Reset clears the failure counter after a successful login
+func (s *lockoutService) Reset(userID uint) {
+	if !s.enabled() {
+		return
+	}
+	if s.cache.Enabled() {
+		s.cache.Delete(context.Background(), failuresKey(userID), lockKey(userID))
+		return
+	}
+	if err := s.clearColumns(userID, "failed_login_attempts > 0 OR locked_until IS NOT NULL"); err != nil {
+		utils.Log("Security").Error("Failed to reset failed logins", "user_id", userID, "error", err)
+	}
+}
+
+// Unlock lifts a lockout and resets the counter on behalf of an administrator
+func (s *lockoutService) Unlock(userID, adminID uint) error {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+
+	s.cache.Delete(context.Background(), failuresKey(userID), lockKey(userID))
+	if err := s.clearColumns(userID, ""); err != nil {
+		return err
+	}
+	utils.Log("Security").Info("Account unlocked", "user_id", userID, "admin_id", adminID)
+	return nil
+}
+
+func (s *lockoutService) enabled() bool {
+	return s.opts.Threshold > 0 || s.opts.BackoffBase > 0
+}
+
+func (s *lockoutService) incrementFailures(userID uint) (int64, error) {
+	if s.cache.Enabled() {
+		return s.cache.Incr(context.Background(), failuresKey(userID), s.opts.Duration)
+	}
+
+	now := time.Now()
+	var attempts int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE failed_login_attempts + 1 END", now.Add(-s.opts.Duration)),
+			"last_failed_login_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Select("failed_login_attempts").Scan(&attempts).Error
+	})
+	return attempts, err
+}
+
+// delay returns how long the account waits after the given number of
+// consecutive failures
+func (s *lockoutService) delay(attempts int64) time.Duration {
+	if s.opts.Threshold > 0 && attempts >= int64(s.opts.Threshold) {
+		return s.opts.Duration
+	}
+	over := attempts - int64(s.opts.BackoffAfter)
+	if s.opts.BackoffBase <= 0 || over <= 0 {
+		return 0
+	}
+	if over > 20 {
+		over = 20
+	}
+	delay := s.opts.BackoffBase << (over - 1)
+	if s.opts.Duration > 0 && delay > s.opts.Duration {
+		delay = s.opts.Duration
+	}
+	return delay
+}
+
+func (s *lockoutService) clearColumns(userID uint, condition string) error {
+	query := s.db.Model(&models.User{}).Where("id = ?", userID)
+	if condition != "" {
+		query = query.Where(condition)
+	}
+	return query.UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"last_failed_login_at":  nil,
+		"locked_until":          nil,
+	}).Error
+}
+
+func failuresKey(userID uint) string {
+	return "login:failures:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func lockKey(userID uint) string {
+	return "login:locked:" + strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"gorm.io/gorm"
+)
+
+var testLockoutOptions = LockoutOptions{
+	Threshold:    5,
+	Duration:     15 * time.Minute,
+	BackoffAfter: 2,
+	BackoffBase:  time.Second,
+}
+
+// retryAfter checks the stored user and returns how long it has to wait
+func retryAfter(t *testing.T, db *gorm.DB, service LockoutService, userID uint) time.Duration {
+	t.Helper()
+	var user models.User
+	testutil.AssertNoError(t, db.First(&user, userID).Error)
+	err := service.Check(&user)
+	if err == nil {
+		return 0
+	}
+	var locked *AccountLockedError
+	testutil.AssertTrue(t, errors.As(err, &locked), "Check() error = %v", err)
+	testutil.AssertTrue(t, errors.Is(err, ErrAccountLocked), "AccountLockedError must match ErrAccountLocked")
+	return locked.RetryAfter
+}
+
+func TestLockoutDelay(t *testing.T) {
+	service := &lockoutService{opts: testLockoutOptions}
+	for attempts, want := range []time.Duration{0, 0, 0, time.Second, 2 * time.Second, 15 * time.Minute, 15 * time.Minute} {
+		testutil.AssertEqual(t, want, service.delay(int64(attempts)), "delay after %d failures", attempts)
+	}
+
+	// Without a threshold the backoff keeps doubling, capped at Duration
+	service.opts.Threshold = 0
+	testutil.AssertEqual(t, 8*time.Second, service.delay(6))
+	testutil.AssertEqual(t, 15*time.Minute, service.delay(100))
+}
+
+func TestLockoutBacksOffThenLocks(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewLockoutService(db, nil, testLockoutOptions)
+	userID := testutil.CreateStandardUserFixture(db).ID
+
+	service.RecordFailure(userID)
+	service.RecordFailure(userID)
+	testutil.AssertEqual(t, time.Duration(0), retryAfter(t, db, service, userID))
+
+	service.RecordFailure(userID)
+	wait := retryAfter(t, db, service, userID)
+	testutil.AssertTrue(t, wait > 0 && wait <= time.Second, "first backoff = %v", wait)
+
+	service.RecordFailure(userID)
+	service.RecordFailure(userID)
+	wait = retryAfter(t, db, service, userID)
+	testutil.AssertTrue(t, wait > 14*time.Minute, "lockout = %v", wait)
+
+	service.Reset(userID)
+	testutil.AssertEqual(t, time.Duration(0), retryAfter(t, db, service, userID))
+	var user models.User
+	testutil.AssertNoError(t, db.First(&user, userID).Error)
+	testutil.AssertEqual(t, 0, user.FailedLoginAttempts)
+}
+
+func TestLockoutUnlock(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewLockoutService(db, nil, testLockoutOptions)
+	userID := testutil.CreateStandardUserFixture(db).ID
+	for i := 0; i < testLockoutOptions.Threshold; i++ {
+		service.RecordFailure(userID)
+	}
+	testutil.AssertTrue(t, retryAfter(t, db, service, userID) > 0, "account should be locked")
+
+	testutil.AssertNoError(t, service.Unlock(userID, 1))
+	testutil.AssertEqual(t, time.Duration(0), retryAfter(t, db, service, userID))
+	testutil.AssertTrue(t, errors.Is(service.Unlock(9999, 1), ErrUserNotFound), "unknown user")
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/totp"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFANotConfigured  = errors.New("two-factor authentication is not configured")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+)
+
+type MFAService interface {
+	Status(userID uint) (*dto.MFAStatusResponse, error)
+	BeginEnrollment(userID uint) (*dto.MFAEnrollResponse, error)
+	ConfirmEnrollment(userID uint, code string) (*dto.MFARecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(userID uint, code string) (*dto.MFARecoveryCodesResponse, error)
+	Disable(userID uint, code string) error
+	IsEnabled(userID uint) (bool, error)
+	VerifyCode(userID uint, code string) error
+}
+
+type mfaService struct {
+	db     *gorm.DB
+	cipher *utils.SecretCipher
+	issuer string
+}
+
+// NewMFAService creates the TOTP service. A nil cipher disables enrollment.
+func NewMFAService(db *gorm.DB, cipher *utils.SecretCipher, issuer string) MFAService {
+	return &mfaService{db: db, cipher: cipher, issuer: issuer}
+}
+
+func (s *mfaService) Status(userID uint) (*dto.MFAStatusResponse, error) {
+	record, err := s.find(userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnabled) {
+			return &dto.MFAStatusResponse{}, nil
+		}
+		return nil, err
+	}
+	if !record.IsEnabled() {
+		return &dto.MFAStatusResponse{}, nil
+	}
+	var remaining int64
+	if err := s.db.Model(&models.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+		return nil, err
+	}
+	return &dto.MFAStatusResponse{
+		Enabled:                true,
+		EnabledAt:              record.EnabledAt,
+		RecoveryCodesRemaining: int(remaining),
+	}, nil
+}
+
+// BeginEnrollment creates a pending TOTP secret. It replaces any earlier
+// unconfirmed enrollment and only becomes active after ConfirmEnrollment.
+func (s *mfaService) BeginEnrollment(userID uint) (*dto.MFAEnrollResponse, error) {
+	if s.cipher == nil {
+		return nil, ErrMFANotConfigured
+	}
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	record, err := s.find(userID)
+	if err != nil && !errors.Is(err, ErrMFANotEnabled) {
+		return nil, err
+	}
+	if record != nil && record.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		record = &models.UserMFA{UserID: userID}
+	}
+	record.SecretEncrypted = encrypted
+	record.LastUsedStep = 0
+	if err := s.db.Save(record).Error; err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: totp.KeyURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment activates a pending enrollment with a first valid code and
+// returns a fresh set of recovery codes.
+func (s *mfaService) ConfirmEnrollment(userID uint, code string) (*dto.MFARecoveryCodesResponse, error) {
+	if s.cipher == nil {
+		return nil, ErrMFANotConfigured
+	}
+	record, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	step, err := s.checkTOTP(record, normalizeMFACode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(record).Updates(map[string]interface{}{
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("Two-factor authentication enabled", "user_id", userID)
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID uint, code string) (*dto.MFARecoveryCodesResponse, error) {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("Recovery codes regenerated", "user_id", userID)
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *mfaService) Disable(userID uint, code string) error {
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	utils.Log("Security").Warn("Two-factor authentication disabled", "user_id", userID)
+	return nil
+}
+
+func (s *mfaService) IsEnabled(userID uint) (bool, error) {
+	record, err := s.find(userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnabled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return record.IsEnabled(), nil
+}
+
+// VerifyCode accepts either a current TOTP code or an unused recovery code.
+// Recovery codes are consumed on use.
+func (s *mfaService) VerifyCode(userID uint, code string) error {
+	record, err := s.find(userID)
+	if err != nil {
+		return err
+	}
+	if !record.IsEnabled() {
+		return ErrMFANotEnabled
+	}
+
+	code = normalizeMFACode(code)
+	if len(code) == totp.Digits {
+		step, err := s.checkTOTP(record, code)
+		if err != nil {
+			return err
+		}
+		// Conditional update so a code cannot be replayed, even concurrently
+		result := s.db.Model(&models.UserMFA{}).
+			Where("id = ? AND last_used_step < ?", record.ID, step).
+			Update("last_used_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	result := s.db.Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	utils.Log("Security").Info("Recovery code used", "user_id", userID)
+	return nil
+}
+
+func (s *mfaService) find(userID uint) (*models.UserMFA, error) {
+	var record models.UserMFA
+	if err := s.db.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFANotEnabled
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *mfaService) checkTOTP(record *models.UserMFA, code string) (int64, error) {
+	if s.cipher == nil {
+		return 0, ErrMFANotConfigured
+	}
+	secret, err := s.cipher.Decrypt(record.SecretEncrypted)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || step <= record.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// replaceRecoveryCodes deletes existing recovery codes and stores a new hashed
+// set, returning the plaintext codes so they can be shown once.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		rows[i] = models.MFARecoveryCode{UserID: userID, CodeHash: hashToken(normalizeMFACode(code))}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return raw[:5] + "-" + raw[5:], nil
+}
+
+// normalizeMFACode strips the spaces and dashes authenticator apps and
+// recovery codes are displayed with, so "123 456" is read as "123456"
+func normalizeMFACode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
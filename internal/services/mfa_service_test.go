@@ -0,0 +1,52 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/totp"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+func newTestMFAService(t *testing.T) (MFAService, uint) {
+	t.Helper()
+	db := testutil.NewTestDB(t)
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	cipher, err := utils.NewSecretCipher(base64.StdEncoding.EncodeToString(key))
+	testutil.AssertNoError(t, err)
+	user := testutil.CreateStandardUserFixture(db)
+	return NewMFAService(db, cipher, "Test"), user.ID
+}
+
+// formatted returns the TOTP code of step as authenticator apps display it
+func formatted(t *testing.T, secret string, step int64, separator string) string {
+	t.Helper()
+	code, err := totp.CodeAt(secret, step)
+	testutil.AssertNoError(t, err)
+	return code[:3] + separator + code[3:]
+}
+
+func TestMFAAcceptsCodesWithSpacesAndDashes(t *testing.T) {
+	service, userID := newTestMFAService(t)
+	enrollment, err := service.BeginEnrollment(userID)
+	testutil.AssertNoError(t, err)
+	step := totp.Step(time.Now())
+
+	recovery, err := service.ConfirmEnrollment(userID, formatted(t, enrollment.Secret, step, " "))
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, service.VerifyCode(userID, formatted(t, enrollment.Secret, step+1, "-")))
+
+	// The code of a step is only accepted once
+	err = service.VerifyCode(userID, formatted(t, enrollment.Secret, step+1, " "))
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMFACode), "replayed code: %v", err)
+
+	code := recovery.RecoveryCodes[0]
+	testutil.AssertNoError(t, service.VerifyCode(userID, " "+code[:5]+" "+code[6:]+" "))
+	err = service.VerifyCode(userID, code)
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidMFACode), "reused recovery code: %v", err)
+}
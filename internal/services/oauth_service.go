@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/oauth"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOAuthProviderNotFound = errors.New("oauth provider not configured")
+	ErrInvalidOAuthState     = errors.New("invalid or expired oauth state")
+	ErrOAuthExchangeFailed   = errors.New("oauth login failed")
+	ErrOAuthEmailRequired    = errors.New("provider did not return a verified email address")
+	ErrOAuthAccountExists    = errors.New("an account with this email already exists; log in and link the provider instead")
+	ErrIdentityAlreadyLinked = errors.New("this provider account is already linked to a user")
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrLastLoginMethod       = errors.New("cannot unlink the only login method; set a password first")
+)
+
+type OAuthService interface {
+	Providers() []string
+	Authorize(ctx context.Context, provider string, linkUserID uint) (*dto.OAuthAuthorizeResponse, error)
+	Login(ctx context.Context, provider string, req *dto.OAuthCallbackRequest, client ClientInfo) (*dto.LoginResponse, error)
+	Link(ctx context.Context, userID uint, provider string, req *dto.OAuthCallbackRequest) (*models.UserIdentity, error)
+	ListIdentities(userID uint) ([]models.UserIdentity, error)
+	Unlink(userID, identityID uint) error
+}
+
+type oauthService struct {
+	db           *gorm.DB
+	providers    map[string]*oauth.Provider
+	authService  AuthService
+	tokenManager *jwt.TokenManager
+	stateTTL     time.Duration
+}
+
+func NewOAuthService(db *gorm.DB, providers map[string]*oauth.Provider, authService AuthService, tokenManager *jwt.TokenManager, stateTTL time.Duration) OAuthService {
+	return &oauthService{
+		db:           db,
+		providers:    providers,
+		authService:  authService,
+		tokenManager: tokenManager,
+		stateTTL:     stateTTL,
+	}
+}
+
+func (s *oauthService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize starts the authorization code flow. linkUserID is set when a
+// logged-in user links a provider and zero for a login.
+func (s *oauthService) Authorize(ctx context.Context, providerName string, linkUserID uint) (*dto.OAuthAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	verifier, challenge := oauth.NewPKCE()
+	state := utils.RandomString(24)
+	nonce := utils.RandomString(24)
+	authURL, err := provider.AuthCodeURL(ctx, state, challenge, nonce)
+	if err != nil {
+		return nil, err
+	}
+	stateToken, err := s.tokenManager.GenerateOAuthStateToken(jwt.OAuthStateClaims{
+		Provider:     providerName,
+		State:        state,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserID:       linkUserID,
+	}, s.stateTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OAuthAuthorizeResponse{AuthorizationURL: authURL, StateToken: stateToken}, nil
+}
+
+// Login signs in with a provider account. Unknown accounts are registered
+// without a local password; an existing user with the same email has to link
+// the provider explicitly so an unverified provider cannot take over accounts.
+func (s *oauthService) Login(ctx context.Context, providerName string, req *dto.OAuthCallbackRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	identity, err := s.exchange(ctx, providerName, 0, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var link models.UserIdentity
+	err = s.db.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&link).Error
+	if err == nil {
+		var user models.User
+		if err := s.db.First(&user, link.UserID).Error; err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		if err := s.db.Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": now}).Error; err != nil {
+			return nil, err
+		}
+		return s.authService.CompleteLogin(&user, client)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOAuthEmailRequired
+	}
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("email = ?", identity.Email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrOAuthAccountExists
+	}
+
+	user, err := s.register(identity)
+	if err != nil {
+		return nil, err
+	}
+	return s.authService.CompleteLogin(user, client)
+}
+
+// Link attaches a provider account to the logged-in user
+func (s *oauthService) Link(ctx context.Context, userID uint, providerName string, req *dto.OAuthCallbackRequest) (*models.UserIdentity, error) {
+	identity, err := s.exchange(ctx, providerName, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.UserIdentity
+	err = s.db.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	link := &models.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := s.db.Create(link).Error; err != nil {
+		return nil, err
+	}
+	utils.Log("Security").Info("External identity linked", "user_id", userID, "provider", identity.Provider)
+	return link, nil
+}
+
+func (s *oauthService) ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := s.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Unlink removes a linked provider account unless it is the user's only way to log in
+func (s *oauthService) Unlink(userID, identityID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		if err := tx.Where("id = ? AND user_id = ?", identityID, userID).First(&link).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrIdentityNotFound
+			}
+			return err
+		}
+
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.Password == nil {
+			var count int64
+			if err := tx.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count <= 1 {
+				return ErrLastLoginMethod
+			}
+		}
+
+		if err := tx.Delete(&link).Error; err != nil {
+			return err
+		}
+		utils.Log("Security").Info("External identity unlinked", "user_id", userID, "provider", link.Provider)
+		return nil
+	})
+}
+
+// exchange validates the state token against the callback and redeems the code
+func (s *oauthService) exchange(ctx context.Context, providerName string, userID uint, req *dto.OAuthCallbackRequest) (*oauth.Identity, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+	claims, err := s.tokenManager.ValidateOAuthStateToken(req.StateToken)
+	if err != nil {
+		return nil, ErrInvalidOAuthState
+	}
+	if claims.Provider != providerName || claims.UserID != userID ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(req.State)) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, claims.CodeVerifier, claims.Nonce)
+	if err != nil {
+		utils.Log("Auth").Warn("OAuth code exchange failed", "provider", providerName, "error", err)
+		return nil, ErrOAuthExchangeFailed
+	}
+	return identity, nil
+}
+
+func (s *oauthService) register(identity *oauth.Identity) (*models.User, error) {
+	now := time.Now()
+	user := &models.User{
+		Email:               identity.Email,
+		PasswordIsSetByUser: false,
+		IsActive:            true,
+		EmailVerifiedAt:     &now,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := assignRoles(tx, user.ID, defaultRole); err != nil {
+			return err
+		}
+		firstName := identity.Name
+		if firstName == "" {
+			firstName, _, _ = strings.Cut(identity.Email, "@")
+		}
+		if err := tx.Create(&models.UserProfile{UserID: user.ID, FirstName: firstName}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    identity.Provider,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Auth").Info("User registered through OAuth", "user_id", user.ID, "provider", identity.Provider)
+	return user, nil
+}
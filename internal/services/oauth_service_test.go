@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/oauth"
+	"go-fiber-boilerplate/pkg/password"
+	"gorm.io/gorm"
+)
+
+type oauthFixture struct {
+	db      *gorm.DB
+	idp     *testutil.FakeOIDCProvider
+	service OAuthService
+}
+
+func newOAuthFixture(t *testing.T) *oauthFixture {
+	t.Helper()
+	config.AppConfig = &config.Config{
+		JWTExpiry:        15 * time.Minute,
+		JWTRefreshExpiry: time.Hour,
+		MFAChallengeTTL:  5 * time.Minute,
+		OAuthStateTTL:    10 * time.Minute,
+	}
+
+	db := testutil.NewTestDB(t)
+
+	idp := testutil.NewFakeOIDCProvider(t)
+	provider := oauth.NewProvider(oauth.ProviderConfig{
+		Name:         "fake",
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		Issuer:       idp.Issuer(),
+		RedirectURL:  "http://localhost:3000/oauth/{provider}/callback",
+	})
+
+	tokenManager := jwt.NewTokenManager("test-secret-key-that-is-long-enough")
+	denylist := cache.NewTokenDenylist(nil)
+	sessionService := NewSessionService(db, denylist)
+	lockoutService := NewLockoutService(db, nil, LockoutOptions{Threshold: 5, Duration: time.Minute})
+	authService := NewAuthService(db, NewNoopEmailService(), sessionService, NewMFAService(db, nil, "Test"), lockoutService, NewTokenVersionService(db, nil), NewPasswordPolicyService(db, password.NewPolicy(), 0), denylist, tokenManager)
+	return &oauthFixture{
+		db:      db,
+		idp:     idp,
+		service: NewOAuthService(db, map[string]*oauth.Provider{"fake": provider}, authService, tokenManager, time.Minute),
+	}
+}
+
+// callback runs the consent step at the fake provider and builds the request
+// the frontend posts back.
+func (f *oauthFixture) callback(t *testing.T, linkUserID uint, user testutil.FakeOIDCUser) *dto.OAuthCallbackRequest {
+	t.Helper()
+	resp, err := f.service.Authorize(context.Background(), "fake", linkUserID)
+	testutil.AssertNoError(t, err)
+	code, state := f.idp.Authorize(t, resp.AuthorizationURL, user)
+	return &dto.OAuthCallbackRequest{Code: code, State: state, StateToken: resp.StateToken}
+}
+
+func TestOAuthLoginRegistersAndReusesIdentity(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+	user := testutil.FakeOIDCUser{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, Name: "New User"}
+
+	resp, err := f.service.Login(ctx, "fake", f.callback(t, 0, user), ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, "", resp.Token)
+	testutil.AssertNotEqual(t, "", resp.RefreshToken)
+
+	var created models.User
+	testutil.AssertNoError(t, f.db.Where("email = ?", "new@example.com").First(&created).Error)
+	testutil.AssertTrue(t, created.Password == nil, "OAuth users have no local password")
+	testutil.AssertTrue(t, created.IsEmailVerified())
+
+	_, err = f.service.Login(ctx, "fake", f.callback(t, 0, user), ClientInfo{})
+	testutil.AssertNoError(t, err)
+	var users, identities int64
+	f.db.Model(&models.User{}).Count(&users)
+	f.db.Model(&models.UserIdentity{}).Count(&identities)
+	testutil.AssertEqual(t, int64(1), users)
+	testutil.AssertEqual(t, int64(1), identities)
+}
+
+func TestOAuthLoginRejectsUnsafeCallbacks(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+	testutil.CreateStandardUserFixture(f.db)
+
+	existing := testutil.FakeOIDCUser{Subject: "sub-2", Email: "user@test.com", EmailVerified: true}
+	_, err := f.service.Login(ctx, "fake", f.callback(t, 0, existing), ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrOAuthAccountExists), "existing emails must be linked explicitly")
+
+	unverified := testutil.FakeOIDCUser{Subject: "sub-3", Email: "other@example.com"}
+	_, err = f.service.Login(ctx, "fake", f.callback(t, 0, unverified), ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrOAuthEmailRequired), err)
+
+	req := f.callback(t, 0, existing)
+	req.State = "tampered"
+	_, err = f.service.Login(ctx, "fake", req, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidOAuthState), err)
+
+	_, err = f.service.Login(ctx, "other", f.callback(t, 0, existing), ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrOAuthProviderNotFound), err)
+
+	linkReq := f.callback(t, 42, existing)
+	_, err = f.service.Login(ctx, "fake", linkReq, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidOAuthState), "link state must not be usable for login")
+}
+
+func TestOAuthLinkAndUnlink(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+	user := testutil.CreateStandardUserFixture(f.db)
+	account := testutil.FakeOIDCUser{Subject: "sub-4", Email: "user@test.com", EmailVerified: true}
+
+	identity, err := f.service.Link(ctx, user.ID, "fake", f.callback(t, user.ID, account))
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, user.ID, identity.UserID)
+
+	resp, err := f.service.Login(ctx, "fake", f.callback(t, 0, account), ClientInfo{})
+	testutil.AssertNoError(t, err, "linked accounts can log in")
+	testutil.AssertNotEqual(t, "", resp.Token)
+
+	other := testutil.CreateUserFixture(f.db, "Other", "other@example.com", "password123", "user")
+	_, err = f.service.Link(ctx, other.ID, "fake", f.callback(t, other.ID, account))
+	testutil.AssertTrue(t, errors.Is(err, ErrIdentityAlreadyLinked), err)
+
+	testutil.AssertNoError(t, f.service.Unlink(user.ID, identity.ID), "users with a password can unlink")
+	testutil.AssertTrue(t, errors.Is(f.service.Unlink(user.ID, identity.ID), ErrIdentityNotFound))
+}
+
+func TestOAuthUnlinkKeepsLastLoginMethod(t *testing.T) {
+	f := newOAuthFixture(t)
+	ctx := context.Background()
+	account := testutil.FakeOIDCUser{Subject: "sub-5", Email: "solo@example.com", EmailVerified: true}
+
+	_, err := f.service.Login(ctx, "fake", f.callback(t, 0, account), ClientInfo{})
+	testutil.AssertNoError(t, err)
+	var user models.User
+	testutil.AssertNoError(t, f.db.Where("email = ?", "solo@example.com").First(&user).Error)
+	identities, err := f.service.ListIdentities(user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, identities, 1)
+
+	err = f.service.Unlink(user.ID, identities[0].ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrLastLoginMethod), err)
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// membershipCacheTTL bounds how long a removed member can keep access through
+// another instance's cache
+const membershipCacheTTL = time.Minute
+
+var (
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrSlugTaken             = errors.New("organization slug is already taken")
+	ErrNotOrganizationMember = errors.New("not a member of the organization")
+	ErrMemberNotFound        = errors.New("organization member not found")
+	ErrAlreadyMember         = errors.New("user is already a member of the organization")
+	ErrLastOwner             = errors.New("an organization needs at least one owner")
+	ErrOrganizationForbidden = errors.New("insufficient organization role")
+)
+
+// OrganizationService manages organizations and their memberships. Roles
+// within an organization are owner, admin and member: admins manage members,
+// only owners grant or revoke ownership.
+type OrganizationService interface {
+	Create(userID uint, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error)
+	ListForUser(userID uint) ([]dto.OrganizationResponse, error)
+	Get(userID, organizationID uint) (*dto.OrganizationResponse, error)
+	FindIDBySlug(ctx context.Context, slug string) (uint, error)
+	Membership(ctx context.Context, organizationID, userID uint) (string, error)
+	SoleOrganization(ctx context.Context, userID uint) (uint, error)
+	ListMembers(organizationID uint) ([]dto.OrganizationMemberResponse, error)
+	AddMember(actorID, organizationID uint, req *dto.AddOrganizationMemberRequest) (*dto.OrganizationMemberResponse, error)
+	UpdateMemberRole(actorID, organizationID, userID uint, role string) (*dto.OrganizationMemberResponse, error)
+	RemoveMember(actorID, organizationID, userID uint) error
+	Switch(userID, sessionID, organizationID uint) (*dto.RefreshTokenResponse, error)
+}
+
+type organizationService struct {
+	db          *gorm.DB
+	cache       *cache.Client
+	authService AuthService
+}
+
+func NewOrganizationService(db *gorm.DB, cacheClient *cache.Client, authService AuthService) OrganizationService {
+	return &organizationService{db: db, cache: cacheClient, authService: authService}
+}
+
+// Create creates an organization with the user as its owner
+func (s *organizationService) Create(userID uint, req *dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	organization := &models.Organization{
+		Name: strings.TrimSpace(req.Name),
+		Slug: strings.ToLower(req.Slug),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Unscoped().Model(&models.Organization{}).Where("slug = ?", organization.Slug).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSlugTaken
+		}
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         userID,
+			Role:           models.OrganizationRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Log("Organization").Info("Organization created", "organization_id", organization.ID, "user_id", userID)
+	return toOrganizationResponse(organization, models.OrganizationRoleOwner), nil
+}
+
+// organizationMembership is an organization joined with the user's role in it
+type organizationMembership struct {
+	models.Organization
+	Role string
+}
+
+func (s *organizationService) ListForUser(userID uint) ([]dto.OrganizationResponse, error) {
+	var memberships []organizationMembership
+	if err := s.membershipsOf(userID).Order("organizations.name, organizations.id").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dto.OrganizationResponse, 0, len(memberships))
+	for i := range memberships {
+		out = append(out, *toOrganizationResponse(&memberships[i].Organization, memberships[i].Role))
+	}
+	return out, nil
+}
+
+// Get returns an organization the user is a member of; other organizations
+// are reported as not found
+func (s *organizationService) Get(userID, organizationID uint) (*dto.OrganizationResponse, error) {
+	var memberships []organizationMembership
+	if err := s.membershipsOf(userID).Where("organizations.id = ?", organizationID).Limit(1).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, ErrOrganizationNotFound
+	}
+	return toOrganizationResponse(&memberships[0].Organization, memberships[0].Role), nil
+}
+
+// FindIDBySlug resolves a subdomain to an organization
+func (s *organizationService) FindIDBySlug(ctx context.Context, slug string) (uint, error) {
+	var organization models.Organization
+	if err := s.db.WithContext(ctx).Select("id").Where("slug = ?", strings.ToLower(slug)).First(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrOrganizationNotFound
+		}
+		return 0, err
+	}
+	return organization.ID, nil
+}
+
+// Membership returns the user's role in the organization, or
+// ErrNotOrganizationMember. Roles are cached until Invalidate or the TTL.
+func (s *organizationService) Membership(ctx context.Context, organizationID, userID uint) (string, error) {
+	var role string
+	key := membershipKey(organizationID, userID)
+	if s.cache.GetJSON(ctx, key, &role) {
+		return role, nil
+	}
+	var membership models.OrganizationMember
+	err := s.db.WithContext(ctx).
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.organization_id = ? AND organization_members.user_id = ?", organizationID, userID).
+		First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotOrganizationMember
+		}
+		return "", err
+	}
+	s.cache.SetJSON(ctx, key, membership.Role, membershipCacheTTL)
+	return membership.Role, nil
+}
+
+// SoleOrganization returns the user's organization when they belong to
+// exactly one, and 0 otherwise
+func (s *organizationService) SoleOrganization(ctx context.Context, userID uint) (uint, error) {
+	var ids []uint
+	if err := s.db.WithContext(ctx).Model(&models.OrganizationMember{}).
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id AND organizations.deleted_at IS NULL").
+		Where("organization_members.user_id = ?", userID).
+		Limit(2).Pluck("organization_members.organization_id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) != 1 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+func (s *organizationService) ListMembers(organizationID uint) ([]dto.OrganizationMemberResponse, error) {
+	var members []models.OrganizationMember
+	if err := s.db.Preload("User.Profile").Where("organization_id = ?", organizationID).
+		Order("created_at, id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dto.OrganizationMemberResponse, 0, len(members))
+	for i := range members {
+		out = append(out, *toOrganizationMemberResponse(&members[i]))
+	}
+	return out, nil
+}
+
+// AddMember adds an existing user by email. Only owners can add owners.
+func (s *organizationService) AddMember(actorID, organizationID uint, req *dto.AddOrganizationMemberRequest) (*dto.OrganizationMemberResponse, error) {
+	role := req.Role
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if err := s.authorize(actorID, organizationID, role); err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := s.db.Where("email = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	var count int64
+	if err := s.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, user.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrAlreadyMember
+	}
+	member := &models.OrganizationMember{OrganizationID: organizationID, UserID: user.ID, Role: role}
+	if err := s.db.Create(member).Error; err != nil {
+		return nil, err
+	}
+	utils.Log("Organization").Info("Member added", "organization_id", organizationID, "user_id", user.ID, "actor_id", actorID, "role", role)
+	return s.findMember(organizationID, user.ID)
+}
+
+// UpdateMemberRole changes a member's role. Owners can only be changed by
+// owners, and the last owner cannot step down.
+func (s *organizationService) UpdateMemberRole(actorID, organizationID, userID uint, role string) (*dto.OrganizationMemberResponse, error) {
+	current, err := s.findMember(organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Role == role {
+		return current, nil
+	}
+	if err := s.authorize(actorID, organizationID, current.Role, role); err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if current.Role == models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, organizationID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", organizationID, userID).
+			Updates(map[string]interface{}{"role": role, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(organizationID, userID)
+	utils.Log("Organization").Info("Member role changed", "organization_id", organizationID, "user_id", userID, "actor_id", actorID, "from", current.Role, "to", role)
+	return s.findMember(organizationID, userID)
+}
+
+// RemoveMember removes a member; members may always remove themselves. The
+// last owner cannot leave.
+func (s *organizationService) RemoveMember(actorID, organizationID, userID uint) error {
+	current, err := s.findMember(organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if actorID != userID {
+		if err := s.authorize(actorID, organizationID, current.Role); err != nil {
+			return err
+		}
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if current.Role == models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, organizationID, userID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("organization_id = ? AND user_id = ?", organizationID, userID).
+			Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		// Sessions that had the organization active fall back to none
+		return tx.Model(&models.UserSession{}).Where("user_id = ? AND organization_id = ?", userID, organizationID).
+			Update("organization_id", nil).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate(organizationID, userID)
+	utils.Log("Organization").Info("Member removed", "organization_id", organizationID, "user_id", userID, "actor_id", actorID)
+	return nil
+}
+
+// Switch makes the organization the session's active one and issues a new
+// token pair carrying it
+func (s *organizationService) Switch(userID, sessionID, organizationID uint) (*dto.RefreshTokenResponse, error) {
+	if _, err := s.Membership(context.Background(), organizationID, userID); err != nil {
+		return nil, err
+	}
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"organization_id": organizationID, "updated_at": time.Now()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return s.authService.ReissueTokens(userID, sessionID)
+}
+
+// authorize checks that the actor may manage members holding the roles
+func (s *organizationService) authorize(actorID, organizationID uint, roles ...string) error {
+	actorRole, err := s.Membership(context.Background(), organizationID, actorID)
+	if err != nil {
+		return err
+	}
+	return checkManage(actorRole, roles...)
+}
+
+// checkManage reports whether a member with actorRole may manage members
+// holding the roles. Admins manage admins and members; owners manage everyone.
+func checkManage(actorRole string, roles ...string) error {
+	switch actorRole {
+	case models.OrganizationRoleOwner:
+		return nil
+	case models.OrganizationRoleAdmin:
+		for _, role := range roles {
+			if role == models.OrganizationRoleOwner {
+				return ErrOrganizationForbidden
+			}
+		}
+		return nil
+	default:
+		return ErrOrganizationForbidden
+	}
+}
+
+func (s *organizationService) membershipsOf(userID uint) *gorm.DB {
+	return s.db.Model(&models.Organization{}).
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID)
+}
+
+func (s *organizationService) findMember(organizationID, userID uint) (*dto.OrganizationMemberResponse, error) {
+	var member models.OrganizationMember
+	if err := s.db.Preload("User.Profile").Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return toOrganizationMemberResponse(&member), nil
+}
+
+func (s *organizationService) invalidate(organizationID, userID uint) {
+	s.cache.Delete(context.Background(), membershipKey(organizationID, userID))
+}
+
+// ensureAnotherOwner fails with ErrLastOwner unless someone besides userID owns the organization
+func ensureAnotherOwner(tx *gorm.DB, organizationID, userID uint) error {
+	var owners int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ? AND user_id <> ?", organizationID, models.OrganizationRoleOwner, userID).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func membershipKey(organizationID, userID uint) string {
+	return "org:member:" + strconv.FormatUint(uint64(organizationID), 10) + ":" + strconv.FormatUint(uint64(userID), 10)
+}
+
+func toOrganizationResponse(organization *models.Organization, role string) *dto.OrganizationResponse {
+	return &dto.OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Slug:      organization.Slug,
+		Role:      role,
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+func toOrganizationMemberResponse(member *models.OrganizationMember) *dto.OrganizationMemberResponse {
+	resp := &dto.OrganizationMemberResponse{
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+	if member.User != nil {
+		resp.Email = member.User.Email
+		if member.User.Profile != nil {
+			resp.Profile = &dto.UserProfileResponse{
+				FirstName: member.User.Profile.FirstName,
+				LastName:  member.User.Profile.LastName,
+			}
+		}
+	}
+	return resp
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/password"
+	"gorm.io/gorm"
+)
+
+var ErrPasswordPolicy = errors.New("password does not meet the password policy")
+
+// PasswordPolicyError lists the rules a new password violates. It matches
+// ErrPasswordPolicy with errors.Is.
+type PasswordPolicyError struct {
+	Field      string
+	Violations []password.Violation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	if len(e.Violations) == 0 {
+		return ErrPasswordPolicy.Error()
+	}
+	return e.Field + " " + e.Violations[0].Message
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
+
+// PasswordPolicyService checks new passwords against the configured policy
+// and keeps the password history the policy consults.
+type PasswordPolicyService interface {
+	Check(ctx context.Context, field, plain string, user *models.User) error
+	Remember(tx *gorm.DB, userID uint, hash string) error
+}
+
+type passwordPolicyService struct {
+	db          *gorm.DB
+	policy      *password.Policy
+	historySize int
+}
+
+func NewPasswordPolicyService(db *gorm.DB, policy *password.Policy, historySize int) PasswordPolicyService {
+	return &passwordPolicyService{db: db, policy: policy, historySize: historySize}
+}
+
+// Check returns a PasswordPolicyError reported against field when plain
+// violates the policy. user may be unsaved, as during registration; for
+// existing users the password history is checked as well.
+func (s *passwordPolicyService) Check(ctx context.Context, field, plain string, user *models.User) error {
+	in := password.Input{Password: plain, Email: user.Email}
+
+	profile := user.Profile
+	if profile == nil && user.ID != 0 {
+		var loaded models.UserProfile
+		if err := s.db.WithContext(ctx).Where("user_id = ?", user.ID).First(&loaded).Error; err == nil {
+			profile = &loaded
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if profile != nil {
+		in.Names = append(in.Names, profile.FirstName)
+		if profile.LastName != nil {
+			in.Names = append(in.Names, *profile.LastName)
+		}
+	}
+
+	if user.ID != 0 && s.historySize > 0 {
+		history, err := s.history(ctx, user)
+		if err != nil {
+			return err
+		}
+		in.History = history
+	}
+
+	violations, err := s.policy.Check(ctx, in)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Field: field, Violations: violations}
+	}
+	return nil
+}
+
+// Remember records a newly set password hash and drops entries beyond the
+// configured history size
+func (s *passwordPolicyService) Remember(tx *gorm.DB, userID uint, hash string) error {
+	if s.historySize <= 0 {
+		return nil
+	}
+	if err := tx.Create(&models.PasswordHistory{UserID: userID, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+	keep := tx.Model(&models.PasswordHistory{}).Select("id").Where("user_id = ?", userID).Order("id DESC").Limit(s.historySize)
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID, keep).Delete(&models.PasswordHistory{}).Error
+}
+
+// history returns the user's recent password hashes, newest first. The
+// current password is included even when it predates the history table.
+func (s *passwordPolicyService) history(ctx context.Context, user *models.User) ([]string, error) {
+	var hashes []string
+	if err := s.db.WithContext(ctx).Model(&models.PasswordHistory{}).
+		Where("user_id = ?", user.ID).Order("id DESC").Limit(s.historySize).
+		Pluck("password_hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+	if user.Password != nil && !slices.Contains(hashes, *user.Password) {
+		hashes = append([]string{*user.Password}, hashes...)
+		if len(hashes) > s.historySize {
+			hashes = hashes[:s.historySize]
+		}
+	}
+	return hashes, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/password"
+)
+
+var testPasswordConfig = &config.Config{
+	JWTExpiry:               15 * time.Minute,
+	PasswordMinLength:       10,
+	PasswordRequiredClasses: "lowercase,digit",
+	PasswordHistory:         2,
+	PasswordCheckBreached:   true,
+}
+
+// violationCodes returns the codes of the rules err reports
+func violationCodes(t *testing.T, err error) []string {
+	t.Helper()
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("error = %v, want *PasswordPolicyError", err)
+	}
+	testutil.AssertTrue(t, errors.Is(err, ErrPasswordPolicy), "PasswordPolicyError must match ErrPasswordPolicy")
+	codes := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewPasswordPolicyService(db, testPasswordConfig.GetPasswordPolicy(), testPasswordConfig.PasswordHistory)
+	user := testutil.CreateUserFixture(db, "Jane", "jane.doe@example.com", "password123", "user")
+	ctx := context.Background()
+
+	err := service.Check(ctx, "password", "short", user)
+	testutil.AssertEqual(t, []string{password.CodeTooShort, password.CodeMissingClass}, violationCodes(t, err))
+	testutil.AssertEqual(t, "password", err.(*PasswordPolicyError).Field)
+
+	err = service.Check(ctx, "password", "password123", user)
+	testutil.AssertEqual(t, []string{password.CodeRecentlyUsed, password.CodeBreached}, violationCodes(t, err))
+
+	// The name comes from the stored profile, the other part from the email
+	err = service.Check(ctx, "password", "mightyjane42", user)
+	testutil.AssertEqual(t, []string{password.CodeContainsPersonal}, violationCodes(t, err))
+	err = service.Check(ctx, "password", "doe-the-best-7", user)
+	testutil.AssertEqual(t, []string{password.CodeContainsPersonal}, violationCodes(t, err))
+
+	testutil.AssertNoError(t, service.Check(ctx, "password", "correct-horse-9", user))
+	// Registration checks an unsaved user, which has no history
+	testutil.AssertNoError(t, service.Check(ctx, "password", "correct-horse-9", &models.User{Email: "new@example.com"}))
+}
+
+func TestPasswordHistoryBlocksRecentPasswords(t *testing.T) {
+	config.AppConfig = testPasswordConfig
+	db := testutil.NewTestDB(t)
+	policy := NewPasswordPolicyService(db, testPasswordConfig.GetPasswordPolicy(), testPasswordConfig.PasswordHistory)
+	service := NewUserService(db, NewSessionService(db, nil), NewTokenVersionService(db, nil), policy)
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "first-secret-1", "user")
+	change := func(old, new string) error {
+		return service.ChangePassword(user.ID, 0, &dto.ChangePasswordRequest{OldPassword: old, NewPassword: new})
+	}
+
+	testutil.AssertNoError(t, change("first-secret-1", "second-secret-2"))
+	testutil.AssertNoError(t, change("second-secret-2", "third-secret-3"))
+	testutil.AssertEqual(t, []string{password.CodeRecentlyUsed}, violationCodes(t, change("third-secret-3", "second-secret-2")))
+	testutil.AssertEqual(t, []string{password.CodeRecentlyUsed}, violationCodes(t, change("third-secret-3", "third-secret-3")))
+
+	// Only the last two passwords are remembered
+	testutil.AssertNoError(t, change("third-secret-3", "first-secret-1"))
+	var kept int64
+	testutil.AssertNoError(t, db.Model(&models.PasswordHistory{}).Where("user_id = ?", user.ID).Count(&kept).Error)
+	testutil.AssertEqual(t, int64(2), kept)
+}
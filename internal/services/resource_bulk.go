@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/pkg/utils"
+	"go-fiber-boilerplate/pkg/versioning"
+	"gorm.io/gorm"
+)
+
+// bulkBatchSize is how many items of a partial bulk request share a
+// transaction
+const bulkBatchSize = 100
+
+// ErrBulkAborted marks the items of an atomic bulk request that were rolled
+// back, or never tried, because another item failed
+var ErrBulkAborted = errors.New("not applied because another item failed")
+
+// BulkBatchError reports a partial bulk request stopped by a batch
+// transaction that could not be committed. The items before Index were
+// applied; it and the later ones were not.
+type BulkBatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkBatchError) Error() string {
+	return fmt.Sprintf("bulk batch from item %d failed: %v", e.Index, e.Err)
+}
+
+func (e *BulkBatchError) Unwrap() error { return e.Err }
+
+// InvalidItemError reports a bulk item rejected by its DTO validation
+type InvalidItemError struct {
+	Err error
+}
+
+func (e *InvalidItemError) Error() string { return e.Err.Error() }
+
+func (e *InvalidItemError) Unwrap() error { return e.Err }
+
+// BulkResult is the outcome of the item at Index. ID is the resource the item
+// created or targeted; Err is nil when the item was applied.
+type BulkResult struct {
+	Index int
+	ID    uint
+	Err   error
+}
+
+// BulkCreate creates resources. In atomic mode every item is created or none
+// is; otherwise each valid item is created on its own. Items are checked with
+// their DTO's Validate before any is applied.
+func (s *resourceService) BulkCreate(ctx context.Context, userID uint, items []dto.CreateResourceRequest, atomic bool) ([]BulkResult, error) {
+	return s.runBulk(ctx, len(items), atomic,
+		func(i int) (uint, error) {
+			return 0, items[i].Validate()
+		},
+		func(tx *resourceService, i int) (uint, error) {
+			resource, err := tx.CreateResource(ctx, userID, &items[i])
+			if err != nil {
+				return 0, err
+			}
+			return resource.ID, nil
+		})
+}
+
+// BulkUpdate updates resources like UpdateResource, with the same modes as
+// BulkCreate. An item with a version fails with
+// versioning.ErrPreconditionFailed unless the resource is still at it.
+func (s *resourceService) BulkUpdate(ctx context.Context, items []dto.BulkUpdateResourceItem, atomic bool) ([]BulkResult, error) {
+	return s.runBulk(ctx, len(items), atomic,
+		func(i int) (uint, error) {
+			return items[i].ID, items[i].Validate()
+		},
+		func(tx *resourceService, i int) (uint, error) {
+			itemCtx := ctx
+			if items[i].Version != nil {
+				itemCtx = versioning.WithPrecondition(ctx, versioning.Precondition{Versions: []uint{*items[i].Version}})
+			}
+			if _, err := tx.UpdateResource(itemCtx, items[i].ID, &items[i].UpdateResourceRequest); err != nil {
+				return 0, err
+			}
+			return items[i].ID, nil
+		})
+}
+
+// BulkDelete deletes resources like DeleteResource, with the same modes as
+// BulkCreate
+func (s *resourceService) BulkDelete(ctx context.Context, ids []uint, atomic bool) ([]BulkResult, error) {
+	return s.runBulk(ctx, len(ids), atomic,
+		func(i int) (uint, error) {
+			if ids[i] == 0 {
+				return 0, errors.New("id is required")
+			}
+			return ids[i], nil
+		},
+		func(tx *resourceService, i int) (uint, error) {
+			return ids[i], tx.DeleteResource(ctx, ids[i])
+		})
+}
+
+// runBulk validates n items with prepare, which also returns the ID an item
+// targets, then applies them with apply. An atomic run uses one transaction
+// and stops at the first failure. A partial run uses a transaction per
+// bulkBatchSize items and a savepoint per item, so a failed item is undone
+// alone. The error is set when the transaction of an atomic run fails, or a
+// *BulkBatchError when a batch of a partial run cannot be committed, in which
+// case the earlier batches stay applied and the later ones are not tried.
+func (s *resourceService) runBulk(ctx context.Context, n int, atomic bool, prepare func(i int) (uint, error), apply func(tx *resourceService, i int) (uint, error)) ([]BulkResult, error) {
+	results := make([]BulkResult, n)
+	targets := make([]uint, n)
+	valid := true
+	for i := range results {
+		id, err := prepare(i)
+		results[i] = BulkResult{Index: i, ID: id}
+		targets[i] = id
+		if err != nil {
+			results[i].Err = &InvalidItemError{Err: err}
+			valid = false
+		}
+	}
+
+	if atomic {
+		failed := !valid
+		if valid {
+			err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				bound := s.withDB(tx)
+				for i := range results {
+					id, err := apply(bound, i)
+					if err != nil {
+						results[i].Err = err
+						failed = true
+						return err
+					}
+					results[i].ID = id
+				}
+				return nil
+			})
+			if err != nil && !failed {
+				return nil, err
+			}
+		}
+		if failed {
+			for i := range results {
+				if results[i].Err == nil {
+					results[i] = BulkResult{Index: i, ID: targets[i], Err: ErrBulkAborted}
+				}
+			}
+		}
+		return results, nil
+	}
+
+	for start := 0; start < n; start += bulkBatchSize {
+		end := min(start+bulkBatchSize, n)
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			bound := s.withDB(tx)
+			for i := start; i < end; i++ {
+				if results[i].Err != nil {
+					continue
+				}
+				if err := tx.SavePoint("bulk_item").Error; err != nil {
+					return err
+				}
+				id, err := apply(bound, i)
+				if err != nil {
+					results[i].Err = err
+					if err := tx.RollbackTo("bulk_item").Error; err != nil {
+						return err
+					}
+					continue
+				}
+				results[i].ID = id
+			}
+			return nil
+		})
+		if err != nil {
+			utils.Log("Resource").Error("Bulk batch failed", "from", start, "to", end, "error", err)
+			return nil, &BulkBatchError{Index: start, Err: err}
+		}
+	}
+	return results, nil
+}
+
+// withDB returns a copy of the service that runs its queries on db
+func (s *resourceService) withDB(db *gorm.DB) *resourceService {
+	bound := *s
+	bound.db = db
+	return &bound
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/versioning"
+	"gorm.io/gorm"
+)
+
+var errRejected = errors.New("rejected")
+
+// newBulkService returns a resource service on db whose creates of a resource
+// named "Rejected" fail after the row was inserted, so only a rollback
+// removes it, and a context acting for the owner of a fresh organization
+func newBulkService(t *testing.T, db *gorm.DB) (*resourceService, context.Context, uint) {
+	t.Helper()
+	err := db.Callback().Create().After("gorm:create").Register("test:reject", func(tx *gorm.DB) {
+		if resource, ok := tx.Statement.Dest.(*models.Resource); ok && resource.Name == "Rejected" {
+			tx.AddError(errRejected)
+		}
+	})
+	testutil.AssertNoError(t, err)
+	owner := testutil.CreateStandardUserFixture(db)
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	return NewResourceService(db, nil, ResourcePolicy{}, false).(*resourceService), testutil.ActAs(db, org.ID, owner.ID), owner.ID
+}
+
+// resourceNames lists the names of the resources ctx sees, oldest first
+func resourceNames(t *testing.T, db *gorm.DB, ctx context.Context) []string {
+	t.Helper()
+	var names []string
+	testutil.AssertNoError(t, db.WithContext(ctx).Model(&models.Resource{}).Order("id").Pluck("name", &names).Error)
+	return names
+}
+
+func bulkItems(names ...string) []dto.CreateResourceRequest {
+	items := make([]dto.CreateResourceRequest, len(names))
+	for i, name := range names {
+		items[i] = dto.CreateResourceRequest{Name: name}
+	}
+	return items
+}
+
+func TestBulkCreateAtomicRollsBackEveryItem(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service, ctx, userID := newBulkService(t, db)
+
+	results, err := service.BulkCreate(ctx, userID, bulkItems("First", "Rejected", "Third"), true)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, errors.Is(results[0].Err, ErrBulkAborted), "applied item: %v", results[0].Err)
+	testutil.AssertTrue(t, errors.Is(results[1].Err, errRejected), "failed item: %v", results[1].Err)
+	testutil.AssertTrue(t, errors.Is(results[2].Err, ErrBulkAborted), "untried item: %v", results[2].Err)
+	testutil.AssertLen(t, resourceNames(t, db, ctx), 0)
+
+	// An invalid item stops the request before anything is applied
+	results, err = service.BulkCreate(ctx, userID, bulkItems("First", "x"), true)
+	testutil.AssertNoError(t, err)
+	var invalid *InvalidItemError
+	testutil.AssertTrue(t, errors.As(results[1].Err, &invalid), "invalid item: %v", results[1].Err)
+	testutil.AssertTrue(t, errors.Is(results[0].Err, ErrBulkAborted), "valid item: %v", results[0].Err)
+	testutil.AssertLen(t, resourceNames(t, db, ctx), 0)
+}
+
+func TestBulkCreatePartialRollsBackFailedItemsOnly(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service, ctx, userID := newBulkService(t, db)
+
+	results, err := service.BulkCreate(ctx, userID, bulkItems("First", "Rejected", "x", "Fourth"), false)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, results[0].Err)
+	testutil.AssertTrue(t, errors.Is(results[1].Err, errRejected), "failed item: %v", results[1].Err)
+	var invalid *InvalidItemError
+	testutil.AssertTrue(t, errors.As(results[2].Err, &invalid), "invalid item: %v", results[2].Err)
+	testutil.AssertNoError(t, results[3].Err)
+	testutil.AssertNotEqual(t, uint(0), results[3].ID)
+	testutil.AssertEqual(t, []string{"First", "Fourth"}, resourceNames(t, db, ctx))
+}
+
+func TestBulkCreatePartialReportsFailedBatch(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service, ctx, userID := newBulkService(t, db)
+	names := make([]string, bulkBatchSize+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("Item %d", i)
+	}
+	// Cancelling the context once the first batch is written fails its commit
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	created := 0
+	err := db.Callback().Create().After("gorm:create").Register("test:cancel", func(tx *gorm.DB) {
+		if created++; created == bulkBatchSize {
+			cancel()
+		}
+	})
+	testutil.AssertNoError(t, err)
+
+	results, err := service.BulkCreate(cancelCtx, userID, bulkItems(names...), false)
+	var batchErr *BulkBatchError
+	testutil.AssertTrue(t, errors.As(err, &batchErr), "failed batch: %v", err)
+	testutil.AssertEqual(t, 0, batchErr.Index)
+	testutil.AssertTrue(t, errors.Is(err, context.Canceled), "cause: %v", err)
+	testutil.AssertLen(t, results, 0)
+	testutil.AssertLen(t, resourceNames(t, db, ctx), 0)
+}
+
+func TestBulkUpdateChecksVersionsPerMode(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service, ctx, userID := newBulkService(t, db)
+	created, err := service.BulkCreate(ctx, userID, bulkItems("First", "Second"), true)
+	testutil.AssertNoError(t, err)
+	stale, current := uint(2), uint(1)
+	rename := func(id uint, version *uint, name string) dto.BulkUpdateResourceItem {
+		return dto.BulkUpdateResourceItem{ID: id, Version: version, UpdateResourceRequest: dto.UpdateResourceRequest{Name: &name}}
+	}
+
+	results, err := service.BulkUpdate(ctx, []dto.BulkUpdateResourceItem{
+		rename(created[0].ID, &current, "First v2"),
+		rename(created[1].ID, &stale, "Second v2"),
+	}, true)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, errors.Is(results[0].Err, ErrBulkAborted), "applied item: %v", results[0].Err)
+	testutil.AssertTrue(t, errors.Is(results[1].Err, versioning.ErrPreconditionFailed), "stale item: %v", results[1].Err)
+	testutil.AssertEqual(t, []string{"First", "Second"}, resourceNames(t, db, ctx))
+
+	results, err = service.BulkUpdate(ctx, []dto.BulkUpdateResourceItem{
+		rename(created[0].ID, &current, "First v2"),
+		rename(created[1].ID, &stale, "Second v2"),
+		rename(9999, nil, "Missing"),
+	}, false)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, results[0].Err)
+	testutil.AssertTrue(t, errors.Is(results[1].Err, versioning.ErrPreconditionFailed), "stale item: %v", results[1].Err)
+	testutil.AssertTrue(t, errors.Is(results[2].Err, ErrResourceNotFound), "missing item: %v", results[2].Err)
+	testutil.AssertEqual(t, []string{"First v2", "Second"}, resourceNames(t, db, ctx))
+}
+
+func TestBulkDeleteModes(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service, ctx, userID := newBulkService(t, db)
+	created, err := service.BulkCreate(ctx, userID, bulkItems("First", "Second"), true)
+	testutil.AssertNoError(t, err)
+
+	results, err := service.BulkDelete(ctx, []uint{created[0].ID, 9999}, true)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, errors.Is(results[0].Err, ErrBulkAborted), "applied item: %v", results[0].Err)
+	testutil.AssertTrue(t, errors.Is(results[1].Err, ErrResourceNotFound), "missing item: %v", results[1].Err)
+	testutil.AssertEqual(t, []string{"First", "Second"}, resourceNames(t, db, ctx))
+
+	results, err = service.BulkDelete(ctx, []uint{created[0].ID, 0, 9999}, false)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, results[0].Err)
+	var invalid *InvalidItemError
+	testutil.AssertTrue(t, errors.As(results[1].Err, &invalid), "invalid item: %v", results[1].Err)
+	testutil.AssertTrue(t, errors.Is(results[2].Err, ErrResourceNotFound), "missing item: %v", results[2].Err)
+	testutil.AssertEqual(t, []string{"Second"}, resourceNames(t, db, ctx))
+}
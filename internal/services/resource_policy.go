@@ -0,0 +1,33 @@
+package services
+
+import (
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/policy"
+)
+
+// ResourceAccess is a resource together with the best share level the actor
+// holds on it, empty when nothing is shared with them
+type ResourceAccess struct {
+	Resource *models.Resource
+	Share    string
+}
+
+// ResourcePolicy lets members see the resources they created or that were
+// shared with them, and edit their own and those shared at editor level. Only
+// the creator deletes or shares a resource. Organization owners and admins do
+// everything. visibleResources applies the same view rule in SQL.
+type ResourcePolicy struct{}
+
+func (ResourcePolicy) Allow(actor policy.Actor, action policy.Action, access *ResourceAccess) bool {
+	if access.Resource.CreatedByID == actor.UserID ||
+		actor.HasRole(models.OrganizationRoleOwner, models.OrganizationRoleAdmin) {
+		return true
+	}
+	switch action {
+	case policy.View:
+		return access.Share != ""
+	case policy.Update:
+		return access.Share == models.ShareLevelEditor
+	}
+	return false
+}
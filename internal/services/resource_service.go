@@ -1,39 +1,92 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"go-fiber-boilerplate/internal/dto"
 	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/listquery"
+	"go-fiber-boilerplate/pkg/policy"
+	"go-fiber-boilerplate/pkg/utils"
+	"go-fiber-boilerplate/pkg/versioning"
 	"gorm.io/gorm"
 )
 
-var ErrResourceNotFound = errors.New("resource not found")
+var (
+	ErrResourceNotFound  = errors.New("resource not found")
+	ErrResourceForbidden = errors.New("not allowed to modify this resource")
+	ErrShareNotFound     = errors.New("share not found")
+	ErrShareWithCreator  = errors.New("the resource's creator already has full access")
+)
+
+// ResourceListSpec whitelists the filters and sort keys of ListResources
+var ResourceListSpec = listquery.Spec{
+	Filters: map[string]listquery.Filter{
+		"status":         {Columns: []string{"resources.status"}, Op: listquery.In, Values: []string{"active", "inactive", "archived"}},
+		"created_by":     {Columns: []string{"resources.created_by_id"}, Op: listquery.In, Type: listquery.Uint, AllowMe: true},
+		"created_after":  {Columns: []string{"resources.created_at"}, Op: listquery.After, Type: listquery.Time},
+		"created_before": {Columns: []string{"resources.created_at"}, Op: listquery.Before, Type: listquery.Time},
+		"updated_after":  {Columns: []string{"resources.updated_at"}, Op: listquery.After, Type: listquery.Time},
+		"q":              {Columns: []string{"resources.name", "resources.description"}, Op: listquery.Contains},
+	},
+	Sorts: map[string]string{
+		"name":       "resources.name",
+		"status":     "resources.status",
+		"created_at": "resources.created_at",
+		"updated_at": "resources.updated_at",
+	},
+	DefaultSort: "-created_at",
+	TieBreaker:  "resources.id",
+	Reserved:    []string{"page", "limit", "count"},
+}
 
+// ResourceService manages resources of the organization in ctx. Resources are
+// tenant-owned, so every query is scoped to that organization and a context
+// without one fails. Reads and changes are checked against the resource
+// policy for the actor in ctx. Updates and deletes also meet the If-Match
+// precondition in ctx and increment the resource's version.
 type ResourceService interface {
-	ListResources(page, limit int) ([]dto.ResourceResponse, int64, error)
-	GetResource(id uint) (*dto.ResourceResponse, error)
-	CreateResource(userID uint, req *dto.CreateResourceRequest) (*dto.ResourceResponse, error)
-	UpdateResource(id uint, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error)
-	DeleteResource(id uint) error
+	ListResources(ctx context.Context, query *listquery.Query, page, limit int) ([]dto.ResourceResponse, int64, error)
+	ListResourcesByCursor(ctx context.Context, query *listquery.Query, limit int, count bool) ([]dto.ResourceResponse, *listquery.Page, error)
+	GetResource(ctx context.Context, id uint) (*dto.ResourceResponse, error)
+	CreateResource(ctx context.Context, userID uint, req *dto.CreateResourceRequest) (*dto.ResourceResponse, error)
+	UpdateResource(ctx context.Context, id uint, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error)
+	DeleteResource(ctx context.Context, id uint) error
+	ListShares(ctx context.Context, id uint) ([]dto.ResourceShareResponse, error)
+	Share(ctx context.Context, id uint, req *dto.ShareResourceRequest) (*dto.ResourceShareResponse, error)
+	RevokeShare(ctx context.Context, id, shareID uint) error
+	BulkCreate(ctx context.Context, userID uint, items []dto.CreateResourceRequest, atomic bool) ([]BulkResult, error)
+	BulkUpdate(ctx context.Context, items []dto.BulkUpdateResourceItem, atomic bool) ([]BulkResult, error)
+	BulkDelete(ctx context.Context, ids []uint, atomic bool) ([]BulkResult, error)
 }
 
 type resourceService struct {
-	db *gorm.DB
+	db           *gorm.DB
+	emailService EmailService
+	policy       policy.Policy[ResourceAccess]
+	hideDenied   bool
 }
 
-func NewResourceService(db *gorm.DB) ResourceService {
-	return &resourceService{db: db}
+// NewResourceService creates the service. With hideDenied, resources the
+// actor may not access are reported as not found instead of forbidden.
+func NewResourceService(db *gorm.DB, emailService EmailService, resourcePolicy policy.Policy[ResourceAccess], hideDenied bool) ResourceService {
+	return &resourceService{db: db, emailService: emailService, policy: resourcePolicy, hideDenied: hideDenied}
 }
 
-func (s *resourceService) ListResources(page, limit int) ([]dto.ResourceResponse, int64, error) {
+// ListResources lists the resources the actor may view, filtered and sorted
+// by a query parsed with ResourceListSpec
+func (s *resourceService) ListResources(ctx context.Context, query *listquery.Query, page, limit int) ([]dto.ResourceResponse, int64, error) {
 	var resources []models.Resource
 	var total int64
-	if err := s.db.Model(&models.Resource{}).Count(&total).Error; err != nil {
+	db := query.Filter(visibleResources(ctx, s.db.WithContext(ctx).Model(&models.Resource{})))
+	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	offset := (page - 1) * limit
-	if err := s.db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&resources).Error; err != nil {
+	if err := query.Order(db).Offset(offset).Limit(limit).Find(&resources).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]dto.ResourceResponse, 0, len(resources))
@@ -43,8 +96,24 @@ func (s *resourceService) ListResources(page, limit int) ([]dto.ResourceResponse
 	return out, total, nil
 }
 
-func (s *resourceService) GetResource(id uint) (*dto.ResourceResponse, error) {
-	resource, err := s.findResource(id)
+// ListResourcesByCursor lists the page of resources after the query's cursor,
+// counting all matches only when count is set
+func (s *resourceService) ListResourcesByCursor(ctx context.Context, query *listquery.Query, limit int, count bool) ([]dto.ResourceResponse, *listquery.Page, error) {
+	var resources []models.Resource
+	db := query.Filter(visibleResources(ctx, s.db.WithContext(ctx).Model(&models.Resource{})))
+	page, err := query.Paginate(db, &resources, limit, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([]dto.ResourceResponse, 0, len(resources))
+	for _, resource := range resources {
+		out = append(out, toResourceResponse(&resource))
+	}
+	return out, page, nil
+}
+
+func (s *resourceService) GetResource(ctx context.Context, id uint) (*dto.ResourceResponse, error) {
+	resource, err := s.authorizedResource(ctx, policy.View, id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +121,7 @@ func (s *resourceService) GetResource(id uint) (*dto.ResourceResponse, error) {
 	return &resp, nil
 }
 
-func (s *resourceService) CreateResource(userID uint, req *dto.CreateResourceRequest) (*dto.ResourceResponse, error) {
+func (s *resourceService) CreateResource(ctx context.Context, userID uint, req *dto.CreateResourceRequest) (*dto.ResourceResponse, error) {
 	status := req.Status
 	if status == "" {
 		status = "active"
@@ -63,15 +132,15 @@ func (s *resourceService) CreateResource(userID uint, req *dto.CreateResourceReq
 		Status:      status,
 		CreatedByID: userID,
 	}
-	if err := s.db.Create(resource).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(resource).Error; err != nil {
 		return nil, err
 	}
 	resp := toResourceResponse(resource)
 	return &resp, nil
 }
 
-func (s *resourceService) UpdateResource(id uint, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error) {
-	resource, err := s.findResource(id)
+func (s *resourceService) UpdateResource(ctx context.Context, id uint, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error) {
+	resource, err := s.authorizedResource(ctx, policy.Update, id)
 	if err != nil {
 		return nil, err
 	}
@@ -85,28 +154,181 @@ func (s *resourceService) UpdateResource(id uint, req *dto.UpdateResourceRequest
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
-	if len(updates) > 0 {
-		if err := s.db.Model(resource).Updates(updates).Error; err != nil {
+	if err := versioning.Update(s.db.WithContext(ctx), resource, resource.Version, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, err
+	}
+	return s.GetResource(ctx, id)
+}
+
+func (s *resourceService) DeleteResource(ctx context.Context, id uint) error {
+	resource, err := s.authorizedResource(ctx, policy.Delete, id)
+	if err != nil {
+		return err
+	}
+	if err := versioning.Delete(s.db.WithContext(ctx), resource, resource.Version); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResourceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListShares lists who the resource is shared with
+func (s *resourceService) ListShares(ctx context.Context, id uint) ([]dto.ResourceShareResponse, error) {
+	if _, err := s.authorizedResource(ctx, policy.Share, id); err != nil {
+		return nil, err
+	}
+	var shares []models.ResourceShare
+	if err := s.db.WithContext(ctx).Preload("User").Where("resource_id = ?", id).
+		Order("created_at, id").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dto.ResourceShareResponse, 0, len(shares))
+	for i := range shares {
+		out = append(out, toResourceShareResponse(&shares[i]))
+	}
+	return out, nil
+}
+
+// Share grants a member, or every member holding a role, access to the
+// resource, or changes the level of an existing grant. Recipients are
+// notified by email; a failed notification does not undo the grant.
+func (s *resourceService) Share(ctx context.Context, id uint, req *dto.ShareResourceRequest) (*dto.ResourceShareResponse, error) {
+	resource, err := s.authorizedResource(ctx, policy.Share, id)
+	if err != nil {
+		return nil, err
+	}
+	actor, _ := policy.ActorFromContext(ctx)
+	db := s.db.WithContext(ctx)
+
+	share := models.ResourceShare{ResourceID: resource.ID, Level: req.Level, GrantedByID: &actor.UserID}
+	recipients := db.Model(&models.User{}).
+		Joins("JOIN organization_members ON organization_members.user_id = users.id").
+		Where("organization_members.organization_id = ?", resource.OrganizationID)
+	existing := db.Where("resource_id = ?", resource.ID)
+	if req.Role != "" {
+		share.Role = &req.Role
+		recipients = recipients.Where("organization_members.role = ? AND users.id <> ?", req.Role, actor.UserID)
+		existing = existing.Where("role = ?", req.Role)
+	} else {
+		var user models.User
+		if err := db.Joins("JOIN organization_members ON organization_members.user_id = users.id").
+			Where("users.email = ? AND organization_members.organization_id = ?", strings.ToLower(strings.TrimSpace(req.Email)), resource.OrganizationID).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrMemberNotFound
+			}
 			return nil, err
 		}
+		if user.ID == resource.CreatedByID {
+			return nil, ErrShareWithCreator
+		}
+		share.UserID = &user.ID
+		recipients = recipients.Where("users.id = ?", user.ID)
+		existing = existing.Where("user_id = ?", user.ID)
 	}
-	return s.GetResource(id)
+
+	var current models.ResourceShare
+	err = existing.First(&current).Error
+	switch {
+	case err == nil:
+		if current.Level == share.Level {
+			return s.findShare(ctx, current.ID)
+		}
+		if err := db.Model(&current).Updates(map[string]interface{}{
+			"level":         share.Level,
+			"granted_by_id": actor.UserID,
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+			return nil, err
+		}
+		share.ID = current.ID
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := db.Create(&share).Error; err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	utils.Log("Resource").Info("Resource shared", "resource_id", resource.ID, "share_id", share.ID, "level", share.Level, "actor_id", actor.UserID)
+
+	var emails []string
+	if err := recipients.Pluck("users.email", &emails).Error; err != nil {
+		utils.Log("Resource").Error("Failed to load share recipients", "resource_id", resource.ID, "error", err)
+	}
+	for _, email := range emails {
+		if err := s.emailService.SendResourceShared(email, resource.Name, resource.ID, share.Level); err != nil {
+			utils.Log("Resource").Error("Failed to send share notification", "resource_id", resource.ID, "email", email, "error", err)
+		}
+	}
+	return s.findShare(ctx, share.ID)
 }
 
-func (s *resourceService) DeleteResource(id uint) error {
-	result := s.db.Delete(&models.Resource{}, id)
+// RevokeShare removes a grant
+func (s *resourceService) RevokeShare(ctx context.Context, id, shareID uint) error {
+	if _, err := s.authorizedResource(ctx, policy.Share, id); err != nil {
+		return err
+	}
+	result := s.db.WithContext(ctx).Where("resource_id = ?", id).Delete(&models.ResourceShare{}, shareID)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return ErrResourceNotFound
+		return ErrShareNotFound
 	}
 	return nil
 }
 
-func (s *resourceService) findResource(id uint) (*models.Resource, error) {
+// authorizedResource loads the resource and checks the action against the
+// policy for the actor in ctx. Denials are ErrResourceForbidden when the actor
+// can view the resource and ErrResourceNotFound otherwise.
+func (s *resourceService) authorizedResource(ctx context.Context, action policy.Action, id uint) (*models.Resource, error) {
+	resource, err := s.findResource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	access := &ResourceAccess{Resource: resource}
+	if actor, ok := policy.ActorFromContext(ctx); ok {
+		if access.Share, err = s.shareLevel(ctx, resource.ID, actor); err != nil {
+			return nil, err
+		}
+	}
+	if err := policy.Authorize(ctx, s.policy, action, access); err != nil {
+		// Resources the actor cannot even see are reported as missing
+		if s.hideDenied || action == policy.View || policy.Authorize(ctx, s.policy, policy.View, access) != nil {
+			return nil, ErrResourceNotFound
+		}
+		return nil, ErrResourceForbidden
+	}
+	return resource, nil
+}
+
+// shareLevel returns the best level shared with the actor directly or
+// through their organization role
+func (s *resourceService) shareLevel(ctx context.Context, resourceID uint, actor policy.Actor) (string, error) {
+	var levels []string
+	if err := s.db.WithContext(ctx).Model(&models.ResourceShare{}).
+		Where("resource_id = ? AND (user_id = ? OR role IN ?)", resourceID, actor.UserID, actor.Roles).
+		Pluck("level", &levels).Error; err != nil {
+		return "", err
+	}
+	level := ""
+	for _, l := range levels {
+		if l == models.ShareLevelEditor {
+			return l, nil
+		}
+		level = l
+	}
+	return level, nil
+}
+
+func (s *resourceService) findResource(ctx context.Context, id uint) (*models.Resource, error) {
 	var resource models.Resource
-	if err := s.db.First(&resource, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).First(&resource, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrResourceNotFound
 		}
@@ -115,14 +337,58 @@ func (s *resourceService) findResource(id uint) (*models.Resource, error) {
 	return &resource, nil
 }
 
+func (s *resourceService) findShare(ctx context.Context, shareID uint) (*dto.ResourceShareResponse, error) {
+	var share models.ResourceShare
+	if err := s.db.WithContext(ctx).Preload("User").First(&share, shareID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+	resp := toResourceShareResponse(&share)
+	return &resp, nil
+}
+
+// visibleResources restricts a resource query to what ResourcePolicy lets the
+// actor in ctx view. Without an actor nothing is visible.
+func visibleResources(ctx context.Context, db *gorm.DB) *gorm.DB {
+	actor, ok := policy.ActorFromContext(ctx)
+	if !ok {
+		return db.Where("1 = 0")
+	}
+	if actor.HasRole(models.OrganizationRoleOwner, models.OrganizationRoleAdmin) {
+		return db
+	}
+	return db.Where("resources.created_by_id = ? OR EXISTS (SELECT 1 FROM resource_shares WHERE resource_shares.resource_id = resources.id AND (resource_shares.user_id = ? OR resource_shares.role IN ?))",
+		actor.UserID, actor.UserID, actor.Roles)
+}
+
 func toResourceResponse(resource *models.Resource) dto.ResourceResponse {
 	return dto.ResourceResponse{
-		ID:          resource.ID,
-		Name:        resource.Name,
-		Description: resource.Description,
-		Status:      resource.Status,
-		CreatedByID: resource.CreatedByID,
-		CreatedAt:   resource.CreatedAt,
-		UpdatedAt:   resource.UpdatedAt,
+		ID:             resource.ID,
+		OrganizationID: resource.OrganizationID,
+		Name:           resource.Name,
+		Description:    resource.Description,
+		Status:         resource.Status,
+		Version:        resource.Version,
+		CreatedByID:    resource.CreatedByID,
+		CreatedAt:      resource.CreatedAt,
+		UpdatedAt:      resource.UpdatedAt,
+	}
+}
+
+func toResourceShareResponse(share *models.ResourceShare) dto.ResourceShareResponse {
+	resp := dto.ResourceShareResponse{
+		ID:          share.ID,
+		UserID:      share.UserID,
+		Role:        share.Role,
+		Level:       share.Level,
+		GrantedByID: share.GrantedByID,
+		CreatedAt:   share.CreatedAt,
+		UpdatedAt:   share.UpdatedAt,
+	}
+	if share.User != nil {
+		resp.Email = share.User.Email
 	}
+	return resp
 }
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/listquery"
+	"go-fiber-boilerplate/pkg/policy"
+	"go-fiber-boilerplate/pkg/tenant"
+	"go-fiber-boilerplate/pkg/versioning"
+	"gorm.io/gorm"
+)
+
+// shareMailer records share notifications, or fails them while err is set
+type shareMailer struct {
+	noopEmailService
+	notified []string
+	err      error
+}
+
+func (m *shareMailer) SendResourceShared(email, _ string, _ uint, _ string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.notified = append(m.notified, email)
+	return nil
+}
+
+// createResourceUsers creates the owner, creator and member the resource
+// tests act as
+func createResourceUsers(db *gorm.DB) (owner, creator, member *models.User) {
+	owner = testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	creator = testutil.CreateUserFixture(db, "Creator", "creator@example.com", "password123", "user")
+	member = testutil.CreateUserFixture(db, "Member", "member@example.com", "password123", "user")
+	return owner, creator, member
+}
+
+// visibleIDs lists the resources the actor in ctx sees
+func visibleIDs(t *testing.T, service ResourceService, ctx context.Context) []uint {
+	t.Helper()
+	query, err := ResourceListSpec.Parse(map[string]string{}, 0)
+	testutil.AssertNoError(t, err)
+	resources, _, err := service.ListResources(ctx, query, 1, 10)
+	testutil.AssertNoError(t, err)
+	ids := make([]uint, len(resources))
+	for i, resource := range resources {
+		ids[i] = resource.ID
+	}
+	return ids
+}
+
+func TestListResourcesByCursorPagesVisibleResources(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewResourceService(db, NewNoopEmailService(), ResourcePolicy{}, false)
+	owner, creator, member := createResourceUsers(db)
+	org := testutil.CreateOrganizationWithMembers(db, "acme", owner, creator, member)
+	other := testutil.CreateOrganizationWithMembers(db, "globex", owner, member)
+	var want []uint
+	for _, name := range []string{"Echo", "Bravo", "Alpha", "Bravo", "Delta"} {
+		want = append(want, testutil.CreateResourceFixture(db, org.ID, member.ID, name).ID)
+	}
+	want = []uint{want[2], want[1], want[3], want[4], want[0]}
+	// Neither another creator's resource nor another organization's is listed
+	testutil.CreateResourceFixture(db, org.ID, creator.ID, "Charlie")
+	testutil.CreateResourceFixture(db, other.ID, member.ID, "Alpha")
+	ctx := testutil.ActAs(db, org.ID, member.ID)
+
+	var ids []uint
+	var pages []*listquery.Page
+	params := map[string]string{listquery.CursorParam: "", listquery.SortParam: "name"}
+	for {
+		query, err := ResourceListSpec.Parse(params, member.ID)
+		testutil.AssertNoError(t, err)
+		resources, page, err := service.ListResourcesByCursor(ctx, query, 2, true)
+		testutil.AssertNoError(t, err)
+		testutil.AssertEqual(t, int64(5), *page.Total)
+		for _, resource := range resources {
+			ids = append(ids, resource.ID)
+		}
+		pages = append(pages, page)
+		if page.NextCursor == "" {
+			break
+		}
+		params[listquery.CursorParam] = page.NextCursor
+	}
+	testutil.AssertEqual(t, want, ids)
+	testutil.AssertLen(t, pages, 3)
+
+	params[listquery.CursorParam] = pages[2].PrevCursor
+	query, err := ResourceListSpec.Parse(params, member.ID)
+	testutil.AssertNoError(t, err)
+	resources, _, err := service.ListResourcesByCursor(ctx, query, 2, false)
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, resources, 2)
+	testutil.AssertEqual(t, want[2], resources[0].ID)
+}
+
+func TestResourcePolicyHidesOrForbids(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewResourceService(db, NewNoopEmailService(), ResourcePolicy{}, false)
+	owner, creator, member := createResourceUsers(db)
+	org := testutil.CreateOrganizationWithMembers(db, "acme", owner, creator, member)
+	resource := testutil.CreateResourceFixture(db, org.ID, creator.ID, "Roadmap")
+	name := "Renamed"
+	update := &dto.UpdateResourceRequest{Name: &name}
+
+	// Without a share the resource does not exist for the member
+	_, err := service.GetResource(testutil.ActAs(db, org.ID, member.ID), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceNotFound), "view without share: %v", err)
+	_, err = service.UpdateResource(testutil.ActAs(db, org.ID, member.ID), resource.ID, update)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceNotFound), "update without share: %v", err)
+
+	// A viewer sees it, so changes are forbidden rather than hidden
+	share := &models.ResourceShare{ResourceID: resource.ID, UserID: &member.ID, Level: models.ShareLevelViewer}
+	testutil.AssertNoError(t, db.WithContext(testutil.ActAs(db, org.ID, creator.ID)).Create(share).Error)
+	_, err = service.GetResource(testutil.ActAs(db, org.ID, member.ID), resource.ID)
+	testutil.AssertNoError(t, err)
+	_, err = service.UpdateResource(testutil.ActAs(db, org.ID, member.ID), resource.ID, update)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceForbidden), "update as viewer: %v", err)
+	err = service.DeleteResource(testutil.ActAs(db, org.ID, member.ID), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceForbidden), "delete as viewer: %v", err)
+
+	// Owners do everything, without a context actor nothing is allowed
+	_, err = service.UpdateResource(testutil.ActAs(db, org.ID, owner.ID), resource.ID, update)
+	testutil.AssertNoError(t, err)
+	_, err = service.GetResource(tenant.WithOrganization(context.Background(), org.ID), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceNotFound), "no actor: %v", err)
+}
+
+func TestResourcePolicyHideDenied(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewResourceService(db, NewNoopEmailService(), ResourcePolicy{}, true)
+	owner, creator, member := createResourceUsers(db)
+	org := testutil.CreateOrganizationWithMembers(db, "acme", owner, creator, member)
+	resource := testutil.CreateResourceFixture(db, org.ID, creator.ID, "Roadmap")
+	share := &models.ResourceShare{ResourceID: resource.ID, UserID: &member.ID, Level: models.ShareLevelViewer}
+	testutil.AssertNoError(t, db.WithContext(testutil.ActAs(db, org.ID, creator.ID)).Create(share).Error)
+
+	_, err := service.GetResource(testutil.ActAs(db, org.ID, member.ID), resource.ID)
+	testutil.AssertNoError(t, err)
+	err = service.DeleteResource(testutil.ActAs(db, org.ID, member.ID), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceNotFound), "delete as viewer with hidden denials: %v", err)
+}
+
+func TestResourcePolicyActions(t *testing.T) {
+	resource := &models.Resource{CreatedByID: 1}
+	member := policy.Actor{UserID: 2, Roles: []string{models.OrganizationRoleMember}}
+	admin := policy.Actor{UserID: 3, Roles: []string{models.OrganizationRoleAdmin}}
+	for _, tc := range []struct {
+		actor  policy.Actor
+		share  string
+		action policy.Action
+		want   bool
+	}{
+		{policy.Actor{UserID: 1}, "", policy.Share, true},
+		{member, "", policy.View, false},
+		{member, models.ShareLevelViewer, policy.View, true},
+		{member, models.ShareLevelViewer, policy.Update, false},
+		{member, models.ShareLevelEditor, policy.Update, true},
+		{member, models.ShareLevelEditor, policy.Delete, false},
+		{member, models.ShareLevelEditor, policy.Share, false},
+		{admin, "", policy.Delete, true},
+	} {
+		got := ResourcePolicy{}.Allow(tc.actor, tc.action, &ResourceAccess{Resource: resource, Share: tc.share})
+		testutil.AssertEqual(t, tc.want, got, "user %d with share %q: %s", tc.actor.UserID, tc.share, tc.action)
+	}
+}
+
+func TestShareGrantsVisibility(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	mailer := &shareMailer{}
+	service := NewResourceService(db, mailer, ResourcePolicy{}, false)
+	owner, creator, member := createResourceUsers(db)
+	org := testutil.CreateOrganizationWithMembers(db, "acme", owner, creator, member)
+	resource := testutil.CreateResourceFixture(db, org.ID, creator.ID, "Roadmap")
+	asCreator, asMember := testutil.ActAs(db, org.ID, creator.ID), testutil.ActAs(db, org.ID, member.ID)
+	testutil.AssertLen(t, visibleIDs(t, service, asMember), 0)
+	testutil.AssertEqual(t, []uint{resource.ID}, visibleIDs(t, service, testutil.ActAs(db, org.ID, owner.ID)))
+
+	_, err := service.Share(asCreator, resource.ID, &dto.ShareResourceRequest{Email: "creator@example.com", Level: models.ShareLevelViewer})
+	testutil.AssertTrue(t, errors.Is(err, ErrShareWithCreator), "share with creator: %v", err)
+	_, err = service.Share(asCreator, resource.ID, &dto.ShareResourceRequest{Email: "stranger@example.com", Level: models.ShareLevelViewer})
+	testutil.AssertTrue(t, errors.Is(err, ErrMemberNotFound), "share with non-member: %v", err)
+
+	share, err := service.Share(asCreator, resource.ID, &dto.ShareResourceRequest{Email: " Member@example.com ", Level: models.ShareLevelViewer})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, "member@example.com", share.Email)
+	testutil.AssertEqual(t, []string{"member@example.com"}, mailer.notified)
+	testutil.AssertEqual(t, []uint{resource.ID}, visibleIDs(t, service, asMember))
+
+	// Sharing again changes the level of the same grant
+	upgraded, err := service.Share(asCreator, resource.ID, &dto.ShareResourceRequest{Email: "member@example.com", Level: models.ShareLevelEditor})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, share.ID, upgraded.ID)
+	name := "Edited by member"
+	_, err = service.UpdateResource(asMember, resource.ID, &dto.UpdateResourceRequest{Name: &name})
+	testutil.AssertNoError(t, err)
+
+	// Only the creator and organization admins manage shares
+	_, err = service.ListShares(asMember, resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceForbidden), "list shares as editor: %v", err)
+	testutil.AssertNoError(t, service.RevokeShare(asCreator, resource.ID, share.ID))
+	testutil.AssertLen(t, visibleIDs(t, service, asMember), 0)
+	err = service.RevokeShare(asCreator, resource.ID, share.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrShareNotFound), "revoke twice: %v", err)
+}
+
+func TestShareWithRoleAndFailedNotification(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	mailer := &shareMailer{err: errors.New("smtp unavailable")}
+	service := NewResourceService(db, mailer, ResourcePolicy{}, false)
+	owner, creator, member := createResourceUsers(db)
+	org := testutil.CreateOrganizationWithMembers(db, "acme", owner, creator, member)
+	resource := testutil.CreateResourceFixture(db, org.ID, creator.ID, "Roadmap")
+
+	// A failed notification does not undo the grant
+	_, err := service.Share(testutil.ActAs(db, org.ID, creator.ID), resource.ID, &dto.ShareResourceRequest{Role: models.OrganizationRoleMember, Level: models.ShareLevelViewer})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []uint{resource.ID}, visibleIDs(t, service, testutil.ActAs(db, org.ID, member.ID)))
+
+	shares, err := service.ListShares(testutil.ActAs(db, org.ID, owner.ID), resource.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, shares, 1)
+	testutil.AssertEqual(t, models.OrganizationRoleMember, *shares[0].Role)
+}
+
+func TestResourceUpdateAndDeleteHonourIfMatch(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewResourceService(db, NewNoopEmailService(), ResourcePolicy{}, false)
+	creator := testutil.CreateStandardUserFixture(db)
+	org := testutil.CreateOrganizationFixture(db, "acme", creator.ID)
+	resource := testutil.CreateResourceFixture(db, org.ID, creator.ID, "Roadmap")
+	ifMatch := func(version uint) context.Context {
+		return versioning.WithPrecondition(testutil.ActAs(db, org.ID, creator.ID), versioning.Precondition{Versions: []uint{version}})
+	}
+	name := "Renamed"
+	update := &dto.UpdateResourceRequest{Name: &name}
+
+	current, err := service.GetResource(testutil.ActAs(db, org.ID, creator.ID), resource.ID)
+	testutil.AssertNoError(t, err)
+	_, err = service.UpdateResource(ifMatch(current.Version+1), resource.ID, update)
+	testutil.AssertTrue(t, errors.Is(err, versioning.ErrPreconditionFailed), "stale If-Match on update: %v", err)
+
+	updated, err := service.UpdateResource(ifMatch(current.Version), resource.ID, update)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, current.Version+1, updated.Version)
+	testutil.AssertEqual(t, name, updated.Name)
+
+	err = service.DeleteResource(ifMatch(current.Version), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, versioning.ErrPreconditionFailed), "stale If-Match on delete: %v", err)
+	testutil.AssertNoError(t, service.DeleteResource(ifMatch(updated.Version), resource.ID))
+	_, err = service.GetResource(testutil.ActAs(db, org.ID, creator.ID), resource.ID)
+	testutil.AssertTrue(t, errors.Is(err, ErrResourceNotFound), "deleted resource: %v", err)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultRole is assigned to users created through registration and OAuth
+const defaultRole = "user"
+
+var ErrUnknownRole = errors.New("unknown role")
+
+// RoleService resolves the permissions a user holds through their roles and
+// manages role assignments. Resolved permissions are cached per user until
+// Invalidate is called or the cache TTL passes.
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	Permissions(ctx context.Context, userID uint) ([]string, error)
+	SetUserRoles(tx *gorm.DB, userID uint, names []string) error
+	Invalidate(ctx context.Context, userID uint)
+}
+
+type roleService struct {
+	db    *gorm.DB
+	cache *cache.Client
+}
+
+func NewRoleService(db *gorm.DB, cacheClient *cache.Client) RoleService {
+	return &roleService{db: db, cache: cacheClient}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	var roles []models.Role
+	if err := s.db.WithContext(ctx).Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("permissions.name")
+	}).Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	out := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Name)
+		}
+		out = append(out, dto.RoleResponse{
+			ID:          role.ID,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		})
+	}
+	return out, nil
+}
+
+// Permissions returns the sorted, distinct permissions of all the user's roles
+func (s *roleService) Permissions(ctx context.Context, userID uint) ([]string, error) {
+	var permissions []string
+	if s.cache.GetJSON(ctx, permissionsKey(userID), &permissions) {
+		return permissions, nil
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Distinct().Order("permissions.name").
+		Pluck("permissions.name", &permissions).Error; err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	s.cache.SetJSON(ctx, permissionsKey(userID), permissions, 0)
+	return permissions, nil
+}
+
+// SetUserRoles replaces the user's roles inside tx. Names are matched case
+// insensitively; an unknown name fails with ErrUnknownRole and changes
+// nothing. Call Invalidate once the transaction has committed.
+func (s *roleService) SetUserRoles(tx *gorm.DB, userID uint, names []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRole{}).Error; err != nil {
+		return err
+	}
+	return assignRoles(tx, userID, names...)
+}
+
+func (s *roleService) Invalidate(ctx context.Context, userID uint) {
+	s.cache.Delete(ctx, permissionsKey(userID))
+}
+
+// assignRoles grants the named roles to a user inside tx
+func assignRoles(tx *gorm.DB, userID uint, names ...string) error {
+	names = normalizeRoleNames(names)
+	if len(names) == 0 {
+		return nil
+	}
+	var roles []models.Role
+	if err := tx.Where("name IN ?", names).Find(&roles).Error; err != nil {
+		return err
+	}
+	if len(roles) != len(names) {
+		for _, name := range names {
+			if !slices.ContainsFunc(roles, func(role models.Role) bool { return role.Name == name }) {
+				return fmt.Errorf("%w: %s", ErrUnknownRole, name)
+			}
+		}
+	}
+	assignments := make([]models.UserRole, 0, len(roles))
+	for _, role := range roles {
+		assignments = append(assignments, models.UserRole{UserID: userID, RoleID: role.ID})
+	}
+	return tx.Create(&assignments).Error
+}
+
+// loadRoleNames returns the sorted names of the user's roles
+func loadRoleNames(db *gorm.DB, userID uint) ([]string, error) {
+	names := []string{}
+	err := db.Model(&models.Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Pluck("roles.name", &names).Error
+	return names, err
+}
+
+// roleNames returns the sorted names of preloaded roles
+func roleNames(roles []models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func normalizeRoleNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func permissionsKey(userID uint) string {
+	return "user:permissions:" + strconv.FormatUint(uint64(userID), 10)
+}
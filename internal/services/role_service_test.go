@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+// recordingRoleService records which users' cached permissions were dropped
+type recordingRoleService struct {
+	RoleService
+	invalidated []uint
+}
+
+func (s *recordingRoleService) Invalidate(ctx context.Context, userID uint) {
+	s.invalidated = append(s.invalidated, userID)
+	s.RoleService.Invalidate(ctx, userID)
+}
+
+func TestRolePermissionsAreDistinctAndSorted(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewRoleService(db, nil)
+	testutil.CreateRoleFixture(db, "editor", "resources:write", "resources:read")
+	testutil.CreateRoleFixture(db, "auditor", "audit:read", "resources:read")
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "editor")
+	db.Create(&models.UserRole{UserID: user.ID, RoleID: testutil.CreateRoleFixture(db, "auditor").ID})
+
+	permissions, err := service.Permissions(context.Background(), user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"audit:read", "resources:read", "resources:write"}, permissions)
+
+	permissions, err = service.Permissions(context.Background(), 9999)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{}, permissions)
+}
+
+func TestAdminSetRolesChangesPermissions(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	roles := &recordingRoleService{RoleService: NewRoleService(db, nil)}
+	versions := NewTokenVersionService(db, nil)
+	service := NewAdminUserService(db, NewNoopEmailService(), NewSessionService(db, nil), versions, roles)
+	admin := testutil.CreateAdminUserFixture(db)
+	user := testutil.CreateStandardUserFixture(db)
+	ctx := context.Background()
+
+	_, err := service.SetRoles(admin.ID, admin.ID, []string{"user"})
+	testutil.AssertTrue(t, errors.Is(err, ErrCannotModifySelf), "own roles: %v", err)
+	_, err = service.SetRoles(admin.ID, user.ID, []string{"admin", "superuser"})
+	testutil.AssertTrue(t, errors.Is(err, ErrUnknownRole), "unknown role: %v", err)
+	names, err := loadRoleNames(db, user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"user"}, names, "a failed change keeps the roles")
+
+	updated, err := service.SetRoles(admin.ID, user.ID, []string{" Admin ", "user"})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"admin", "user"}, updated.Roles)
+	testutil.AssertEqual(t, []uint{user.ID}, roles.invalidated)
+	permissions, err := roles.Permissions(ctx, user.ID)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, []string{"resources:read", "resources:write", "users:admin", "users:read"}, permissions)
+	err = versions.Check(ctx, user.ID, user.TokenVersion)
+	testutil.AssertTrue(t, errors.Is(err, ErrTokenVersionMismatch), "tokens with the old roles: %v", err)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrEmptySearch = errors.New("search query has no words")
+
+// maxSearchTerms bounds the words of one search query
+const maxSearchTerms = 16
+
+// Highlights are marked with control characters, so the text can be HTML
+// escaped before the marks become <mark> tags
+const (
+	markStart = "\x02"
+	markEnd   = "\x03"
+)
+
+var highlightMarks = strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>")
+
+// SearchService runs ranked full-text searches over the resources the actor
+// in ctx may view. Every word of the query must match; names weigh more than
+// descriptions.
+type SearchService interface {
+	SearchResources(ctx context.Context, query string, page, limit int) ([]dto.ResourceSearchResult, int64, error)
+}
+
+// NewSearchService returns the implementation for the database driver: the
+// search_vector column on Postgres, or the resources_fts FTS5 table on SQLite
+func NewSearchService(db *gorm.DB) SearchService {
+	if db.Dialector.Name() == "sqlite" {
+		return &sqliteSearchService{db: db}
+	}
+	return &postgresSearchService{db: db}
+}
+
+type postgresSearchService struct {
+	db *gorm.DB
+}
+
+func (s *postgresSearchService) SearchResources(ctx context.Context, query string, page, limit int) ([]dto.ResourceSearchResult, int64, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, 0, ErrEmptySearch
+	}
+	text := strings.Join(terms, " ")
+	nameOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", HighlightAll=true`, markStart, markEnd)
+	snippetOptions := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=24, MinWords=12`, markStart, markEnd)
+	db := visibleResources(ctx, s.db.WithContext(ctx).Model(&models.Resource{})).
+		Where("resources.search_vector @@ plainto_tsquery('english', ?)", text)
+	return findSearchResults(db, page, limit,
+		"resources.*, ts_rank_cd(resources.search_vector, plainto_tsquery('english', ?)) AS search_rank, "+
+			"ts_headline('english', resources.name, plainto_tsquery('english', ?), ?) AS name_highlight, "+
+			"ts_headline('english', COALESCE(resources.description, ''), plainto_tsquery('english', ?), ?) AS description_highlight",
+		text, text, nameOptions, text, snippetOptions)
+}
+
+type sqliteSearchService struct {
+	db *gorm.DB
+}
+
+func (s *sqliteSearchService) SearchResources(ctx context.Context, query string, page, limit int) ([]dto.ResourceSearchResult, int64, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, 0, ErrEmptySearch
+	}
+	// Quoted terms keep FTS5 query syntax out of user input
+	match := `"` + strings.Join(terms, `" "`) + `"`
+	db := visibleResources(ctx, s.db.WithContext(ctx).Model(&models.Resource{})).
+		Joins("JOIN resources_fts ON resources_fts.rowid = resources.id").
+		Where("resources_fts MATCH ?", match)
+	return findSearchResults(db, page, limit,
+		"resources.*, -bm25(resources_fts, 10.0, 1.0) AS search_rank, "+
+			"highlight(resources_fts, 0, ?, ?) AS name_highlight, "+
+			"snippet(resources_fts, 1, ?, ?, '…', 24) AS description_highlight",
+		markStart, markEnd, markStart, markEnd)
+}
+
+type searchRow struct {
+	models.Resource
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// findSearchResults counts the matches of db, then loads a page of them best
+// first. columns must select search_rank, name_highlight and
+// description_highlight next to the resource.
+func findSearchResults(db *gorm.DB, page, limit int, columns string, args ...interface{}) ([]dto.ResourceSearchResult, int64, error) {
+	var total int64
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []searchRow
+	if err := db.Select(columns, args...).Order("search_rank DESC").Order("resources.id").
+		Offset((page - 1) * limit).Limit(limit).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]dto.ResourceSearchResult, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, dto.ResourceSearchResult{
+			ResourceResponse: toResourceResponse(&row.Resource),
+			Highlight: dto.ResourceHighlight{
+				Name:        highlightMarks.Replace(html.EscapeString(row.NameHighlight)),
+				Description: highlightMarks.Replace(html.EscapeString(row.DescriptionHighlight)),
+			},
+		})
+	}
+	return out, total, nil
+}
+
+// searchTerms splits a query into lower case words, dropping punctuation and
+// search syntax
+func searchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
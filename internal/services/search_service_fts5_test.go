@@ -0,0 +1,105 @@
+//go:build sqlite_fts5
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-fiber-boilerplate/assets"
+	"go-fiber-boilerplate/internal/database"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/policy"
+	"go-fiber-boilerplate/pkg/tenant"
+	"go-fiber-boilerplate/pkg/utils"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupMigratedDB opens a private in-memory database built by the SQL
+// migrations, since only they create the FTS5 table
+func setupMigratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	utils.InitLogger()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	testutil.AssertNoError(t, err)
+	// Every connection to file::memory: opens another database
+	sqlDB, err := db.DB()
+	testutil.AssertNoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	testutil.AssertNoError(t, db.Use(tenant.Plugin{}))
+	testutil.AssertNoError(t, database.MigrateFromFS(db, assets.MigrationsFS))
+	return db
+}
+
+// The SQLite search reads an FTS5 table, so this file needs the sqlite_fts5
+// build tag that make test sets.
+func TestSQLiteSearchRanksAndHighlights(t *testing.T) {
+	db := setupMigratedDB(t)
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	ctx := policy.WithActor(tenant.WithOrganization(context.Background(), org.ID),
+		policy.Actor{UserID: owner.ID, Roles: []string{models.OrganizationRoleOwner}})
+
+	described := testutil.CreateResourceFixture(db, org.ID, owner.ID, "Quarterly report")
+	description := "Revenue <b>forecast</b> for the sales team"
+	db.WithContext(ctx).Model(described).Update("description", description)
+	named := testutil.CreateResourceFixture(db, org.ID, owner.ID, "Forecast model")
+	testutil.CreateResourceFixture(db, org.ID, owner.ID, "Unrelated")
+
+	service := NewSearchService(db)
+	if _, ok := service.(*sqliteSearchService); !ok {
+		t.Fatalf("NewSearchService() = %T, want *sqliteSearchService", service)
+	}
+
+	results, total, err := service.SearchResources(ctx, "forecasts", 1, 10)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int64(2), total)
+	testutil.AssertLen(t, results, 2)
+	// A match in the name outranks one in the description
+	testutil.AssertEqual(t, named.ID, results[0].ID)
+	testutil.AssertEqual(t, "<mark>Forecast</mark> model", results[0].Highlight.Name)
+	testutil.AssertEqual(t, described.ID, results[1].ID)
+	testutil.AssertContains(t, results[1].Highlight.Description, "&lt;b&gt;<mark>forecast</mark>&lt;/b&gt;")
+
+	// Triggers keep the index current on update and delete
+	db.WithContext(ctx).Model(named).Update("name", "Budget model")
+	db.WithContext(ctx).Delete(described)
+	results, total, err = service.SearchResources(ctx, "forecast", 1, 10)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int64(0), total)
+	testutil.AssertLen(t, results, 0)
+	_, total, err = service.SearchResources(ctx, "budget", 1, 10)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int64(1), total)
+
+	_, _, err = service.SearchResources(ctx, `"*" -`, 1, 10)
+	testutil.AssertTrue(t, errors.Is(err, ErrEmptySearch))
+}
+
+func TestSQLiteSearchOnlyFindsVisibleResources(t *testing.T) {
+	db := setupMigratedDB(t)
+	owner := testutil.CreateUserFixture(db, "Owner", "owner@example.com", "password123", "user")
+	member := testutil.CreateUserFixture(db, "Member", "member@example.com", "password123", "user")
+	org := testutil.CreateOrganizationFixture(db, "acme", owner.ID)
+	other := testutil.CreateOrganizationFixture(db, "other", owner.ID)
+	testutil.CreateMembershipFixture(db, org.ID, member.ID, models.OrganizationRoleMember)
+
+	testutil.CreateResourceFixture(db, org.ID, owner.ID, "Roadmap owner")
+	testutil.CreateResourceFixture(db, org.ID, member.ID, "Roadmap member")
+	testutil.CreateResourceFixture(db, other.ID, owner.ID, "Roadmap elsewhere")
+
+	ctx := policy.WithActor(tenant.WithOrganization(context.Background(), org.ID),
+		policy.Actor{UserID: member.ID, Roles: []string{models.OrganizationRoleMember}})
+	results, total, err := NewSearchService(db).SearchResources(ctx, "roadmap", 1, 10)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int64(1), total)
+	testutil.AssertEqual(t, "Roadmap member", results[0].Name)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/policy"
+	"go-fiber-boilerplate/pkg/tenant"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder is a gorm logger keeping the SQL of every statement
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// dryRunPostgres returns a Postgres session that renders statements into the
+// recorder without connecting to a server
+func dryRunPostgres(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=dry_run sslmode=disable"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               recorder,
+	})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, db.Use(tenant.Plugin{}))
+	return db, recorder
+}
+
+func TestSearchTerms(t *testing.T) {
+	testutil.AssertEqual(t, []string{"q3", "forecast", "über"}, searchTerms(` Q3 "forecast"* -Über `))
+	testutil.AssertLen(t, searchTerms(`"*" - ()`), 0)
+	testutil.AssertLen(t, searchTerms("a b c d e f g h i j k l m n o p q r s"), maxSearchTerms)
+}
+
+func TestPostgresSearchUsesTSVector(t *testing.T) {
+	db, recorder := dryRunPostgres(t)
+	service := NewSearchService(db)
+	if _, ok := service.(*postgresSearchService); !ok {
+		t.Fatalf("NewSearchService() = %T, want *postgresSearchService", service)
+	}
+	ctx := policy.WithActor(tenant.WithOrganization(context.Background(), 7),
+		policy.Actor{UserID: 3, Roles: []string{models.OrganizationRoleMember}})
+
+	_, _, err := service.SearchResources(ctx, `"*" -`, 1, 10)
+	testutil.AssertTrue(t, errors.Is(err, ErrEmptySearch), "query without words: %v", err)
+	testutil.AssertLen(t, recorder.statements, 0)
+
+	_, _, err = service.SearchResources(ctx, "Forecasts, Q3!", 2, 10)
+	testutil.AssertNoError(t, err)
+	testutil.AssertLen(t, recorder.statements, 2)
+	count, page := recorder.statements[0], recorder.statements[1]
+	for _, sql := range recorder.statements {
+		testutil.AssertContains(t, sql, `resources.search_vector @@ plainto_tsquery('english', 'forecasts q3')`)
+		testutil.AssertContains(t, sql, `"resources"."organization_id" = 7`)
+		testutil.AssertContains(t, sql, `resources.created_by_id = 3`)
+	}
+	testutil.AssertContains(t, count, "SELECT count(*)")
+	testutil.AssertContains(t, page, "ts_rank_cd(resources.search_vector, plainto_tsquery('english', 'forecasts q3')) AS search_rank")
+	testutil.AssertContains(t, page, "ts_headline('english', resources.name, plainto_tsquery('english', 'forecasts q3'), 'StartSel=\"\x02\", StopSel=\"\x03\", HighlightAll=true') AS name_highlight")
+	testutil.AssertContains(t, page, "ORDER BY search_rank DESC,resources.id LIMIT 10 OFFSET 10")
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionRevoked     = errors.New("session has been revoked or expired")
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+const (
+	SessionRevokedReuse          = "reuse_detected"
+	SessionRevokedLogout         = "logout"
+	SessionRevokedLogoutAll      = "logout_all"
+	SessionRevokedByUser         = "revoked_by_user"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedRoleChange     = "role_change"
+	SessionRevokedDeactivated    = "deactivated"
+	SessionRevokedUserDeleted    = "user_deleted"
+)
+
+const maxUserAgentLength = 512
+
+// ClientInfo describes the device a login or refresh came from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionService interface {
+	Create(userID uint, expiry time.Duration, client ClientInfo) (*models.UserSession, error)
+	Rotate(sessionID uint, presentedTokenID string, expiry time.Duration, client ClientInfo) (*models.UserSession, error)
+	ListActive(userID, currentSessionID uint) ([]dto.SessionResponse, error)
+	Revoke(sessionID uint, reason string) error
+	RevokeForUser(userID, sessionID uint, reason string) error
+	RevokeAllForUser(userID uint, reason string) error
+	RevokeOthers(userID, keepSessionID uint, reason string) error
+}
+
+type sessionService struct {
+	db       *gorm.DB
+	denylist *cache.TokenDenylist
+}
+
+func NewSessionService(db *gorm.DB, denylist *cache.TokenDenylist) SessionService {
+	return &sessionService{db: db, denylist: denylist}
+}
+
+func (s *sessionService) Create(userID uint, expiry time.Duration, client ClientInfo) (*models.UserSession, error) {
+	now := time.Now()
+	session := &models.UserSession{
+		UserID:         userID,
+		RefreshTokenID: jwt.NewTokenID(),
+		ExpiresAt:      now.Add(expiry),
+		UserAgent:      truncate(client.UserAgent, maxUserAgentLength),
+		IPAddress:      client.IPAddress,
+		LastSeenAt:     &now,
+	}
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Rotate swaps the session's refresh token ID for a new one. Presenting a token
+// ID that is no longer current means the token was already rotated, so the whole
+// session is revoked to cut off whoever holds the other copy.
+func (s *sessionService) Rotate(sessionID uint, presentedTokenID string, expiry time.Duration, client ClientInfo) (*models.UserSession, error) {
+	session, err := s.find(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, ErrSessionRevoked
+	}
+	if session.RefreshTokenID != presentedTokenID {
+		return nil, s.revokeForReuse(session)
+	}
+
+	nextTokenID := jwt.NewTokenID()
+	expiresAt := now.Add(expiry)
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_id = ? AND revoked_at IS NULL", session.ID, presentedTokenID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": nextTokenID,
+			"expires_at":       expiresAt,
+			"rotated_at":       now,
+			"last_seen_at":     now,
+			"user_agent":       truncate(client.UserAgent, maxUserAgentLength),
+			"ip_address":       client.IPAddress,
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Another request rotated this token first.
+		return nil, s.revokeForReuse(session)
+	}
+
+	session.RefreshTokenID = nextTokenID
+	session.ExpiresAt = expiresAt
+	session.RotatedAt = &now
+	session.LastSeenAt = &now
+	session.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+	session.IPAddress = client.IPAddress
+	session.UpdatedAt = now
+	return session, nil
+}
+
+// ListActive returns the user's unrevoked, unexpired sessions, most recently
+// seen first, marking the one the request was made with.
+func (s *sessionService) ListActive(userID, currentSessionID uint) ([]dto.SessionResponse, error) {
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").Order("id DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	resp := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		resp[i] = dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		}
+	}
+	return resp, nil
+}
+
+// Revoke ends a session and denylists its outstanding access tokens.
+func (s *sessionService) Revoke(sessionID uint, reason string) error {
+	now := time.Now()
+	result := s.db.Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	s.denylistSessions(sessionID)
+	return nil
+}
+
+// RevokeForUser revokes one of the user's sessions, such as a lost device
+func (s *sessionService) RevokeForUser(userID, sessionID uint, reason string) error {
+	var count int64
+	if err := s.db.Model(&models.UserSession{}).Where("id = ? AND user_id = ?", sessionID, userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrSessionNotFound
+	}
+	return s.Revoke(sessionID, reason)
+}
+
+func (s *sessionService) RevokeAllForUser(userID uint, reason string) error {
+	return s.RevokeOthers(userID, 0, reason)
+}
+
+// RevokeOthers revokes every session of the user except keepSessionID
+func (s *sessionService) RevokeOthers(userID, keepSessionID uint, reason string) error {
+	var sessionIDs []uint
+	if err := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL AND id <> ?", userID, keepSessionID).
+		Pluck("id", &sessionIDs).Error; err != nil {
+		return err
+	}
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	if err := s.db.Model(&models.UserSession{}).
+		Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		}).Error; err != nil {
+		return err
+	}
+	s.denylistSessions(sessionIDs...)
+	return nil
+}
+
+// denylistSessions blocks access tokens of revoked sessions until the longest
+// possible access token issued for them has expired.
+func (s *sessionService) denylistSessions(sessionIDs ...uint) {
+	until := time.Now().Add(config.AppConfig.JWTExpiry)
+	for _, id := range sessionIDs {
+		s.denylist.RevokeSession(context.Background(), id, until)
+	}
+}
+
+func (s *sessionService) revokeForReuse(session *models.UserSession) error {
+	utils.Log("Security").Warn("Refresh token reuse detected, revoking session", "user_id", session.UserID, "session_id", session.ID)
+	if err := s.Revoke(session.ID, SessionRevokedReuse); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *sessionService) find(sessionID uint) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := s.db.First(&session, sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
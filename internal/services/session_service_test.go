@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+// activeSessionIDs lists the IDs of the user's unrevoked sessions
+func activeSessionIDs(t *testing.T, service SessionService, userID uint) []uint {
+	t.Helper()
+	sessions, err := service.ListActive(userID, 0)
+	testutil.AssertNoError(t, err)
+	ids := make([]uint, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	return ids
+}
+
+func TestSessionRotateRevokesOnReuse(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewSessionService(db, nil)
+	user := testutil.CreateStandardUserFixture(db)
+	session, err := service.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	stolen := session.RefreshTokenID
+
+	rotated, err := service.Rotate(session.ID, stolen, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotEqual(t, stolen, rotated.RefreshTokenID)
+	testutil.AssertNotNil(t, rotated.RotatedAt)
+
+	_, err = service.Rotate(session.ID, stolen, time.Hour, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrRefreshTokenReused), "reused token: %v", err)
+	var stored models.UserSession
+	testutil.AssertNoError(t, db.First(&stored, session.ID).Error)
+	testutil.AssertEqual(t, SessionRevokedReuse, *stored.RevokedReason)
+
+	// The legitimate holder is cut off too
+	_, err = service.Rotate(session.ID, rotated.RefreshTokenID, time.Hour, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrSessionRevoked), "after reuse: %v", err)
+}
+
+func TestSessionRotateRejectsExpiredAndUnknown(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewSessionService(db, nil)
+	user := testutil.CreateStandardUserFixture(db)
+	session, err := service.Create(user.ID, -time.Minute, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	_, err = service.Rotate(session.ID, session.RefreshTokenID, time.Hour, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrSessionRevoked), "expired session: %v", err)
+	_, err = service.Rotate(9999, session.RefreshTokenID, time.Hour, ClientInfo{})
+	testutil.AssertTrue(t, errors.Is(err, ErrSessionNotFound), "unknown session: %v", err)
+}
+
+func TestSessionRevokeForUser(t *testing.T) {
+	db := testutil.NewTestDB(t)
+	service := NewSessionService(db, nil)
+	user := testutil.CreateStandardUserFixture(db)
+	other := testutil.CreateUserFixture(db, "Other", "other@example.com", "password123", "user")
+	session, err := service.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	err = service.RevokeForUser(other.ID, session.ID, SessionRevokedByUser)
+	testutil.AssertTrue(t, errors.Is(err, ErrSessionNotFound), "another user's session: %v", err)
+	testutil.AssertEqual(t, []uint{session.ID}, activeSessionIDs(t, service, user.ID))
+
+	testutil.AssertNoError(t, service.RevokeForUser(user.ID, session.ID, SessionRevokedByUser))
+	testutil.AssertLen(t, activeSessionIDs(t, service, user.ID), 0)
+	err = service.Revoke(session.ID, SessionRevokedLogout)
+	testutil.AssertTrue(t, errors.Is(err, ErrSessionNotFound), "revoked twice: %v", err)
+}
+
+func TestSessionRevokeOthersKeepsCurrent(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	denylist := cache.NewTokenDenylist(nil)
+	service := NewSessionService(db, denylist)
+	user := testutil.CreateStandardUserFixture(db)
+	other := testutil.CreateUserFixture(db, "Other", "other@example.com", "password123", "user")
+	current, err := service.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	sibling, err := service.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	foreign, err := service.Create(other.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	testutil.AssertNoError(t, service.RevokeOthers(user.ID, current.ID, SessionRevokedLogoutAll))
+	testutil.AssertEqual(t, []uint{current.ID}, activeSessionIDs(t, service, user.ID))
+	testutil.AssertTrue(t, denylist.IsRevoked(context.Background(), "", sibling.ID), "revoked session must be denylisted")
+	testutil.AssertFalse(t, denylist.IsRevoked(context.Background(), "", current.ID), "current session must stay valid")
+
+	testutil.AssertNoError(t, service.RevokeAllForUser(user.ID, SessionRevokedPasswordChange))
+	testutil.AssertLen(t, activeSessionIDs(t, service, user.ID), 0)
+	testutil.AssertEqual(t, []uint{foreign.ID}, activeSessionIDs(t, service, other.ID))
+}
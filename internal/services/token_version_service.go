@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go-fiber-boilerplate/internal/cache"
+	"go-fiber-boilerplate/internal/models"
+	"gorm.io/gorm"
+)
+
+// tokenVersionCacheTTL bounds how long a cached version can lag behind the
+// database, e.g. when another instance bumps it
+const tokenVersionCacheTTL = time.Minute
+
+var ErrTokenVersionMismatch = errors.New("token has been invalidated")
+
+// TokenVersionService answers which token version a user's tokens must carry.
+// Lookups are cached so the auth middleware does not hit the database on
+// every request.
+type TokenVersionService interface {
+	Check(ctx context.Context, userID uint, version int) error
+	Bump(tx *gorm.DB, userID uint) error
+	Invalidate(ctx context.Context, userID uint)
+}
+
+type tokenVersionService struct {
+	db    *gorm.DB
+	cache *cache.Client
+}
+
+func NewTokenVersionService(db *gorm.DB, cacheClient *cache.Client) TokenVersionService {
+	return &tokenVersionService{db: db, cache: cacheClient}
+}
+
+// Check returns ErrTokenVersionMismatch when version is not the user's
+// current one, including for deleted users.
+func (s *tokenVersionService) Check(ctx context.Context, userID uint, version int) error {
+	current, err := s.current(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenVersionMismatch
+		}
+		return err
+	}
+	if current != version {
+		return ErrTokenVersionMismatch
+	}
+	return nil
+}
+
+// Bump increments the user's token version inside tx. Call Invalidate once
+// the transaction has committed.
+func (s *tokenVersionService) Bump(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// Invalidate drops the cached version so the next request reads the new one
+func (s *tokenVersionService) Invalidate(ctx context.Context, userID uint) {
+	s.cache.Delete(ctx, tokenVersionKey(userID))
+}
+
+func (s *tokenVersionService) current(ctx context.Context, userID uint) (int, error) {
+	var version int
+	if s.cache.GetJSON(ctx, tokenVersionKey(userID), &version) {
+		return version, nil
+	}
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "token_version").First(&user, userID).Error; err != nil {
+		return 0, err
+	}
+	s.cache.SetJSON(ctx, tokenVersionKey(userID), user.TokenVersion, tokenVersionCacheTTL)
+	return user.TokenVersion, nil
+}
+
+func tokenVersionKey(userID uint) string {
+	return "user:token_version:" + strconv.FormatUint(uint64(userID), 10)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -20,20 +21,28 @@ type UserService interface {
 	GetUserByID(id uint) (*models.User, error)
 	GetUserResponse(user *models.User) *dto.UserResponse
 	UpdateProfile(userID uint, req *dto.UpdateProfileRequest) (*models.User, error)
-	ChangePassword(userID uint, oldPassword, newPassword string) error
+	ChangePassword(userID, currentSessionID uint, req *dto.ChangePasswordRequest) error
 }
 
 type userService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	sessionService SessionService
+	tokenVersions  TokenVersionService
+	passwordPolicy PasswordPolicyService
 }
 
-func NewUserService(db *gorm.DB) UserService {
-	return &userService{db: db}
+func NewUserService(db *gorm.DB, sessionService SessionService, tokenVersions TokenVersionService, passwordPolicy PasswordPolicyService) UserService {
+	return &userService{
+		db:             db,
+		sessionService: sessionService,
+		tokenVersions:  tokenVersions,
+		passwordPolicy: passwordPolicy,
+	}
 }
 
 func (s *userService) GetUserByID(id uint) (*models.User, error) {
 	var user models.User
-	if err := s.db.Preload("Profile").First(&user, id).Error; err != nil {
+	if err := s.db.Preload("Profile").Preload("Roles").First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrUserNotFound
 		}
@@ -43,11 +52,10 @@ func (s *userService) GetUserByID(id uint) (*models.User, error) {
 }
 
 func (s *userService) GetUserResponse(user *models.User) *dto.UserResponse {
-	role := roleString(user.Role)
 	resp := &dto.UserResponse{
 		ID:        user.ID,
 		Email:     user.Email,
-		Role:      role,
+		Roles:     roleNames(user.Roles),
 		IsActive:  user.IsActive,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
@@ -83,7 +91,10 @@ func (s *userService) UpdateProfile(userID uint, req *dto.UpdateProfileRequest)
 	return s.GetUserByID(userID)
 }
 
-func (s *userService) ChangePassword(userID uint, oldPassword, newPassword string) error {
+// ChangePassword replaces the user's password and bumps the token version,
+// which invalidates every token issued so far; the current session has to be
+// issued new tokens. With RevokeOtherSessions the other sessions are revoked.
+func (s *userService) ChangePassword(userID, currentSessionID uint, req *dto.ChangePasswordRequest) error {
 	user, err := s.GetUserByID(userID)
 	if err != nil {
 		return err
@@ -91,15 +102,34 @@ func (s *userService) ChangePassword(userID uint, oldPassword, newPassword strin
 	if user.Password == nil {
 		return ErrNoPasswordSet
 	}
-	if err := utils.VerifyPassword(oldPassword, *user.Password); err != nil {
+	if err := utils.VerifyPassword(req.OldPassword, *user.Password); err != nil {
 		return ErrInvalidPassword
 	}
-	hashedPassword, err := utils.HashPassword(newPassword)
+	if err := s.passwordPolicy.Check(context.Background(), "new_password", req.NewPassword, user); err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
 		return err
 	}
-	return s.db.Model(user).Updates(map[string]interface{}{
-		"password":   hashedPassword,
-		"updated_at": time.Now(),
-	}).Error
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"password":   hashedPassword,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := s.passwordPolicy.Remember(tx, userID, hashedPassword); err != nil {
+			return err
+		}
+		return s.tokenVersions.Bump(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+	s.tokenVersions.Invalidate(context.Background(), userID)
+	if req.RevokeOtherSessions {
+		return s.sessionService.RevokeOthers(userID, currentSessionID, SessionRevokedPasswordChange)
+	}
+	return nil
 }
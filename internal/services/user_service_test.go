@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-boilerplate/config"
+	"go-fiber-boilerplate/internal/dto"
+	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/internal/testutil"
+	"go-fiber-boilerplate/pkg/password"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+func TestChangePasswordInvalidatesOtherTokens(t *testing.T) {
+	config.AppConfig = &config.Config{JWTExpiry: 15 * time.Minute}
+	db := testutil.NewTestDB(t)
+	versions := NewTokenVersionService(db, nil)
+	sessions := NewSessionService(db, nil)
+	service := NewUserService(db, sessions, versions, NewPasswordPolicyService(db, password.NewPolicy(), 0))
+	user := testutil.CreateUserFixture(db, "Jane", "jane@example.com", "password123", "user")
+	current, err := sessions.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+	_, err = sessions.Create(user.ID, time.Hour, ClientInfo{})
+	testutil.AssertNoError(t, err)
+
+	err = service.ChangePassword(user.ID, current.ID, &dto.ChangePasswordRequest{OldPassword: "wrong-password", NewPassword: "new-password-456"})
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidPassword), "wrong old password: %v", err)
+	testutil.AssertNoError(t, versions.Check(context.Background(), user.ID, user.TokenVersion))
+
+	testutil.AssertNoError(t, service.ChangePassword(user.ID, current.ID, &dto.ChangePasswordRequest{OldPassword: "password123", NewPassword: "new-password-456"}))
+	err = versions.Check(context.Background(), user.ID, user.TokenVersion)
+	testutil.AssertTrue(t, errors.Is(err, ErrTokenVersionMismatch), "token issued before the change: %v", err)
+	testutil.AssertNoError(t, versions.Check(context.Background(), user.ID, user.TokenVersion+1))
+
+	// Other sessions are only signed out on request
+	testutil.AssertLen(t, activeSessionIDs(t, sessions, user.ID), 2)
+	testutil.AssertNoError(t, service.ChangePassword(user.ID, current.ID, &dto.ChangePasswordRequest{
+		OldPassword: "new-password-456", NewPassword: "newer-password-789", RevokeOtherSessions: true,
+	}))
+	testutil.AssertEqual(t, []uint{current.ID}, activeSessionIDs(t, sessions, user.ID), "the session that changed the password stays signed in")
+
+	var stored models.User
+	testutil.AssertNoError(t, db.First(&stored, user.ID).Error)
+	testutil.AssertNoError(t, utils.VerifyPassword("newer-password-789", *stored.Password))
+}
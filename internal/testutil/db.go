@@ -2,6 +2,8 @@ package testutil
 
 import (
 	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/tenant"
+	"go-fiber-boilerplate/pkg/utils"
 	"testing"
 
 	"gorm.io/driver/sqlite"
@@ -22,17 +24,50 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		t.Fatalf("Failed to register tenant plugin: %v", err)
+	}
+
 	// Auto-migrate all models
 	err = db.AutoMigrate(
 		&models.User{},
+		&models.Role{},
+		&models.Permission{},
+		&models.UserRole{},
 		&models.UserProfile{},
 		&models.PasswordReset{},
+		&models.PasswordHistory{},
+		&models.EmailVerification{},
+		&models.MagicLink{},
+		&models.UserSession{},
+		&models.UserMFA{},
+		&models.MFARecoveryCode{},
+		&models.UserIdentity{},
+		&models.APIKey{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.OrganizationInvitation{},
 		&models.Resource{},
+		&models.ResourceShare{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
 
+	// Seed the default roles, which migration 013 creates at runtime
+	CreateRoleFixture(db, "admin")
+	CreateRoleFixture(db, "user")
+
+	return db
+}
+
+// NewTestDB returns a database from SetupTestDB that is closed when the test
+// ends, with the logger the services write to initialized
+func NewTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	utils.InitLogger()
+	db := SetupTestDB(t)
+	t.Cleanup(func() { CleanupTestDB(db) })
 	return db
 }
 
@@ -1,9 +1,12 @@
 package testutil
 
 import (
+	"context"
 	"fmt"
 
 	"go-fiber-boilerplate/internal/models"
+	"go-fiber-boilerplate/pkg/policy"
+	"go-fiber-boilerplate/pkg/tenant"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -11,29 +14,64 @@ import (
 func CreateUserFixture(db *gorm.DB, firstName, email, password, role string) *models.User {
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	passwordStr := string(hashedPassword)
-	roleStr := role
 	user := &models.User{
 		Email:               email,
 		Password:            &passwordStr,
 		PasswordIsSetByUser: true,
-		Role:                &roleStr,
 		IsActive:            true,
 	}
 	db.Create(user)
 	db.Create(&models.UserProfile{UserID: user.ID, FirstName: firstName})
+	roleRecord := CreateRoleFixture(db, role)
+	db.Create(&models.UserRole{UserID: user.ID, RoleID: roleRecord.ID})
 	return user
 }
 
-func CreateResourceFixture(db *gorm.DB, userID uint, name string) *models.Resource {
+func CreateResourceFixture(db *gorm.DB, organizationID, userID uint, name string) *models.Resource {
 	resource := &models.Resource{
 		Name:        name,
 		Status:      "active",
 		CreatedByID: userID,
 	}
-	db.Create(resource)
+	db.WithContext(tenant.WithOrganization(context.Background(), organizationID)).Create(resource)
 	return resource
 }
 
+// CreateOrganizationFixture creates an organization owned by ownerID
+func CreateOrganizationFixture(db *gorm.DB, slug string, ownerID uint) *models.Organization {
+	organization := &models.Organization{Name: slug, Slug: slug}
+	db.Create(organization)
+	CreateMembershipFixture(db, organization.ID, ownerID, models.OrganizationRoleOwner)
+	return organization
+}
+
+// CreateOrganizationWithMembers creates an organization owned by owner in
+// which every one of members holds the member role
+func CreateOrganizationWithMembers(db *gorm.DB, slug string, owner *models.User, members ...*models.User) *models.Organization {
+	organization := CreateOrganizationFixture(db, slug, owner.ID)
+	for _, member := range members {
+		CreateMembershipFixture(db, organization.ID, member.ID, models.OrganizationRoleMember)
+	}
+	return organization
+}
+
+func CreateMembershipFixture(db *gorm.DB, organizationID, userID uint, role string) *models.OrganizationMember {
+	member := &models.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role}
+	db.Create(member)
+	return member
+}
+
+// ActAs returns a context that acts for the user inside the organization,
+// holding their membership role there as the policy actor
+func ActAs(db *gorm.DB, organizationID, userID uint) context.Context {
+	actor := policy.Actor{UserID: userID}
+	var member models.OrganizationMember
+	if db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Limit(1).Find(&member).RowsAffected > 0 {
+		actor.Roles = []string{member.Role}
+	}
+	return policy.WithActor(tenant.WithOrganization(context.Background(), organizationID), actor)
+}
+
 func CreateMultipleUserFixtures(db *gorm.DB, count int) []*models.User {
 	users := make([]*models.User, count)
 	for i := 0; i < count; i++ {
@@ -42,10 +80,10 @@ func CreateMultipleUserFixtures(db *gorm.DB, count int) []*models.User {
 	return users
 }
 
-func CreateMultipleResourceFixtures(db *gorm.DB, userID uint, count int) []*models.Resource {
+func CreateMultipleResourceFixtures(db *gorm.DB, organizationID, userID uint, count int) []*models.Resource {
 	resources := make([]*models.Resource, count)
 	for i := 0; i < count; i++ {
-		resources[i] = CreateResourceFixture(db, userID, fmt.Sprintf("Resource %d", i+1))
+		resources[i] = CreateResourceFixture(db, organizationID, userID, fmt.Sprintf("Resource %d", i+1))
 	}
 	return resources
 }
@@ -57,3 +95,23 @@ func CreateAdminUserFixture(db *gorm.DB) *models.User {
 func CreateStandardUserFixture(db *gorm.DB) *models.User {
 	return CreateUserFixture(db, "User", "user@test.com", "user123456", "user")
 }
+
+// CreateRoleFixture returns the named role, creating it on first use. The
+// admin and user roles get the permissions the RBAC migration seeds.
+func CreateRoleFixture(db *gorm.DB, name string, permissions ...string) *models.Role {
+	switch {
+	case len(permissions) > 0:
+	case name == "admin":
+		permissions = []string{"resources:read", "resources:write", "users:read", "users:admin"}
+	case name == "user":
+		permissions = []string{"resources:read", "resources:write"}
+	}
+	role := &models.Role{Name: name}
+	db.Where("name = ?", name).FirstOrCreate(role)
+	for _, name := range permissions {
+		permission := &models.Permission{Name: name}
+		db.Where("name = ?", name).FirstOrCreate(permission)
+		db.Model(role).Association("Permissions").Append(permission)
+	}
+	return role
+}
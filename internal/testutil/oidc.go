@@ -0,0 +1,216 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FakeOIDCUser is the account that consents at the fake provider
+type FakeOIDCUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type fakeAuthRequest struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          FakeOIDCUser
+}
+
+// FakeOIDCProvider is an in-process OpenID Connect provider for integration
+// tests. It serves discovery, JWKS, token, and user info endpoints and
+// enforces client credentials, redirect URI, and PKCE on the token exchange.
+type FakeOIDCProvider struct {
+	Server       *httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]fakeAuthRequest
+	users map[string]FakeOIDCUser
+}
+
+func NewFakeOIDCProvider(t *testing.T) *FakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate provider key: %v", err)
+	}
+	p := &FakeOIDCProvider{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		key:          key,
+		codes:        make(map[string]fakeAuthRequest),
+		users:        make(map[string]FakeOIDCUser),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/jwks", p.handleJWKS)
+	mux.HandleFunc("/token", p.handleToken)
+	mux.HandleFunc("/userinfo", p.handleUserInfo)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+	return p
+}
+
+// Issuer returns the provider's issuer URL
+func (p *FakeOIDCProvider) Issuer() string {
+	return p.Server.URL
+}
+
+// Authorize simulates the user consenting at the authorization URL and returns
+// the code and state the provider would redirect back with.
+func (p *FakeOIDCProvider) Authorize(t *testing.T, authURL string, user FakeOIDCUser) (code, state string) {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != p.ClientID {
+		t.Fatalf("Unexpected client_id %q", query.Get("client_id"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("Authorization request is missing a PKCE challenge")
+	}
+
+	code = randomToken()
+	p.mu.Lock()
+	p.codes[code] = fakeAuthRequest{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		user:          user,
+	}
+	p.mu.Unlock()
+	return code, query.Get("state")
+}
+
+func (p *FakeOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer(),
+		"authorization_endpoint": p.Issuer() + "/authorize",
+		"token_endpoint":         p.Issuer() + "/token",
+		"userinfo_endpoint":      p.Issuer() + "/userinfo",
+		"jwks_uri":               p.Issuer() + "/jwks",
+	})
+}
+
+func (p *FakeOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "fake-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *FakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if r.Form.Get("client_id") != p.ClientID || r.Form.Get("client_secret") != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.Form.Get("code")
+	p.mu.Lock()
+	req, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+	if !ok || req.redirectURI != r.Form.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer(),
+		"aud":            p.ClientID,
+		"sub":            req.user.Subject,
+		"email":          req.user.Email,
+		"email_verified": req.user.EmailVerified,
+		"name":           req.user.Name,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+	if req.nonce != "" {
+		claims["nonce"] = req.nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "fake-key"
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken := randomToken()
+	p.mu.Lock()
+	p.users[accessToken] = req.user
+	p.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (p *FakeOIDCProvider) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	user, ok := p.users[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	p.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
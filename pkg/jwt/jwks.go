@@ -0,0 +1,117 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a JSON Web Key (RFC 7517) holding a public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set as served from /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK encodes a public key as a signing JWK
+func NewJWK(kid, alg string, pub interface{}) (JWK, error) {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: alg}
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(key.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		jwk.X = b64(key.X.FillBytes(make([]byte, size)))
+		jwk.Y = b64(key.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(key)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return jwk, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// PublicKey decodes the JWK into an *rsa.PublicKey, *ecdsa.PublicKey, or ed25519.PublicKey
+func (k JWK) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := unb64(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: invalid modulus: %w", k.Kid, err)
+		}
+		e, err := unb64(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: invalid exponent: %w", k.Kid, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwk %q: exponent too large", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := unb64(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: invalid x: %w", k.Kid, err)
+		}
+		y, err := unb64(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: invalid y: %w", k.Kid, err)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("jwk %q: point is not on curve", k.Kid)
+		}
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := unb64(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %q: invalid Ed25519 key", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwk %q: unsupported key type %q", k.Kid, k.Kty)
+	}
+}
+
+func unb64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
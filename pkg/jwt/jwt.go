@@ -1,112 +1,273 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Claims represents the JWT claims
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFAChallenge proves the password step of a two-step login
+	TokenTypeMFAChallenge = "mfa_challenge"
+	// TokenTypeOAuthState binds an OAuth authorization request to its callback
+	TokenTypeOAuthState = "oauth_state"
+)
+
+// Claims represents the JWT claims. TokenVersion has to match the user's
+// current version; bumping it, as a password change does, invalidates every
+// older token. Roles are informational for clients and services verifying
+// tokens through the JWKS endpoint; permissions are resolved server-side.
+// OrganizationID is the session's active organization, if one was chosen.
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID         uint     `json:"user_id"`
+	Email          string   `json:"email"`
+	EmailVerified  bool     `json:"email_verified"`
+	Roles          []string `json:"roles"`
+	OrganizationID uint     `json:"org,omitempty"`
+	SessionID      uint     `json:"sid,omitempty"`
+	TokenVersion   int      `json:"tv"`
+	TokenType      string   `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
+// AccessTokenClaims are the caller-supplied claims of an access token. The
+// token type, ID and lifetime claims are filled in when the token is signed.
+type AccessTokenClaims struct {
+	UserID         uint
+	Email          string
+	EmailVerified  bool
+	Roles          []string
+	OrganizationID uint
+	SessionID      uint
+	TokenVersion   int
+}
+
 // RefreshClaims represents the refresh token claims
 type RefreshClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID       uint   `json:"user_id"`
+	Email        string `json:"email"`
+	SessionID    uint   `json:"sid"`
+	TokenVersion int    `json:"tv"`
+	TokenType    string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// ChallengeClaims represents an intermediate token issued between login steps
+type ChallengeClaims struct {
+	UserID    uint   `json:"user_id"`
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// OAuthStateClaims carries the PKCE verifier and nonce of an OAuth
+// authorization request. The token is held by the client, never sent to the
+// provider, and must be presented together with the state and code from the
+// callback. UserID is set when an already logged-in user links an account.
+type OAuthStateClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+	Nonce        string `json:"nonce"`
+	UserID       uint   `json:"user_id,omitempty"`
+	TokenType    string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
 // TokenManager handles JWT token creation and validation
 type TokenManager struct {
-	secretKey string
+	keyring *Keyring
 }
 
-// NewTokenManager creates a new token manager
+// NewTokenManager creates a token manager signing with a single HS256 secret.
+// It panics when the secret is empty, since every token would be forgeable.
 func NewTokenManager(secretKey string) *TokenManager {
+	if secretKey == "" {
+		panic("jwt: NewTokenManager requires a non-empty HS256 secret")
+	}
+	keyring, err := NewKeyring(NewHMACKey("", secretKey))
+	if err != nil {
+		panic(fmt.Sprintf("jwt: NewTokenManager: %v", err))
+	}
 	return &TokenManager{
-		secretKey: secretKey,
+		keyring: keyring,
 	}
 }
 
-// GenerateAccessToken generates an access token
-func (tm *TokenManager) GenerateAccessToken(userID uint, email, role string, expiry time.Duration) (string, error) {
+// NewTokenManagerWithKeyring creates a token manager backed by a keyring
+func NewTokenManagerWithKeyring(keyring *Keyring) *TokenManager {
+	return &TokenManager{
+		keyring: keyring,
+	}
+}
+
+// Keyring returns the keys used by the token manager
+func (tm *TokenManager) Keyring() *Keyring {
+	return tm.keyring
+}
+
+// NewTokenID returns a random identifier suitable for the jti claim
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// GenerateAccessToken generates an access token bound to a session
+func (tm *TokenManager) GenerateAccessToken(access AccessTokenClaims, expiry time.Duration) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:         access.UserID,
+		Email:          access.Email,
+		EmailVerified:  access.EmailVerified,
+		Roles:          access.Roles,
+		OrganizationID: access.OrganizationID,
+		SessionID:      access.SessionID,
+		TokenVersion:   access.TokenVersion,
+		TokenType:      TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        NewTokenID(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(tm.secretKey))
+	return tm.sign(claims)
 }
 
-// GenerateRefreshToken generates a refresh token
-func (tm *TokenManager) GenerateRefreshToken(userID uint, email string, expiry time.Duration) (string, error) {
+// GenerateRefreshToken generates a refresh token; tokenID becomes the jti claim
+// and must match the session's current refresh token ID to be accepted.
+func (tm *TokenManager) GenerateRefreshToken(userID uint, email string, sessionID uint, tokenID string, tokenVersion int, expiry time.Duration) (string, error) {
+	now := time.Now()
 	claims := RefreshClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:       userID,
+		Email:        email,
+		SessionID:    sessionID,
+		TokenVersion: tokenVersion,
+		TokenType:    TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(tm.secretKey))
+	return tm.sign(claims)
+}
+
+// GenerateMFAChallengeToken generates a short-lived token that can only be
+// exchanged for a token pair together with a second factor.
+func (tm *TokenManager) GenerateMFAChallengeToken(userID uint, expiry time.Duration) (string, error) {
+	now := time.Now()
+	claims := ChallengeClaims{
+		UserID:    userID,
+		TokenType: TokenTypeMFAChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        NewTokenID(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return tm.sign(claims)
+}
+
+// GenerateOAuthStateToken signs the state of an OAuth authorization request
+func (tm *TokenManager) GenerateOAuthStateToken(claims OAuthStateClaims, expiry time.Duration) (string, error) {
+	now := time.Now()
+	claims.TokenType = TokenTypeOAuthState
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        NewTokenID(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	return tm.sign(claims)
 }
 
 // ValidateAccessToken validates an access token and returns claims
 func (tm *TokenManager) ValidateAccessToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(tm.secretKey), nil
-	})
-
-	if err != nil {
+	if err := tm.parse(tokenString, claims); err != nil {
 		return nil, err
 	}
-
-	if !token.Valid {
-		return nil, fmt.Errorf("invalid token")
+	if claims.TokenType != TokenTypeAccess {
+		return nil, fmt.Errorf("unexpected token type: %q", claims.TokenType)
 	}
-
 	return claims, nil
 }
 
 // ValidateRefreshToken validates a refresh token and returns claims
 func (tm *TokenManager) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
 	claims := &RefreshClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(tm.secretKey), nil
-	})
+	if err := tm.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, fmt.Errorf("unexpected token type: %q", claims.TokenType)
+	}
+	if claims.SessionID == 0 || claims.ID == "" {
+		return nil, fmt.Errorf("refresh token is not bound to a session")
+	}
+	return claims, nil
+}
 
-	if err != nil {
+// ValidateMFAChallengeToken validates an MFA challenge token and returns claims
+func (tm *TokenManager) ValidateMFAChallengeToken(tokenString string) (*ChallengeClaims, error) {
+	claims := &ChallengeClaims{}
+	if err := tm.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeMFAChallenge {
+		return nil, fmt.Errorf("unexpected token type: %q", claims.TokenType)
+	}
+	return claims, nil
+}
+
+// ValidateOAuthStateToken validates an OAuth state token and returns claims
+func (tm *TokenManager) ValidateOAuthStateToken(tokenString string) (*OAuthStateClaims, error) {
+	claims := &OAuthStateClaims{}
+	if err := tm.parse(tokenString, claims); err != nil {
 		return nil, err
 	}
+	if claims.TokenType != TokenTypeOAuthState {
+		return nil, fmt.Errorf("unexpected token type: %q", claims.TokenType)
+	}
+	return claims, nil
+}
+
+func (tm *TokenManager) sign(claims jwt.Claims) (string, error) {
+	key := tm.keyring.Active()
+	token := jwt.NewWithClaims(key.method(), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.signKey)
+}
+
+func (tm *TokenManager) parse(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, tm.keyring.keyFunc)
+
+	if err != nil {
+		return err
+	}
 
 	if !token.Valid {
-		return nil, fmt.Errorf("invalid token")
+		return fmt.Errorf("invalid token")
 	}
 
-	return claims, nil
+	return nil
 }
 
 // ExtractTokenFromHeader extracts token from Authorization: Bearer <token>.
@@ -0,0 +1,231 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a single signing or verification key identified by its kid
+type Key struct {
+	ID        string
+	Algorithm string
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign reports whether the key holds private (or symmetric) key material
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// PublicKey returns the public half of an asymmetric key, or nil for HMAC keys
+func (k *Key) PublicKey() crypto.PublicKey {
+	if k.Algorithm == AlgorithmHS256 {
+		return nil
+	}
+	return k.verifyKey
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// NewHMACKey creates a symmetric HS256 key
+func NewHMACKey(id, secret string) *Key {
+	return &Key{ID: id, Algorithm: AlgorithmHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+}
+
+// ParseKeyPEM parses a PEM encoded private or public key. The algorithm is
+// derived from the key type: RSA -> RS256, P-256 -> ES256, Ed25519 -> EdDSA.
+// Public keys produce verification-only keys.
+func ParseKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM block found", id)
+	}
+
+	if strings.Contains(block.Type, "PRIVATE KEY") {
+		priv, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		alg, err := algorithmFor(priv.Public())
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		return &Key{ID: id, Algorithm: alg, signKey: priv, verifyKey: priv.Public()}, nil
+	}
+
+	var pub interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		pub = parsed
+	case "RSA PUBLIC KEY":
+		parsed, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		pub = parsed
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		pub = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("key %q: unsupported PEM block type %q", id, block.Type)
+	}
+	alg, err := algorithmFor(pub)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+	return &Key{ID: id, Algorithm: alg, verifyKey: pub}, nil
+}
+
+// LoadKeyFile reads a PEM key from disk
+func LoadKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+	return ParseKeyPEM(id, data)
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unable to parse private key")
+}
+
+func algorithmFor(pub interface{}) (string, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return "", fmt.Errorf("RSA keys must be at least 2048 bits")
+		}
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("only P-256 ECDSA keys are supported")
+		}
+		return AlgorithmES256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// Keyring holds the active signing key and any older verification keys,
+// selected by the kid header of incoming tokens.
+type Keyring struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// NewKeyring builds a keyring from the active signing key and older keys that
+// are still accepted for verification during rotation.
+func NewKeyring(active *Key, verificationKeys ...*Key) (*Keyring, error) {
+	if active == nil || !active.CanSign() {
+		return nil, fmt.Errorf("active key must contain signing material")
+	}
+	kr := &Keyring{active: active, keys: map[string]*Key{active.ID: active}}
+	for _, key := range verificationKeys {
+		if key == nil {
+			continue
+		}
+		if key.Algorithm == AlgorithmHS256 || active.Algorithm == AlgorithmHS256 {
+			return nil, fmt.Errorf("key %q: HMAC keys cannot be mixed into a keyring", key.ID)
+		}
+		if _, exists := kr.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		kr.keys[key.ID] = key
+	}
+	return kr, nil
+}
+
+// Active returns the key used for signing new tokens
+func (kr *Keyring) Active() *Key {
+	return kr.active
+}
+
+// Lookup returns the key for a kid. Tokens without a kid resolve to the active key.
+func (kr *Keyring) Lookup(kid string) (*Key, bool) {
+	if kid == "" {
+		return kr.active, true
+	}
+	key, ok := kr.keys[kid]
+	return key, ok
+}
+
+// JWKS returns the public keys of the keyring. HMAC secrets are never published.
+func (kr *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	ids := make([]string, 0, len(kr.keys))
+	for id := range kr.keys {
+		if id != kr.active.ID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range append([]string{kr.active.ID}, ids...) {
+		key := kr.keys[id]
+		pub := key.PublicKey()
+		if pub == nil {
+			continue
+		}
+		jwk, err := NewJWK(key.ID, key.Algorithm, pub)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func (kr *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := kr.Lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
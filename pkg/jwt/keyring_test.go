@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+// testAccessClaims are the claims of the access tokens signed in these tests
+var testAccessClaims = AccessTokenClaims{
+	UserID: 1, Email: "jane@example.com", EmailVerified: true, Roles: []string{"user"}, OrganizationID: 3, SessionID: 2, TokenVersion: 4,
+}
+
+// generateKey returns a PEM encoded private key of the given algorithm
+func generateKey(t *testing.T, alg string) []byte {
+	t.Helper()
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	testutil.AssertNoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	testutil.AssertNoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// publicPEM returns the PEM encoded public half of key
+func publicPEM(t *testing.T, key *Key) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey())
+	testutil.AssertNoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newKey(t *testing.T, id, alg string) *Key {
+	t.Helper()
+	key, err := ParseKeyPEM(id, generateKey(t, alg))
+	testutil.AssertNoError(t, err)
+	return key
+}
+
+func newManager(t *testing.T, active *Key, verificationKeys ...*Key) *TokenManager {
+	t.Helper()
+	keyring, err := NewKeyring(active, verificationKeys...)
+	testutil.AssertNoError(t, err)
+	return NewTokenManagerWithKeyring(keyring)
+}
+
+func TestKeyringSignsWithEachAlgorithm(t *testing.T) {
+	for _, alg := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		key := newKey(t, "key-"+alg, alg)
+		testutil.AssertEqual(t, alg, key.Algorithm)
+		testutil.AssertTrue(t, key.CanSign(), "%s private key can sign", alg)
+		tm := newManager(t, key)
+
+		token, err := tm.GenerateAccessToken(testAccessClaims, time.Minute)
+		testutil.AssertNoError(t, err)
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+		testutil.AssertNoError(t, err)
+		testutil.AssertEqual(t, alg, parsed.Header["alg"])
+		testutil.AssertEqual(t, key.ID, parsed.Header["kid"])
+
+		claims, err := tm.ValidateAccessToken(token)
+		testutil.AssertNoError(t, err)
+		testutil.AssertEqual(t, testAccessClaims, AccessTokenClaims{
+			UserID: claims.UserID, Email: claims.Email, EmailVerified: claims.EmailVerified, Roles: claims.Roles,
+			OrganizationID: claims.OrganizationID, SessionID: claims.SessionID, TokenVersion: claims.TokenVersion,
+		})
+		testutil.AssertEqual(t, TokenTypeAccess, claims.TokenType)
+	}
+
+	// HS256 tokens carry no kid
+	tm := NewTokenManager("test-secret-key-that-is-long-enough")
+	token, err := tm.GenerateAccessToken(testAccessClaims, time.Minute)
+	testutil.AssertNoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertNil(t, parsed.Header["kid"])
+	_, err = tm.ValidateAccessToken(token)
+	testutil.AssertNoError(t, err)
+}
+
+func TestNewTokenManagerRejectsEmptySecret(t *testing.T) {
+	defer func() {
+		testutil.AssertNotNil(t, recover(), "an empty secret must not produce a token manager")
+	}()
+	NewTokenManager("")
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old := newKey(t, "2024-01", AlgorithmRS256)
+	oldToken, err := newManager(t, old).GenerateAccessToken(testAccessClaims, time.Minute)
+	testutil.AssertNoError(t, err)
+
+	// The old key stays accepted for verification after the new one takes over
+	retired, err := ParseKeyPEM(old.ID, publicPEM(t, old))
+	testutil.AssertNoError(t, err)
+	testutil.AssertFalse(t, retired.CanSign(), "a public key cannot sign")
+	current := newKey(t, "2024-02", AlgorithmES256)
+	rotated := newManager(t, current, retired)
+	_, err = rotated.ValidateAccessToken(oldToken)
+	testutil.AssertNoError(t, err)
+	newToken, err := rotated.GenerateAccessToken(testAccessClaims, time.Minute)
+	testutil.AssertNoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, current.ID, parsed.Header["kid"])
+
+	// Once the old key is dropped its tokens have an unknown kid
+	_, err = newManager(t, current).ValidateAccessToken(oldToken)
+	testutil.AssertError(t, err)
+}
+
+func TestKeyringRejectsUnexpectedTokens(t *testing.T) {
+	key := newKey(t, "rsa", AlgorithmRS256)
+	tm := newManager(t, key)
+	claims := Claims{
+		UserID:           1,
+		TokenType:        TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}
+	sign := func(method jwt.SigningMethod, kid string, signKey interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(signKey)
+		testutil.AssertNoError(t, err)
+		return signed
+	}
+
+	// An HMAC token keyed with the RSA public key must not verify
+	der, err := x509.MarshalPKIXPublicKey(key.PublicKey())
+	testutil.AssertNoError(t, err)
+	_, err = tm.ValidateAccessToken(sign(jwt.SigningMethodHS256, "rsa", der))
+	testutil.AssertError(t, err)
+
+	other := newKey(t, "other", AlgorithmES256)
+	_, err = tm.ValidateAccessToken(sign(jwt.SigningMethodES256, "rsa", other.signKey))
+	testutil.AssertError(t, err)
+	_, err = tm.ValidateAccessToken(sign(jwt.SigningMethodES256, "other", other.signKey))
+	testutil.AssertError(t, err)
+	_, err = tm.ValidateAccessToken(sign(jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType))
+	testutil.AssertError(t, err)
+	_, err = tm.ValidateAccessToken(sign(jwt.SigningMethodRS256, "rsa", key.signKey))
+	testutil.AssertNoError(t, err)
+
+	// Refresh tokens are not access tokens
+	refresh, err := tm.GenerateRefreshToken(1, "jane@example.com", 2, NewTokenID(), 0, time.Minute)
+	testutil.AssertNoError(t, err)
+	_, err = tm.ValidateAccessToken(refresh)
+	testutil.AssertError(t, err)
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	rsaKey := newKey(t, "rsa", AlgorithmRS256)
+	public, err := ParseKeyPEM("public", publicPEM(t, rsaKey))
+	testutil.AssertNoError(t, err)
+
+	_, err = NewKeyring(public)
+	testutil.AssertError(t, err)
+	_, err = NewKeyring(rsaKey, NewHMACKey("hmac", "test-secret-key-that-is-long-enough"))
+	testutil.AssertError(t, err)
+	_, err = NewKeyring(rsaKey, newKey(t, "rsa", AlgorithmEdDSA))
+	testutil.AssertError(t, err)
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	testutil.AssertNoError(t, err)
+	_, err = ParseKeyPEM("small", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(small)}))
+	testutil.AssertError(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	testutil.AssertNoError(t, err)
+	der, err := x509.MarshalECPrivateKey(p384)
+	testutil.AssertNoError(t, err)
+	_, err = ParseKeyPEM("p384", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	testutil.AssertError(t, err)
+	_, err = ParseKeyPEM("garbage", []byte("not a key"))
+	testutil.AssertError(t, err)
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	active := newKey(t, "b-active", AlgorithmEdDSA)
+	set := newManager(t, active, newKey(t, "c-rsa", AlgorithmRS256), newKey(t, "a-ec", AlgorithmES256)).Keyring().JWKS()
+
+	testutil.AssertLen(t, set.Keys, 3)
+	kids := make([]string, len(set.Keys))
+	for i, jwk := range set.Keys {
+		kids[i] = jwk.Kid
+		testutil.AssertEqual(t, "sig", jwk.Use)
+	}
+	testutil.AssertEqual(t, []string{"b-active", "a-ec", "c-rsa"}, kids, "active key first, then by kid")
+	testutil.AssertEqual(t, JWK{Kty: "OKP", Kid: "b-active", Use: "sig", Alg: AlgorithmEdDSA, Crv: "Ed25519", X: b64(active.PublicKey().(ed25519.PublicKey))}, set.Keys[0])
+	testutil.AssertEqual(t, "P-256", set.Keys[1].Crv)
+	testutil.AssertEqual(t, "AQAB", set.Keys[2].E)
+
+	// HMAC secrets are never published
+	testutil.AssertLen(t, NewTokenManager("test-secret-key-that-is-long-enough").Keyring().JWKS().Keys, 0)
+}
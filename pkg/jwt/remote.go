@@ -0,0 +1,306 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minJWKSRefreshInterval limits how often an unknown kid can force a JWKS fetch
+const minJWKSRefreshInterval = 30 * time.Second
+
+var remoteAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// RemoteVerifierConfig configures validation of tokens issued by an external
+// OpenID Connect provider.
+type RemoteVerifierConfig struct {
+	// Issuer must match the iss claim exactly
+	Issuer string
+	// Audience must be present in the aud claim; empty skips the check
+	Audience string
+	// JWKSURL overrides discovery through {Issuer}/.well-known/openid-configuration
+	JWKSURL string
+	// CacheTTL controls how long fetched keys are reused
+	CacheTTL time.Duration
+	// Claim names (dot separated for nested claims) mapped onto ExternalIdentity
+	SubjectClaim string
+	EmailClaim   string
+	RoleClaim    string
+	HTTPClient   *http.Client
+}
+
+// ExternalIdentity is the verified identity carried by an external token
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Roles         []string
+	Name          string
+	TokenID       string
+	ExpiresAt     time.Time
+	Claims        jwt.MapClaims
+}
+
+type remoteKey struct {
+	alg string
+	pub interface{}
+}
+
+// RemoteVerifier validates tokens against a remote, cached JWKS
+type RemoteVerifier struct {
+	cfg RemoteVerifierConfig
+
+	mu          sync.RWMutex
+	jwksURL     string
+	keys        map[string]remoteKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewRemoteVerifier creates a verifier; keys are fetched lazily on first use
+func NewRemoteVerifier(cfg RemoteVerifierConfig) *RemoteVerifier {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = time.Hour
+	}
+	if cfg.SubjectClaim == "" {
+		cfg.SubjectClaim = "sub"
+	}
+	if cfg.EmailClaim == "" {
+		cfg.EmailClaim = "email"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &RemoteVerifier{cfg: cfg, jwksURL: cfg.JWKSURL}
+}
+
+// Issuer returns the configured issuer
+func (v *RemoteVerifier) Issuer() string {
+	return v.cfg.Issuer
+}
+
+// Handles reports whether the token claims to come from this verifier's issuer.
+// The signature is not checked; call Verify before trusting the token.
+func (v *RemoteVerifier) Handles(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	iss, _ := claims["iss"].(string)
+	return iss != "" && iss == v.cfg.Issuer
+}
+
+// Verify checks the signature, issuer, audience, and expiry of the token and
+// maps its claims onto an ExternalIdentity.
+func (v *RemoteVerifier) Verify(ctx context.Context, tokenString string) (*ExternalIdentity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(remoteAlgorithms),
+		jwt.WithIssuer(v.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+	}
+	if v.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.cfg.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := v.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.alg != "" && key.alg != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.pub, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	identity := &ExternalIdentity{
+		Issuer:  v.cfg.Issuer,
+		Subject: claimString(claims, v.cfg.SubjectClaim),
+		Email:   strings.ToLower(claimString(claims, v.cfg.EmailClaim)),
+		Name:    claimString(claims, "name"),
+		TokenID: claimString(claims, "jti"),
+		Claims:  claims,
+	}
+	if v.cfg.RoleClaim != "" {
+		identity.Roles = claimStrings(claims, v.cfg.RoleClaim)
+	}
+	if verified, ok := claims["email_verified"].(bool); ok {
+		identity.EmailVerified = verified
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		identity.ExpiresAt = exp.Time
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("token has no %s claim", v.cfg.SubjectClaim)
+	}
+	return identity, nil
+}
+
+func (v *RemoteVerifier) key(ctx context.Context, kid string) (remoteKey, error) {
+	v.mu.RLock()
+	key, ok := v.lookup(kid)
+	fresh := time.Since(v.fetchedAt) < v.cfg.CacheTTL
+	canRetry := time.Since(v.lastAttempt) >= minJWKSRefreshInterval
+	v.mu.RUnlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+	if !fresh || canRetry {
+		if err := v.refresh(ctx); err != nil && !ok {
+			return remoteKey{}, err
+		}
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, ok := v.lookup(kid); ok {
+		return key, nil
+	}
+	return remoteKey{}, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup must be called with v.mu held
+func (v *RemoteVerifier) lookup(kid string) (remoteKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+func (v *RemoteVerifier) refresh(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if time.Since(v.lastAttempt) < minJWKSRefreshInterval {
+		return nil
+	}
+	v.lastAttempt = time.Now()
+
+	if v.jwksURL == "" {
+		jwksURL, err := v.discoverJWKSURL(ctx)
+		if err != nil {
+			return err
+		}
+		v.jwksURL = jwksURL
+	}
+
+	var set JWKSet
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+	keys := make(map[string]remoteKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = remoteKey{alg: jwk.Alg, pub: pub}
+	}
+	if len(keys) == 0 {
+		return errors.New("jwks contains no usable signing keys")
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+func (v *RemoteVerifier) discoverJWKSURL(ctx context.Context) (string, error) {
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	discoveryURL := strings.TrimSuffix(v.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := v.getJSON(ctx, discoveryURL, &doc); err != nil {
+		return "", fmt.Errorf("oidc discovery: %w", err)
+	}
+	if doc.Issuer != v.cfg.Issuer {
+		return "", fmt.Errorf("oidc discovery: issuer mismatch %q", doc.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("oidc discovery: jwks_uri missing")
+	}
+	return doc.JWKSURI, nil
+}
+
+func (v *RemoteVerifier) getJSON(ctx context.Context, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// claimValue resolves a dot separated claim path
+func claimValue(claims jwt.MapClaims, path string) interface{} {
+	var current interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = obj[part]
+	}
+	return current
+}
+
+// claimString returns a single valued claim; arrays resolve to ""
+func claimString(claims jwt.MapClaims, path string) string {
+	switch val := claimValue(claims, path).(type) {
+	case string:
+		return val
+	case float64:
+		return fmt.Sprintf("%.0f", val)
+	}
+	return ""
+}
+
+// claimStrings returns every string of an array claim such as
+// realm_access.roles. A single string resolves to a one element list.
+func claimStrings(claims jwt.MapClaims, path string) []string {
+	switch val := claimValue(claims, path).(type) {
+	case string:
+		if val != "" {
+			return []string{val}
+		}
+	case []interface{}:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
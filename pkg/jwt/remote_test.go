@@ -0,0 +1,190 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+type fakeIdP struct {
+	server     *httptest.Server
+	keys       atomic.Value
+	jwksHits   atomic.Int32
+	discovered atomic.Int32
+}
+
+func newFakeIdP(t *testing.T, keys ...JWK) *fakeIdP {
+	t.Helper()
+	idp := &fakeIdP{}
+	idp.keys.Store(keys)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.discovered.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   idp.server.URL,
+			"jwks_uri": idp.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksHits.Add(1)
+		_ = json.NewEncoder(w).Encode(JWKSet{Keys: idp.keys.Load().([]JWK)})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func newRSAKey(t *testing.T, kid string) (*rsa.PrivateKey, JWK) {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	jwk, err := NewJWK(kid, AlgorithmRS256, &priv.PublicKey)
+	if err != nil {
+		t.Fatalf("encode jwk: %v", err)
+	}
+	return priv, jwk
+}
+
+func signExternal(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func externalClaims(issuer string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":          issuer,
+		"aud":          "api://boilerplate",
+		"sub":          "idp-user-1",
+		"email":        "Jane@Corp.example",
+		"given_name":   "Jane",
+		"jti":          "ext-token-1",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"iat":          time.Now().Unix(),
+		"realm_access": map[string]interface{}{"roles": []interface{}{"admin", "user"}},
+	}
+}
+
+func TestRemoteVerifierVerifiesTokenFromDiscoveredJWKS(t *testing.T) {
+	priv, jwk := newRSAKey(t, "rsa-1")
+	idp := newFakeIdP(t, jwk)
+	verifier := NewRemoteVerifier(RemoteVerifierConfig{
+		Issuer:    idp.server.URL,
+		Audience:  "api://boilerplate",
+		RoleClaim: "realm_access.roles",
+	})
+
+	token := signExternal(t, jwt.SigningMethodRS256, "rsa-1", priv, externalClaims(idp.server.URL))
+	testutil.AssertTrue(t, verifier.Handles(token), "verifier should handle tokens from its issuer")
+
+	identity, err := verifier.Verify(context.Background(), token)
+	testutil.AssertNoError(t, err)
+	if identity == nil {
+		t.FailNow()
+	}
+	testutil.AssertEqual(t, "idp-user-1", identity.Subject)
+	testutil.AssertEqual(t, "jane@corp.example", identity.Email)
+	testutil.AssertEqual(t, []string{"admin", "user"}, identity.Roles)
+	testutil.AssertEqual(t, "ext-token-1", identity.TokenID)
+	testutil.AssertEqual(t, int32(1), idp.discovered.Load())
+
+	_, err = verifier.Verify(context.Background(), token)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, int32(1), idp.jwksHits.Load(), "keys should be served from cache")
+}
+
+func TestRemoteVerifierRejectsInvalidTokens(t *testing.T) {
+	priv, jwk := newRSAKey(t, "rsa-1")
+	otherPriv, _ := newRSAKey(t, "rsa-1")
+	idp := newFakeIdP(t, jwk)
+	verifier := NewRemoteVerifier(RemoteVerifierConfig{
+		Issuer:   idp.server.URL,
+		Audience: "api://boilerplate",
+		JWKSURL:  idp.server.URL + "/keys",
+	})
+
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+		key    interface{}
+	}{
+		{name: "wrong audience", mutate: func(c jwt.MapClaims) { c["aud"] = "api://other" }, key: priv},
+		{name: "wrong issuer", mutate: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, key: priv},
+		{name: "expired", mutate: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, key: priv},
+		{name: "missing expiry", mutate: func(c jwt.MapClaims) { delete(c, "exp") }, key: priv},
+		{name: "wrong signature", mutate: func(jwt.MapClaims) {}, key: otherPriv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := externalClaims(idp.server.URL)
+			tt.mutate(claims)
+			token := signExternal(t, jwt.SigningMethodRS256, "rsa-1", tt.key, claims)
+			_, err := verifier.Verify(context.Background(), token)
+			testutil.AssertError(t, err)
+		})
+	}
+
+	hmacToken, _ := NewTokenManager("a-local-secret-that-is-long-enough!!").GenerateAccessToken(AccessTokenClaims{UserID: 1, Email: "a@b.c", SessionID: 1}, time.Minute)
+	testutil.AssertFalse(t, verifier.Handles(hmacToken), "local tokens have no external issuer")
+}
+
+func TestClaimStringsResolvesSingleAndMultiValuedClaims(t *testing.T) {
+	claims := jwt.MapClaims{
+		"role":         "admin",
+		"realm_access": map[string]interface{}{"roles": []interface{}{"admin", 7.0, "", "user"}},
+	}
+	testutil.AssertEqual(t, []string{"admin"}, claimStrings(claims, "role"))
+	testutil.AssertEqual(t, []string{"admin", "user"}, claimStrings(claims, "realm_access.roles"))
+	testutil.AssertLen(t, claimStrings(claims, "realm_access.groups"), 0)
+	testutil.AssertLen(t, claimStrings(claims, "role.name"), 0)
+	testutil.AssertEqual(t, "", claimString(claims, "realm_access.roles"), "an array is not a single value")
+}
+
+func TestRemoteVerifierRefetchesKeysOnRotation(t *testing.T) {
+	_, oldJWK := newRSAKey(t, "old")
+	idp := newFakeIdP(t, oldJWK)
+	verifier := NewRemoteVerifier(RemoteVerifierConfig{
+		Issuer:   idp.server.URL,
+		Audience: "api://boilerplate",
+		JWKSURL:  idp.server.URL + "/keys",
+	})
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	testutil.AssertNoError(t, err)
+	newJWK, err := NewJWK("new", AlgorithmEdDSA, pub)
+	testutil.AssertNoError(t, err)
+	token := signExternal(t, jwt.SigningMethodEdDSA, "new", priv, externalClaims(idp.server.URL))
+
+	_, err = verifier.Verify(context.Background(), token)
+	testutil.AssertError(t, err, "unknown kid should fail before the IdP publishes it")
+
+	idp.keys.Store([]JWK{oldJWK, newJWK})
+	_, err = verifier.Verify(context.Background(), token)
+	testutil.AssertError(t, err, "unknown kid refetches are throttled")
+	testutil.AssertEqual(t, int32(1), idp.jwksHits.Load())
+
+	verifier.mu.Lock()
+	verifier.lastAttempt = time.Now().Add(-minJWKSRefreshInterval)
+	verifier.mu.Unlock()
+	identity, err := verifier.Verify(context.Background(), token)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNotNil(t, identity)
+	testutil.AssertEqual(t, int32(2), idp.jwksHits.Load())
+}
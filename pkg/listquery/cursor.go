@@ -0,0 +1,215 @@
+package listquery
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// cursorKey signs cursors. The random default keeps cursors valid only for
+// the life of the process; SetCursorKey replaces it with a shared key.
+var cursorKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// SetCursorKey sets the key that signs cursors. An empty key keeps the
+// random per-process key.
+func SetCursorKey(key []byte) {
+	if len(key) > 0 {
+		cursorKey = key
+	}
+}
+
+// Page describes a page loaded by Paginate
+type Page struct {
+	// NextCursor and PrevCursor are empty at either end of the list
+	NextCursor string
+	PrevCursor string
+	// Total is the number of matching rows, when counted
+	Total *int64
+}
+
+// cursor is the signed payload of a cursor: the sort values of the row it
+// points at, and a fingerprint of the query that issued it
+type cursor struct {
+	Query  string            `json:"q"`
+	Values []json.RawMessage `json:"v"`
+	Prev   bool              `json:"p,omitempty"`
+}
+
+// CursorMode reports whether the request asked for cursor pagination
+func (q *Query) CursorMode() bool {
+	return q.cursorMode
+}
+
+// Paginate loads into dest, a pointer to a slice of models, the page of at
+// most limit rows that follows the query's cursor, or the first page without
+// one. db must already carry the filter; Paginate adds the order. Sort columns
+// must be NOT NULL columns of the model. With count, the rows matching db are
+// counted too.
+func (q *Query) Paginate(db *gorm.DB, dest interface{}, limit int, count bool) (*Page, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return nil, err
+	}
+	fields := make([]*schema.Field, len(q.sorts))
+	for i, sort := range q.sorts {
+		name := sort.Column[strings.LastIndexByte(sort.Column, '.')+1:]
+		if fields[i] = stmt.Schema.LookUpField(name); fields[i] == nil {
+			return nil, fmt.Errorf("listquery: %s has no column %s", stmt.Schema.Name, name)
+		}
+	}
+
+	page := &Page{}
+	if count {
+		var total int64
+		if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	backward := q.cursor != nil && q.cursor.Prev
+	if q.cursor != nil {
+		values := make([]interface{}, len(fields))
+		for i, field := range fields {
+			value := reflect.New(field.FieldType)
+			if err := json.Unmarshal(q.cursor.Values[i], value.Interface()); err != nil {
+				return nil, fmt.Errorf("listquery: decode cursor: %w", err)
+			}
+			values[i] = value.Elem().Interface()
+		}
+		sql, args := keyset(q.sorts, values, backward)
+		db = db.Where(sql, args...)
+	}
+	for _, sort := range q.sorts {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sort.Column}, Desc: sort.Desc != backward})
+	}
+	if err := db.Limit(limit + 1).Find(dest).Error; err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	more := rows.Len() > limit
+	if more {
+		rows.Set(rows.Slice(0, limit))
+	}
+	n := rows.Len()
+	if n == 0 {
+		return page, nil
+	}
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	var err error
+	first, last := rows.Index(0), rows.Index(n-1)
+	if (backward && more) || (!backward && q.cursor != nil) {
+		if page.PrevCursor, err = q.encodeCursor(db, fields, first, true); err != nil {
+			return nil, err
+		}
+	}
+	if backward || more {
+		if page.NextCursor, err = q.encodeCursor(db, fields, last, false); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// keyset builds the condition selecting rows after values in the sort order,
+// or before them when backward:
+// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?) ...
+func keyset(sorts []Sort, values []interface{}, backward bool) (string, []interface{}) {
+	var args []interface{}
+	terms := make([]string, len(sorts))
+	for i, sort := range sorts {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, sorts[j].Column+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if sort.Desc != backward {
+			op = " < ?"
+		}
+		parts = append(parts, sort.Column+op)
+		args = append(args, values[i])
+		terms[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// fingerprint identifies the filter and order, so a cursor is only accepted
+// by the query that issued it
+func (q *Query) fingerprint() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v|%v", q.conditions, q.sorts)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (q *Query) encodeCursor(db *gorm.DB, fields []*schema.Field, row reflect.Value, prev bool) (string, error) {
+	cur := cursor{Query: q.fingerprint(), Values: make([]json.RawMessage, len(fields)), Prev: prev}
+	for i, field := range fields {
+		value, _ := field.ValueOf(db.Statement.Context, row)
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("listquery: encode cursor: %w", err)
+		}
+		cur.Values[i] = raw
+	}
+	payload, err := json.Marshal(cur)
+	if err != nil {
+		return "", fmt.Errorf("listquery: encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(payload)), nil
+}
+
+func (q *Query) decodeCursor(token string) (*cursor, *FieldError) {
+	invalid := &FieldError{Field: CursorParam, Code: CodeInvalidValue, Message: "invalid cursor"}
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, sign(payload)) {
+		return nil, invalid
+	}
+	var cur cursor
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return nil, invalid
+	}
+	if cur.Query != q.fingerprint() {
+		return nil, &FieldError{Field: CursorParam, Code: CodeInvalidValue, Message: "cursor was issued for other filters or another sort order"}
+	}
+	if len(cur.Values) != len(q.sorts) {
+		return nil, invalid
+	}
+	return &cur, nil
+}
+
+func sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,127 @@
+package listquery
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// item is a listed record like the models the package pages through
+type item struct {
+	ID     uint
+	Name   string
+	Status string
+}
+
+var testSpec = Spec{
+	Filters: map[string]Filter{
+		"status": {Columns: []string{"items.status"}, Op: In, Values: []string{"active", "archived"}},
+	},
+	Sorts:       map[string]string{"name": "items.name"},
+	DefaultSort: "name",
+	TieBreaker:  "items.id",
+}
+
+// pageFixture returns a session over five items named A to E, where B and D
+// share the name so only the tie-breaker orders them
+func pageFixture(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, name := range []string{"Echo", "Bravo", "Alpha", "Bravo", "Delta"} {
+		if err := db.Create(&item{Name: name, Status: "active"}).Error; err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+	return db.Model(&item{})
+}
+
+func loadPage(t *testing.T, db *gorm.DB, params map[string]string) ([]uint, *Page) {
+	t.Helper()
+	query, err := testSpec.Parse(params, 0)
+	if err != nil {
+		t.Fatalf("Parse(%v) error = %v", params, err)
+	}
+	var rows []item
+	page, err := query.Paginate(query.Filter(db.Session(&gorm.Session{})), &rows, 2, true)
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return ids, page
+}
+
+func TestPaginatePagesForwardAndBackward(t *testing.T) {
+	db := pageFixture(t)
+
+	first, page := loadPage(t, db, map[string]string{CursorParam: ""})
+	if want := []uint{3, 2}; !reflect.DeepEqual(first, want) {
+		t.Errorf("first page = %v, want %v", first, want)
+	}
+	if page.Total == nil || *page.Total != 5 {
+		t.Errorf("total = %v, want 5", page.Total)
+	}
+	if page.PrevCursor != "" {
+		t.Error("the first page has no previous page")
+	}
+
+	second, page := loadPage(t, db, map[string]string{CursorParam: page.NextCursor})
+	if want := []uint{4, 5}; !reflect.DeepEqual(second, want) {
+		t.Errorf("second page = %v, want %v", second, want)
+	}
+	next, prev := page.NextCursor, page.PrevCursor
+
+	last, page := loadPage(t, db, map[string]string{CursorParam: next})
+	if want := []uint{1}; !reflect.DeepEqual(last, want) {
+		t.Errorf("last page = %v, want %v", last, want)
+	}
+	if page.NextCursor != "" {
+		t.Error("the last page has no next page")
+	}
+
+	back, page := loadPage(t, db, map[string]string{CursorParam: prev})
+	if !reflect.DeepEqual(back, first) {
+		t.Errorf("paging back = %v, want %v", back, first)
+	}
+	if page.PrevCursor != "" || page.NextCursor == "" {
+		t.Errorf("paging back to the first page: prev %q, next %q", page.PrevCursor, page.NextCursor)
+	}
+}
+
+func TestParseRejectsForeignCursors(t *testing.T) {
+	db := pageFixture(t)
+	_, page := loadPage(t, db, map[string]string{CursorParam: ""})
+
+	for name, params := range map[string]map[string]string{
+		"tampered":     {CursorParam: page.NextCursor[:len(page.NextCursor)-2] + "AA"},
+		"other filter": {CursorParam: page.NextCursor, "status": "archived"},
+		"other sort":   {CursorParam: page.NextCursor, SortParam: "-name"},
+	} {
+		_, err := testSpec.Parse(params, 0)
+		var queryErr *Error
+		if !errors.As(err, &queryErr) {
+			t.Fatalf("%s: Parse() error = %v, want *Error", name, err)
+		}
+		if queryErr.Fields[0].Field != CursorParam {
+			t.Errorf("%s: rejected field = %q, want %q", name, queryErr.Fields[0].Field, CursorParam)
+		}
+	}
+}
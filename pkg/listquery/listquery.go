@@ -0,0 +1,316 @@
+// Package listquery turns the query parameters of a list endpoint into GORM
+// filter and order clauses. Only parameters and sort keys declared in a Spec
+// are accepted; everything else is rejected with a FieldError, and values
+// always reach the database as bound parameters.
+package listquery
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Op is how a filter compares its column with the parameter
+type Op int
+
+const (
+	// Eq matches one value
+	Eq Op = iota
+	// In matches any of a comma separated list of values
+	In
+	// After matches values at or after the parameter
+	After
+	// Before matches values before the parameter
+	Before
+	// Contains matches a case-insensitive substring in any of the columns
+	Contains
+)
+
+// Type is how parameter values are parsed
+type Type int
+
+const (
+	String Type = iota
+	Uint
+	// Time accepts RFC 3339 timestamps and YYYY-MM-DD dates
+	Time
+)
+
+// Me is the value that stands for the current user in filters with AllowMe
+const Me = "me"
+
+// Error codes of FieldError
+const (
+	CodeUnknownParameter = "unknown_parameter"
+	CodeInvalidValue     = "invalid_value"
+	CodeUnknownSortField = "unknown_sort_field"
+)
+
+// SortParam is the query parameter listing sort keys
+const SortParam = "sort"
+
+// CursorParam is the query parameter carrying a cursor from a previous page.
+// Its presence, even empty, selects cursor pagination.
+const CursorParam = "cursor"
+
+// Filter declares a filterable query parameter
+type Filter struct {
+	// Columns are the columns compared; Contains searches all of them, the
+	// other ops use the first
+	Columns []string
+	Op      Op
+	Type    Type
+	// Values, when set, are the only values accepted
+	Values []string
+	// AllowMe lets the value "me" stand for the current user's ID
+	AllowMe bool
+	// MaxLength bounds string values; 0 means 255
+	MaxLength int
+}
+
+// Spec is the whitelist of one list endpoint
+type Spec struct {
+	Filters map[string]Filter
+	// Sorts maps sort keys to columns
+	Sorts map[string]string
+	// DefaultSort is used without a sort parameter, e.g. "-created_at"
+	DefaultSort string
+	// TieBreaker is a unique column appended to every order so pages are stable
+	TieBreaker string
+	// Reserved are other parameters the endpoint handles itself, like page and limit
+	Reserved []string
+}
+
+// FieldError describes a rejected query parameter
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// Error lists every rejected parameter of a query
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 1 {
+		return "invalid query parameter " + e.Fields[0].Field + ": " + e.Fields[0].Message
+	}
+	return fmt.Sprintf("%d invalid query parameters", len(e.Fields))
+}
+
+// Sort is one key of the order, by column
+type Sort struct {
+	Key    string
+	Column string
+	Desc   bool
+}
+
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+// Query is a parsed, validated list query
+type Query struct {
+	conditions []condition
+	sorts      []Sort
+	cursorMode bool
+	cursor     *cursor
+}
+
+// Parse validates params against the spec. userID replaces "me" in filters
+// that allow it. All problems are reported together in an *Error.
+func (s *Spec) Parse(params map[string]string, userID uint) (*Query, error) {
+	q := &Query{}
+	var errs []FieldError
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := strings.TrimSpace(params[name])
+		if name == SortParam || name == CursorParam || slices.Contains(s.Reserved, name) {
+			continue
+		}
+		filter, ok := s.Filters[name]
+		if !ok {
+			errs = append(errs, FieldError{Field: name, Code: CodeUnknownParameter, Message: "unknown query parameter"})
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		cond, fieldErr := filter.condition(name, value, userID)
+		if fieldErr != nil {
+			errs = append(errs, *fieldErr)
+			continue
+		}
+		q.conditions = append(q.conditions, cond)
+	}
+
+	sort := strings.TrimSpace(params[SortParam])
+	if sort == "" {
+		sort = s.DefaultSort
+	}
+	seen := map[string]bool{}
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		column, ok := s.Sorts[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: SortParam, Code: CodeUnknownSortField, Message: fmt.Sprintf("cannot sort by %q; allowed: %s", key, strings.Join(s.sortKeys(), ", "))})
+			continue
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		q.sorts = append(q.sorts, Sort{Key: key, Column: column, Desc: desc})
+	}
+	if s.TieBreaker != "" && !slices.ContainsFunc(q.sorts, func(sort Sort) bool { return sort.Column == s.TieBreaker }) {
+		desc := len(q.sorts) > 0 && q.sorts[len(q.sorts)-1].Desc
+		q.sorts = append(q.sorts, Sort{Column: s.TieBreaker, Desc: desc})
+	}
+
+	if len(errs) > 0 {
+		return nil, &Error{Fields: errs}
+	}
+	if token, ok := params[CursorParam]; ok {
+		q.cursorMode = true
+		if token = strings.TrimSpace(token); token != "" {
+			cur, fieldErr := q.decodeCursor(token)
+			if fieldErr != nil {
+				return nil, &Error{Fields: []FieldError{*fieldErr}}
+			}
+			q.cursor = cur
+		}
+	}
+	return q, nil
+}
+
+func (s *Spec) sortKeys() []string {
+	keys := make([]string, 0, len(s.Sorts))
+	for key := range s.Sorts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// likeEscaper escapes LIKE wildcards in user supplied search terms
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (f Filter) condition(name, value string, userID uint) (condition, *FieldError) {
+	invalid := func(message string) *FieldError {
+		return &FieldError{Field: name, Code: CodeInvalidValue, Message: message}
+	}
+	maxLength := f.MaxLength
+	if maxLength == 0 {
+		maxLength = 255
+	}
+	if len(value) > maxLength {
+		return condition{}, invalid(fmt.Sprintf("must be at most %d characters long", maxLength))
+	}
+
+	if f.Op == Contains {
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+		clauses := make([]string, len(f.Columns))
+		args := make([]interface{}, len(f.Columns))
+		for i, column := range f.Columns {
+			clauses[i] = "LOWER(" + column + `) LIKE ? ESCAPE '\'`
+			args[i] = pattern
+		}
+		return condition{sql: "(" + strings.Join(clauses, " OR ") + ")", args: args}, nil
+	}
+
+	raw := []string{value}
+	if f.Op == In {
+		raw = strings.Split(value, ",")
+	}
+	values := make([]interface{}, 0, len(raw))
+	for _, v := range raw {
+		v = strings.TrimSpace(v)
+		if f.AllowMe && v == Me {
+			values = append(values, userID)
+			continue
+		}
+		if len(f.Values) > 0 && !slices.Contains(f.Values, v) {
+			return condition{}, invalid(fmt.Sprintf("%q is not one of %s", v, strings.Join(f.Values, ", ")))
+		}
+		parsed, err := f.Type.parse(v)
+		if err != nil {
+			return condition{}, invalid(err.Error())
+		}
+		values = append(values, parsed)
+	}
+
+	column := f.Columns[0]
+	switch f.Op {
+	case In:
+		return condition{sql: column + " IN ?", args: []interface{}{values}}, nil
+	case After:
+		return condition{sql: column + " >= ?", args: values}, nil
+	case Before:
+		return condition{sql: column + " < ?", args: values}, nil
+	default:
+		return condition{sql: column + " = ?", args: values}, nil
+	}
+}
+
+func (t Type) parse(value string) (interface{}, error) {
+	switch t {
+	case Uint:
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a positive integer", value)
+		}
+		return uint(n), nil
+	case Time:
+		if ts, err := time.Parse(time.RFC3339, value); err == nil {
+			return ts, nil
+		}
+		if day, err := time.Parse(time.DateOnly, value); err == nil {
+			return day, nil
+		}
+		return nil, fmt.Errorf("%q is not an RFC 3339 timestamp or YYYY-MM-DD date", value)
+	default:
+		if value == "" {
+			return nil, fmt.Errorf("empty value")
+		}
+		return value, nil
+	}
+}
+
+// Sorts returns the order, ending with the tie breaker
+func (q *Query) Sorts() []Sort {
+	return q.sorts
+}
+
+// Filter applies the filter conditions
+func (q *Query) Filter(db *gorm.DB) *gorm.DB {
+	for _, cond := range q.conditions {
+		db = db.Where(cond.sql, cond.args...)
+	}
+	return db
+}
+
+// Order applies the sort order
+func (q *Query) Order(db *gorm.DB) *gorm.DB {
+	for _, sort := range q.sorts {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sort.Column}, Desc: sort.Desc})
+	}
+	return db
+}
@@ -0,0 +1,110 @@
+package listquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var filterSpec = Spec{
+	Filters: map[string]Filter{
+		"status":        {Columns: []string{"status"}, Op: In, Values: []string{"active", "archived"}},
+		"created_by":    {Columns: []string{"created_by_id"}, Op: In, Type: Uint, AllowMe: true},
+		"created_after": {Columns: []string{"created_at"}, Op: After, Type: Time},
+		"q":             {Columns: []string{"name"}, Op: Contains, MaxLength: 10},
+	},
+	Sorts:       map[string]string{"name": "name", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	TieBreaker:  "id",
+	Reserved:    []string{"page", "limit"},
+}
+
+// fieldErrors parses params and returns the rejected fields by name
+func fieldErrors(t *testing.T, params map[string]string) map[string]FieldError {
+	t.Helper()
+	_, err := filterSpec.Parse(params, 7)
+	var queryErr *Error
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("Parse(%v) error = %v, want *Error", params, err)
+	}
+	fields := make(map[string]FieldError, len(queryErr.Fields))
+	for _, f := range queryErr.Fields {
+		fields[f.Field] = f
+	}
+	return fields
+}
+
+func TestParseReportsEveryInvalidParameter(t *testing.T) {
+	fields := fieldErrors(t, map[string]string{
+		"status":        "active,deleted",
+		"created_by":    "me,abc",
+		"created_after": "yesterday",
+		"q":             strings.Repeat("x", 11),
+		"owner":         "1",
+		"sort":          "name,-size",
+		"page":          "2",
+	})
+	want := map[string]string{
+		"status":        CodeInvalidValue,
+		"created_by":    CodeInvalidValue,
+		"created_after": CodeInvalidValue,
+		"q":             CodeInvalidValue,
+		"owner":         CodeUnknownParameter,
+		SortParam:       CodeUnknownSortField,
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("Parse() rejected %d fields, want %d: %v", len(fields), len(want), fields)
+	}
+	for name, code := range want {
+		if fields[name].Code != code {
+			t.Errorf("%s code = %q, want %q", name, fields[name].Code, code)
+		}
+	}
+	if msg := fields["status"].Message; !strings.Contains(msg, `"deleted" is not one of active, archived`) {
+		t.Errorf("status message = %q", msg)
+	}
+	if msg := fields[SortParam].Message; !strings.Contains(msg, "allowed: created_at, name") {
+		t.Errorf("sort message = %q", msg)
+	}
+}
+
+func TestParseBuildsConditionsAndOrder(t *testing.T) {
+	query, err := filterSpec.Parse(map[string]string{
+		"status":        "active, archived",
+		"created_by":    "me,3",
+		"created_after": "2024-01-02",
+		"q":             "50%_off",
+		"sort":          "name,name",
+	}, 7)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	// Conditions follow the parameter names: created_after, created_by, q, status
+	if len(query.conditions) != 4 {
+		t.Fatalf("Parse() built %d conditions, want 4", len(query.conditions))
+	}
+	if got, want := query.conditions[1].args, []interface{}{[]interface{}{uint(7), uint(3)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("created_by args = %v, want %v", got, want)
+	}
+	if got, want := query.conditions[2].args, []interface{}{`%50\%\_off%`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("q args = %v, want %v", got, want)
+	}
+	if got, want := query.Sorts(), []Sort{{Key: "name", Column: "name"}, {Column: "id"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sorts() = %v, want %v", got, want)
+	}
+
+	query, err = filterSpec.Parse(map[string]string{"status": ""}, 7)
+	if err != nil {
+		t.Fatalf("Parse() with an empty filter error = %v", err)
+	}
+	if len(query.conditions) != 0 {
+		t.Errorf("an empty filter built %d conditions", len(query.conditions))
+	}
+	if got, want := query.Sorts(), []Sort{{Key: "created_at", Column: "created_at", Desc: true}, {Column: "id", Desc: true}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("default Sorts() = %v, want %v", got, want)
+	}
+	if query.CursorMode() {
+		t.Error("CursorMode() = true without a cursor parameter")
+	}
+}
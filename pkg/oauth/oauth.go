@@ -0,0 +1,336 @@
+// Package oauth implements the OAuth 2.0 authorization code flow with PKCE
+// (RFC 7636) against OpenID Connect and plain OAuth 2.0 providers.
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-boilerplate/pkg/jwt"
+	"go-fiber-boilerplate/pkg/utils"
+)
+
+var (
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// ProviderConfig describes an identity provider. When Issuer is set the
+// endpoints are discovered from {Issuer}/.well-known/openid-configuration and
+// the id_token is verified; explicitly set URLs take precedence.
+type ProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	// EmailsURL lists the user's addresses when the user info lacks a verified
+	// email (GitHub's /user/emails)
+	EmailsURL   string
+	Scopes      []string
+	RedirectURL string
+	HTTPClient  *http.Client
+}
+
+// Identity is the provider account that completed the login
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider runs the authorization code flow for one configured provider
+type Provider struct {
+	cfg ProviderConfig
+
+	mu         sync.Mutex
+	discovered bool
+	verifier   *jwt.RemoteVerifier
+}
+
+// NewProvider creates a provider. Discovery happens lazily on first use.
+func NewProvider(cfg ProviderConfig) *Provider {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 && cfg.Issuer != "" {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg}
+}
+
+// Google returns the configuration for Google accounts
+func Google(clientID, clientSecret string) ProviderConfig {
+	return ProviderConfig{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Issuer:       "https://accounts.google.com",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// GitHub returns the configuration for GitHub accounts. GitHub does not
+// support OpenID Connect, so identities come from its REST API.
+func GitHub(clientID, clientSecret string) ProviderConfig {
+	return ProviderConfig{
+		Name:         "github",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		EmailsURL:    "https://api.github.com/user/emails",
+		Scopes:       []string{"read:user", "user:email"},
+	}
+}
+
+// Name returns the provider name used in routes and stored identities
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// NewPKCE returns a code verifier and its S256 code challenge
+func NewPKCE() (verifier, challenge string) {
+	verifier = utils.RandomString(32)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the URL the user is sent to for consent
+func (p *Provider) AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.redirectURL())
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	if len(p.cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	}
+	if p.verifier != nil && nonce != "" {
+		params.Set("nonce", nonce)
+	}
+	separator := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.cfg.AuthURL + separator + params.Encode(), nil
+}
+
+// Exchange redeems the authorization code and returns the provider identity.
+// For OpenID Connect providers the id_token signature, audience and nonce are
+// verified; otherwise the identity is read from the user info endpoint.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL())
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("client_secret", p.cfg.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" || token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: status %d %s %s", ErrExchangeFailed, resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+
+	var identity *Identity
+	if p.verifier != nil && token.IDToken != "" {
+		identity, err = p.identityFromIDToken(ctx, token.IDToken, nonce)
+	} else {
+		identity, err = p.identityFromUserInfo(ctx, token.AccessToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider = p.cfg.Name
+	identity.Email = strings.ToLower(identity.Email)
+	return identity, nil
+}
+
+func (p *Provider) identityFromIDToken(ctx context.Context, idToken, nonce string) (*Identity, error) {
+	ext, err := p.verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claimNonce, _ := ext.Claims["nonce"].(string); nonce != "" && claimNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	return &Identity{
+		Subject:       ext.Subject,
+		Email:         ext.Email,
+		EmailVerified: ext.EmailVerified,
+		Name:          ext.Name,
+	}, nil
+}
+
+func (p *Provider) identityFromUserInfo(ctx context.Context, accessToken string) (*Identity, error) {
+	if p.cfg.UserInfoURL == "" {
+		return nil, fmt.Errorf("%w: provider has no user info endpoint", ErrExchangeFailed)
+	}
+	var info map[string]interface{}
+	if err := p.getJSON(ctx, p.cfg.UserInfoURL, accessToken, &info); err != nil {
+		return nil, fmt.Errorf("user info: %w", err)
+	}
+
+	identity := &Identity{
+		Subject: stringClaim(info, "sub", "id"),
+		Email:   stringClaim(info, "email"),
+		Name:    stringClaim(info, "name", "login"),
+	}
+	identity.EmailVerified, _ = info["email_verified"].(bool)
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("user info has no subject")
+	}
+
+	if !identity.EmailVerified && p.cfg.EmailsURL != "" {
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		if err := p.getJSON(ctx, p.cfg.EmailsURL, accessToken, &emails); err != nil {
+			return nil, fmt.Errorf("user emails: %w", err)
+		}
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				identity.Email = e.Email
+				identity.EmailVerified = true
+				break
+			}
+		}
+	}
+	return identity, nil
+}
+
+// discover fills in endpoints from the OIDC discovery document and prepares
+// id_token verification. It is a no-op for plain OAuth 2.0 providers.
+func (p *Provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered {
+		return nil
+	}
+	if p.cfg.Issuer == "" {
+		if p.cfg.AuthURL == "" || p.cfg.TokenURL == "" {
+			return fmt.Errorf("provider %q: auth and token URLs are required without an issuer", p.cfg.Name)
+		}
+		p.discovered = true
+		return nil
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, "", &doc); err != nil {
+		return fmt.Errorf("provider %q: oidc discovery: %w", p.cfg.Name, err)
+	}
+	if doc.Issuer != p.cfg.Issuer {
+		return fmt.Errorf("provider %q: oidc discovery: issuer mismatch %q", p.cfg.Name, doc.Issuer)
+	}
+	if p.cfg.AuthURL == "" {
+		p.cfg.AuthURL = doc.AuthorizationEndpoint
+	}
+	if p.cfg.TokenURL == "" {
+		p.cfg.TokenURL = doc.TokenEndpoint
+	}
+	if p.cfg.UserInfoURL == "" {
+		p.cfg.UserInfoURL = doc.UserInfoEndpoint
+	}
+	p.verifier = jwt.NewRemoteVerifier(jwt.RemoteVerifierConfig{
+		Issuer:     p.cfg.Issuer,
+		Audience:   p.cfg.ClientID,
+		JWKSURL:    doc.JWKSURI,
+		HTTPClient: p.cfg.HTTPClient,
+	})
+	p.discovered = true
+	return nil
+}
+
+func (p *Provider) redirectURL() string {
+	return strings.ReplaceAll(p.cfg.RedirectURL, "{provider}", p.cfg.Name)
+}
+
+func (p *Provider) getJSON(ctx context.Context, url, accessToken string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
+
+// stringClaim returns the first non-empty claim; numeric IDs are formatted
+// without a fractional part.
+func stringClaim(claims map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		switch val := claims[name].(type) {
+		case string:
+			if val != "" {
+				return val
+			}
+		case float64:
+			return strconv.FormatFloat(val, 'f', -1, 64)
+		}
+	}
+	return ""
+}
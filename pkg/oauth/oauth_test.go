@@ -0,0 +1,102 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"go-fiber-boilerplate/internal/testutil"
+)
+
+var testUser = testutil.FakeOIDCUser{
+	Subject:       "fake-user-1",
+	Email:         "Jane@Example.com",
+	EmailVerified: true,
+	Name:          "Jane Doe",
+}
+
+func newTestProvider(idp *testutil.FakeOIDCProvider, issuer bool) *Provider {
+	cfg := ProviderConfig{
+		Name:         "fake",
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost:3000/oauth/{provider}/callback",
+	}
+	if issuer {
+		cfg.Issuer = idp.Issuer()
+	} else {
+		cfg.AuthURL = idp.Issuer() + "/authorize"
+		cfg.TokenURL = idp.Issuer() + "/token"
+		cfg.UserInfoURL = idp.Issuer() + "/userinfo"
+	}
+	return NewProvider(cfg)
+}
+
+func TestExchangeVerifiesIDToken(t *testing.T) {
+	idp := testutil.NewFakeOIDCProvider(t)
+	provider := newTestProvider(idp, true)
+	ctx := context.Background()
+
+	verifier, challenge := NewPKCE()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", challenge, "nonce-1")
+	testutil.AssertNoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	testutil.AssertEqual(t, idp.Issuer()+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	testutil.AssertEqual(t, "http://localhost:3000/oauth/fake/callback", parsed.Query().Get("redirect_uri"))
+	testutil.AssertEqual(t, "openid email profile", parsed.Query().Get("scope"))
+
+	code, state := idp.Authorize(t, authURL, testUser)
+	testutil.AssertEqual(t, "state-1", state)
+
+	identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, "fake", identity.Provider)
+	testutil.AssertEqual(t, "fake-user-1", identity.Subject)
+	testutil.AssertEqual(t, "jane@example.com", identity.Email)
+	testutil.AssertTrue(t, identity.EmailVerified)
+	testutil.AssertEqual(t, "Jane Doe", identity.Name)
+}
+
+func TestExchangeRejectsWrongVerifierAndNonce(t *testing.T) {
+	idp := testutil.NewFakeOIDCProvider(t)
+	provider := newTestProvider(idp, true)
+	ctx := context.Background()
+
+	_, challenge := NewPKCE()
+	authURL, err := provider.AuthCodeURL(ctx, "state", challenge, "nonce")
+	testutil.AssertNoError(t, err)
+	code, _ := idp.Authorize(t, authURL, testUser)
+	otherVerifier, _ := NewPKCE()
+	_, err = provider.Exchange(ctx, code, otherVerifier, "nonce")
+	testutil.AssertTrue(t, errors.Is(err, ErrExchangeFailed), "PKCE mismatch must fail the exchange")
+
+	verifier, challenge := NewPKCE()
+	authURL, err = provider.AuthCodeURL(ctx, "state", challenge, "nonce")
+	testutil.AssertNoError(t, err)
+	code, _ = idp.Authorize(t, authURL, testUser)
+	_, err = provider.Exchange(ctx, code, verifier, "another-nonce")
+	testutil.AssertTrue(t, errors.Is(err, ErrInvalidIDToken), "nonce mismatch must be rejected")
+
+	_, err = provider.Exchange(ctx, code, verifier, "nonce")
+	testutil.AssertTrue(t, errors.Is(err, ErrExchangeFailed), "codes are single use")
+}
+
+func TestExchangeUsesUserInfoWithoutIssuer(t *testing.T) {
+	idp := testutil.NewFakeOIDCProvider(t)
+	provider := newTestProvider(idp, false)
+	ctx := context.Background()
+
+	verifier, challenge := NewPKCE()
+	authURL, err := provider.AuthCodeURL(ctx, "state", challenge, "nonce")
+	testutil.AssertNoError(t, err)
+	parsed, _ := url.Parse(authURL)
+	testutil.AssertEqual(t, "", parsed.Query().Get("nonce"), "plain OAuth providers get no nonce")
+
+	code, _ := idp.Authorize(t, authURL, testUser)
+	identity, err := provider.Exchange(ctx, code, verifier, "")
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, "fake-user-1", identity.Subject)
+	testutil.AssertEqual(t, "jane@example.com", identity.Email)
+	testutil.AssertTrue(t, identity.EmailVerified)
+}
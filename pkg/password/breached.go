@@ -0,0 +1,91 @@
+package password
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PrefixLength is the number of hex characters of the SHA-1 hash sent to a
+// RangeSource, as in the Pwned Passwords range API
+const PrefixLength = 5
+
+// RangeSource returns the SHA-1 hash suffixes of breached passwords whose
+// hash starts with prefix. Only the prefix leaves the caller, so a remote
+// source never learns the password or its full hash.
+type RangeSource interface {
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Breached rejects passwords whose SHA-1 hash is listed by source
+func Breached(source RangeSource) Rule {
+	return RuleFunc(func(ctx context.Context, in Input) (*Violation, error) {
+		sum := sha1.Sum([]byte(in.Password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		suffixes, err := source.Range(ctx, hash[:PrefixLength])
+		if err != nil {
+			return nil, err
+		}
+		for _, suffix := range suffixes {
+			if suffix == hash[PrefixLength:] {
+				return &Violation{Code: CodeBreached, Message: "appears in a list of breached passwords; choose another one"}, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// LocalRangeSource serves ranges from an in-memory list of hashes
+type LocalRangeSource struct {
+	ranges map[string][]string
+}
+
+// LoadRangeSource reads a gzip-compressed list with one uppercase or
+// lowercase SHA-1 hex hash per line. Blank lines and lines starting with #
+// are ignored.
+func LoadRangeSource(r io.Reader) (*LocalRangeSource, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	source := &LocalRangeSource{ranges: make(map[string][]string)}
+	scanner := bufio.NewScanner(gz)
+	for line := 1; scanner.Scan(); line++ {
+		hash := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if hash == "" || strings.HasPrefix(hash, "#") {
+			continue
+		}
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("line %d: not a SHA-1 hash", line)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		prefix := hash[:PrefixLength]
+		source.ranges[prefix] = append(source.ranges[prefix], hash[PrefixLength:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+func (s *LocalRangeSource) Range(_ context.Context, prefix string) ([]string, error) {
+	return s.ranges[strings.ToUpper(prefix)], nil
+}
+
+// Len returns the number of hashes in the list
+func (s *LocalRangeSource) Len() int {
+	n := 0
+	for _, suffixes := range s.ranges {
+		n += len(suffixes)
+	}
+	return n
+}
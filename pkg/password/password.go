@@ -0,0 +1,182 @@
+// Package password checks candidate passwords against a configurable policy
+// made of independent rules.
+package password
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes returned by the built-in rules
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingClass     = "missing_character_class"
+	CodeContainsPersonal = "contains_personal_info"
+	CodeRecentlyUsed     = "recently_used"
+	CodeBreached         = "breached"
+)
+
+// minPersonalTokenRunes skips name and email parts too short to be meaningful
+const minPersonalTokenRunes = 3
+
+// Violation describes one rule a password fails
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Input is the candidate password and what is known about its owner
+type Input struct {
+	Password string
+	Email    string
+	Names    []string
+	// History holds hashes of the user's recent passwords, newest first
+	History []string
+}
+
+// Rule checks one aspect of a password. It returns nil when the password
+// passes and an error only when the check itself could not be performed.
+type Rule interface {
+	Check(ctx context.Context, in Input) (*Violation, error)
+}
+
+// RuleFunc adapts a function to the Rule interface
+type RuleFunc func(ctx context.Context, in Input) (*Violation, error)
+
+func (f RuleFunc) Check(ctx context.Context, in Input) (*Violation, error) {
+	return f(ctx, in)
+}
+
+// Policy runs its rules in order and collects every violation
+type Policy struct {
+	rules []Rule
+}
+
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Check returns all violations of the policy; an empty result means the
+// password is acceptable.
+func (p *Policy) Check(ctx context.Context, in Input) ([]Violation, error) {
+	var violations []Violation
+	for _, rule := range p.rules {
+		v, err := rule.Check(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			violations = append(violations, *v)
+		}
+	}
+	return violations, nil
+}
+
+// Length requires between min and max characters; a zero max means no limit
+func Length(min, max int) Rule {
+	return RuleFunc(func(_ context.Context, in Input) (*Violation, error) {
+		n := utf8.RuneCountInString(in.Password)
+		if n < min {
+			return &Violation{Code: CodeTooShort, Message: fmt.Sprintf("must be at least %d characters long", min)}, nil
+		}
+		if max > 0 && n > max {
+			return &Violation{Code: CodeTooLong, Message: fmt.Sprintf("must be at most %d characters long", max)}, nil
+		}
+		return nil, nil
+	})
+}
+
+// Class is a character class a password can be required to contain
+type Class string
+
+const (
+	Lowercase Class = "lowercase"
+	Uppercase Class = "uppercase"
+	Digit     Class = "digit"
+	Symbol    Class = "symbol"
+)
+
+var classNames = map[Class]string{
+	Lowercase: "a lowercase letter",
+	Uppercase: "an uppercase letter",
+	Digit:     "a digit",
+	Symbol:    "a symbol",
+}
+
+// ParseClass returns the class with the given name
+func ParseClass(name string) (Class, error) {
+	class := Class(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := classNames[class]; !ok {
+		return "", fmt.Errorf("unknown character class %q", name)
+	}
+	return class, nil
+}
+
+// CharacterClasses requires at least one character of each class
+func CharacterClasses(classes ...Class) Rule {
+	return RuleFunc(func(_ context.Context, in Input) (*Violation, error) {
+		present := make(map[Class]bool, 4)
+		for _, r := range in.Password {
+			switch {
+			case unicode.IsLower(r):
+				present[Lowercase] = true
+			case unicode.IsUpper(r):
+				present[Uppercase] = true
+			case unicode.IsDigit(r):
+				present[Digit] = true
+			case !unicode.IsSpace(r):
+				present[Symbol] = true
+			}
+		}
+		var missing []string
+		for _, class := range classes {
+			if !present[class] {
+				missing = append(missing, classNames[class])
+			}
+		}
+		if len(missing) == 0 {
+			return nil, nil
+		}
+		return &Violation{Code: CodeMissingClass, Message: "must contain " + strings.Join(missing, ", ")}, nil
+	})
+}
+
+// PersonalInfo rejects passwords containing the email's local part or one of
+// the names, ignoring case.
+func PersonalInfo() Rule {
+	return RuleFunc(func(_ context.Context, in Input) (*Violation, error) {
+		lower := strings.ToLower(in.Password)
+		local, _, _ := strings.Cut(in.Email, "@")
+		tokens := append([]string{local}, in.Names...)
+		for _, token := range tokens {
+			for _, part := range strings.FieldsFunc(strings.ToLower(token), func(r rune) bool {
+				return unicode.IsSpace(r) || r == '.' || r == '_' || r == '-' || r == '+'
+			}) {
+				if utf8.RuneCountInString(part) >= minPersonalTokenRunes && strings.Contains(lower, part) {
+					return &Violation{Code: CodeContainsPersonal, Message: "must not contain your name or email address"}, nil
+				}
+			}
+		}
+		return nil, nil
+	})
+}
+
+// History rejects a password matching one of the first depth hashes in
+// Input.History. verify reports whether a password matches a hash.
+func History(depth int, verify func(password, hash string) bool) Rule {
+	return RuleFunc(func(_ context.Context, in Input) (*Violation, error) {
+		for i, hash := range in.History {
+			if i >= depth {
+				break
+			}
+			if verify(in.Password, hash) {
+				return &Violation{Code: CodeRecentlyUsed, Message: fmt.Sprintf("must not match any of your last %d passwords", depth)}, nil
+			}
+		}
+		return nil, nil
+	})
+}
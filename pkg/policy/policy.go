@@ -0,0 +1,77 @@
+// Package policy decides whether the actor behind a request may perform an
+// action on a record. Each entity gets its own Policy; services look the actor
+// up in the context and call Authorize before touching a record.
+package policy
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Action is something an actor does to a record
+type Action string
+
+const (
+	View   Action = "view"
+	Update Action = "update"
+	Delete Action = "delete"
+	// Share grants or revokes other users' access to the record
+	Share Action = "share"
+)
+
+// ErrForbidden is returned when the policy denies the action, or when the
+// context carries no actor
+var ErrForbidden = errors.New("policy: action not allowed")
+
+// Actor is the user a request acts for. Roles are the roles that apply in the
+// request's scope, such as the user's role in the active organization.
+type Actor struct {
+	UserID uint
+	Roles  []string
+}
+
+// HasRole reports whether the actor holds any of the roles
+func (a Actor) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(a.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithActor returns a context carrying the actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// ActorFromContext returns the actor the context carries
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(contextKey{}).(Actor)
+	return actor, ok && actor.UserID != 0
+}
+
+// Policy decides which actions an actor may perform on records of type T
+type Policy[T any] interface {
+	Allow(actor Actor, action Action, record *T) bool
+}
+
+// Func adapts a function to a Policy
+type Func[T any] func(actor Actor, action Action, record *T) bool
+
+func (f Func[T]) Allow(actor Actor, action Action, record *T) bool {
+	return f(actor, action, record)
+}
+
+// Authorize checks the context's actor against the policy and returns
+// ErrForbidden when the action is not allowed
+func Authorize[T any](ctx context.Context, p Policy[T], action Action, record *T) error {
+	actor, ok := ActorFromContext(ctx)
+	if !ok || !p.Allow(actor, action, record) {
+		return ErrForbidden
+	}
+	return nil
+}
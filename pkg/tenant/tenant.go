@@ -0,0 +1,150 @@
+// Package tenant scopes GORM queries to the organization carried in the
+// statement's context. Models opt in by implementing Owned; every query,
+// update and delete on them is filtered by organization_id, and creates are
+// stamped with it. A statement without an organization in its context fails
+// instead of reading across tenants.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column is the column tenant-owned tables are scoped by
+const Column = "organization_id"
+
+var (
+	ErrMissingTenant = errors.New("tenant: no organization in context for tenant-owned model")
+	ErrCrossTenant   = errors.New("tenant: record belongs to another organization")
+)
+
+// Owned is implemented by models whose rows belong to one organization. The
+// model must have an organization_id column.
+type Owned interface {
+	TenantOwned()
+}
+
+type contextKey int
+
+const (
+	organizationKey contextKey = iota
+	bypassKey
+)
+
+// WithOrganization returns a context whose statements are scoped to the organization
+func WithOrganization(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, organizationKey, organizationID)
+}
+
+// FromContext returns the organization the context is scoped to
+func FromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(organizationKey).(uint)
+	return id, ok && id != 0
+}
+
+// Bypass returns a context whose statements skip tenant scoping, for
+// background jobs and cross-tenant administration. Use it sparingly.
+func Bypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey, true)
+}
+
+// Plugin registers the scoping callbacks: db.Use(tenant.Plugin{})
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scope); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("tenant:create", stamp)
+}
+
+// organization returns the statement's organization, whether the statement
+// has to be scoped at all, and sets ErrMissingTenant when it cannot be
+func organization(db *gorm.DB) (uint, bool) {
+	if db.Error != nil || db.Statement.Schema == nil || !isOwned(db.Statement.Schema.ModelType) {
+		return 0, false
+	}
+	ctx := db.Statement.Context
+	if bypass, _ := ctx.Value(bypassKey).(bool); bypass {
+		return 0, false
+	}
+	id, ok := FromContext(ctx)
+	if !ok {
+		_ = db.AddError(fmt.Errorf("%w: %s", ErrMissingTenant, db.Statement.Schema.Table))
+		return 0, false
+	}
+	return id, true
+}
+
+func scope(db *gorm.DB) {
+	id, ok := organization(db)
+	if !ok {
+		return
+	}
+	// A statement reused for Count and Find only needs the condition once
+	if _, scoped := db.Statement.Settings.Load("tenant:scoped"); scoped {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: Column}, Value: id},
+	}})
+	db.Statement.Settings.Store("tenant:scoped", true)
+}
+
+func stamp(db *gorm.DB) {
+	id, ok := organization(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField(Column)
+	if field == nil {
+		_ = db.AddError(fmt.Errorf("tenant: %s has no %s column", db.Statement.Schema.Table, Column))
+		return
+	}
+	ctx := db.Statement.Context
+	set := func(rv reflect.Value) {
+		value, zero := field.ValueOf(ctx, rv)
+		if zero {
+			if err := field.Set(ctx, rv, id); err != nil {
+				_ = db.AddError(err)
+			}
+			return
+		}
+		if fmt.Sprint(value) != fmt.Sprint(id) {
+			_ = db.AddError(ErrCrossTenant)
+		}
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			set(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		set(rv)
+	}
+}
+
+func isOwned(modelType reflect.Type) bool {
+	owned := reflect.TypeOf((*Owned)(nil)).Elem()
+	return modelType.Implements(owned) || reflect.PointerTo(modelType).Implements(owned)
+}
@@ -0,0 +1,121 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type note struct {
+	ID             uint
+	OrganizationID uint
+	Body           string
+}
+
+func (note) TenantOwned() {}
+
+type tag struct {
+	ID   uint
+	Name string
+}
+
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// Every connection to :memory: opens another database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&note{}, &tag{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Use(Plugin{}); err != nil {
+		t.Fatalf("register plugin: %v", err)
+	}
+	return db
+}
+
+func bodies(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var out []string
+	if err := db.Model(&note{}).Order("id").Pluck("body", &out).Error; err != nil {
+		t.Fatalf("Pluck() error = %v", err)
+	}
+	return out
+}
+
+func TestPluginStampsCreates(t *testing.T) {
+	db := setupDB(t)
+	acme := db.WithContext(WithOrganization(context.Background(), 1))
+
+	created := note{Body: "stamped"}
+	if err := acme.Create(&created).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.OrganizationID != 1 {
+		t.Fatalf("OrganizationID = %d, want 1", created.OrganizationID)
+	}
+	batch := []note{{Body: "first"}, {Body: "second", OrganizationID: 1}}
+	if err := acme.Create(&batch).Error; err != nil {
+		t.Fatalf("Create() of a batch error = %v", err)
+	}
+	if batch[0].OrganizationID != 1 {
+		t.Fatalf("batch OrganizationID = %d, want 1", batch[0].OrganizationID)
+	}
+
+	if err := acme.Create(&note{Body: "foreign", OrganizationID: 2}).Error; !errors.Is(err, ErrCrossTenant) {
+		t.Fatalf("Create() into another organization error = %v, want ErrCrossTenant", err)
+	}
+	if err := db.Create(&note{Body: "unscoped"}).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Fatalf("Create() without an organization error = %v, want ErrMissingTenant", err)
+	}
+	// Models that are not tenant-owned are left alone
+	if err := db.Create(&tag{Name: "shared"}).Error; err != nil {
+		t.Fatalf("Create() of an unowned model error = %v", err)
+	}
+}
+
+func TestPluginScopesQueriesUpdatesAndDeletes(t *testing.T) {
+	db := setupDB(t)
+	acme := db.WithContext(WithOrganization(context.Background(), 1))
+	globex := db.WithContext(WithOrganization(context.Background(), 2))
+	acme.Create(&[]note{{Body: "a1"}, {Body: "a2"}})
+	globex.Create(&note{Body: "g1"})
+
+	if got := bodies(t, acme); len(got) != 2 || got[0] != "a1" || got[1] != "a2" {
+		t.Fatalf("acme notes = %v, want [a1 a2]", got)
+	}
+	var found note
+	if err := globex.First(&found, "body = ?", "a1").Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("First() of another organization's note error = %v, want ErrRecordNotFound", err)
+	}
+	var count int64
+	if err := acme.Model(&note{}).Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("Count() = %d, %v; want 2", count, err)
+	}
+
+	if result := globex.Model(&note{}).Where("body = ?", "a1").Update("body", "stolen"); result.Error != nil || result.RowsAffected != 0 {
+		t.Fatalf("Update() across organizations = %d rows, %v; want 0", result.RowsAffected, result.Error)
+	}
+	if result := globex.Where("body LIKE ?", "a%").Delete(&note{}); result.Error != nil || result.RowsAffected != 0 {
+		t.Fatalf("Delete() across organizations = %d rows, %v; want 0", result.RowsAffected, result.Error)
+	}
+
+	var all []note
+	if err := db.Find(&all).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Fatalf("Find() without an organization error = %v, want ErrMissingTenant", err)
+	}
+	if got := bodies(t, db.WithContext(Bypass(context.Background()))); len(got) != 3 {
+		t.Fatalf("bypassed notes = %v, want all three", got)
+	}
+}
@@ -1,24 +1,198 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashes a password using bcrypt
+var (
+	ErrPasswordMismatch    = errors.New("password does not match")
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// PasswordHasher hashes passwords into self-describing strings that carry
+// the algorithm and its parameters
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify returns ErrPasswordMismatch when password does not match encoded
+	Verify(password, encoded string) error
+	// Identifies reports whether encoded was produced by this algorithm
+	Identifies(encoded string) bool
+	// NeedsRehash reports whether encoded uses other parameters than the hasher
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	hasherMu       sync.RWMutex
+	passwordHasher PasswordHasher = NewArgon2idHasher(DefaultArgon2idParams)
+)
+
+// SetPasswordHasher sets the hasher used for new passwords. Hashes of the
+// built-in algorithms can always be verified, whichever hasher is set.
+func SetPasswordHasher(h PasswordHasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	passwordHasher = h
+}
+
+func currentHasher() PasswordHasher {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	return passwordHasher
+}
+
+// HashPassword hashes a password with the configured hasher
 func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return currentHasher().Hash(password)
+}
+
+// VerifyPassword verifies a password against a hash of any supported algorithm
+func VerifyPassword(password, hash string) error {
+	preferred := currentHasher()
+	for _, h := range []PasswordHasher{preferred, argon2idHasher{}, bcryptHasher{}} {
+		if h.Identifies(hash) {
+			return h.Verify(password, hash)
+		}
+	}
+	return ErrUnknownPasswordHash
+}
+
+// PasswordNeedsRehash reports whether a hash was made with another algorithm
+// or other parameters than the configured hasher would use today
+func PasswordNeedsRehash(hash string) bool {
+	preferred := currentHasher()
+	return !preferred.Identifies(hash) || preferred.NeedsRehash(hash)
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a hasher producing $2a$ bcrypt hashes
+func NewBcryptHasher(cost int) PasswordHasher {
+	return bcryptHasher{cost: cost}
+}
+
+func (h bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", err
 	}
-	return string(hashedPassword), nil
+	return string(hashed), nil
 }
 
-// VerifyPassword verifies a password against a hash
-func VerifyPassword(password, hash string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+func (h bcryptHasher) Verify(password, encoded string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+func (h bcryptHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
 }
 
-// IsPasswordValid validates password strength
-func IsPasswordValid(password string) bool {
-	return len(password) >= 6
+// Argon2idParams are the argon2id cost parameters; Memory is in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation of 64 MiB memory
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher returns a hasher producing PHC strings of the form
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return argon2idHasher{params: params}
+}
+
+func (h argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h argon2idHasher) Verify(password, encoded string) error {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func (h argon2idHasher) Identifies(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.params.Memory || params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism || uint32(len(salt)) != h.params.SaltLength ||
+		uint32(len(key)) != h.params.KeyLength
+}
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
 }
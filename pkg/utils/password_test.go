@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testArgon2idParams keep the tests fast
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHashIsSelfDescribing(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("Hash() = %q, want a PHC argon2id string", hash)
+	}
+	if err := hasher.Verify("correct horse", hash); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := hasher.Verify("wrong horse", hash); !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("Verify() with a wrong password = %v, want ErrPasswordMismatch", err)
+	}
+	if hasher.NeedsRehash(hash) {
+		t.Fatal("NeedsRehash() = true for the hasher's own parameters")
+	}
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+	if !NewArgon2idHasher(stronger).NeedsRehash(hash) {
+		t.Fatal("NeedsRehash() = false after raising the iterations")
+	}
+}
+
+func TestPasswordNeedsRehashAcrossAlgorithms(t *testing.T) {
+	t.Cleanup(func() { SetPasswordHasher(NewArgon2idHasher(DefaultArgon2idParams)) })
+	bcryptHash, err := NewBcryptHasher(4).Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	SetPasswordHasher(NewArgon2idHasher(testArgon2idParams))
+	if err := VerifyPassword("correct horse", bcryptHash); err != nil {
+		t.Fatalf("VerifyPassword() of a bcrypt hash under argon2id = %v", err)
+	}
+	if !PasswordNeedsRehash(bcryptHash) {
+		t.Fatal("PasswordNeedsRehash() = false for a bcrypt hash under argon2id")
+	}
+	argonHash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if PasswordNeedsRehash(argonHash) {
+		t.Fatal("PasswordNeedsRehash() = true for a current hash")
+	}
+
+	SetPasswordHasher(NewBcryptHasher(5))
+	if err := VerifyPassword("correct horse", argonHash); err != nil {
+		t.Fatalf("VerifyPassword() of an argon2id hash under bcrypt = %v", err)
+	}
+	if !PasswordNeedsRehash(bcryptHash) {
+		t.Fatal("PasswordNeedsRehash() = false after raising the bcrypt cost")
+	}
+	if err := VerifyPassword("correct horse", "plain"); !errors.Is(err, ErrUnknownPasswordHash) {
+		t.Fatalf("VerifyPassword() of an unknown format = %v, want ErrUnknownPasswordHash", err)
+	}
+}
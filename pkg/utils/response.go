@@ -36,6 +36,18 @@ func ErrorResponseWithCode(c *fiber.Ctx, statusCode int, code, message string) e
 	return c.Status(statusCode).JSON(response)
 }
 
+// ValidationErrorResponse sends a 400 response listing the rejected fields
+func ValidationErrorResponse(c *fiber.Ctx, message string, errs []models.FieldError) error {
+	response := models.APIResponse{
+		Status:  fiber.StatusBadRequest,
+		Code:    "validation_failed",
+		Message: message,
+		Error:   message,
+		Errors:  errs,
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(response)
+}
+
 // PaginatedResponse sends a paginated response
 func PaginatedResponse(c *fiber.Ctx, message string, data interface{}, page, limit int, total int64) error {
 	totalPages := 0
@@ -54,6 +66,25 @@ func PaginatedResponse(c *fiber.Ctx, message string, data interface{}, page, lim
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// CursorPaginatedResponse sends a cursor paginated response. Empty cursors
+// are sent as null, and total is left out when nil.
+func CursorPaginatedResponse(c *fiber.Ctx, message string, data interface{}, limit int, nextCursor, prevCursor string, total *int64) error {
+	response := models.CursorPaginatedResponse{
+		Status:  fiber.StatusOK,
+		Message: message,
+		Data:    data,
+		Limit:   limit,
+		Total:   total,
+	}
+	if nextCursor != "" {
+		response.NextCursor = &nextCursor
+	}
+	if prevCursor != "" {
+		response.PrevCursor = &prevCursor
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
 // CreatedResponse sends a 201 created response
 func CreatedResponse(c *fiber.Ctx, message string, data interface{}) error {
 	return SuccessResponse(c, fiber.StatusCreated, message, data)
@@ -0,0 +1,149 @@
+// Package versioning implements optimistic concurrency for models with an
+// integer version column. The version is exposed as the record's ETag; the
+// If-Match header of a request travels in the context to Update and Delete,
+// which only change the row while its version still matches.
+package versioning
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Column is the version column of versioned models
+const Column = "version"
+
+// ErrPreconditionFailed is returned when the record's version no longer
+// matches the request's If-Match header
+var ErrPreconditionFailed = errors.New("versioning: record was modified")
+
+// ETag returns the entity tag of a version
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// Precondition lists the versions an If-Match or If-None-Match header names
+type Precondition struct {
+	// Any is set by "*"
+	Any      bool
+	Versions []uint
+}
+
+// Matches reports whether the header names the version
+func (p Precondition) Matches(version uint) bool {
+	return p.Any || slices.Contains(p.Versions, version)
+}
+
+// ParseIfMatch parses an If-Match header. It compares strongly, so weak tags
+// and tags this package did not issue never match.
+func ParseIfMatch(header string) Precondition {
+	return parse(header, false)
+}
+
+// ParseIfNoneMatch parses an If-None-Match header. It compares weakly, so
+// W/"3" matches version 3.
+func ParseIfNoneMatch(header string) Precondition {
+	return parse(header, true)
+}
+
+func parse(header string, weak bool) Precondition {
+	var p Precondition
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			p.Any = true
+			continue
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 0); err == nil {
+			p.Versions = append(p.Versions, uint(version))
+		}
+	}
+	return p
+}
+
+type contextKey struct{}
+
+// WithPrecondition returns a context carrying the request's If-Match header
+func WithPrecondition(ctx context.Context, p Precondition) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PreconditionFromContext returns the If-Match header the context carries
+func PreconditionFromContext(ctx context.Context) (Precondition, bool) {
+	p, ok := ctx.Value(contextKey{}).(Precondition)
+	return p, ok
+}
+
+// Check returns ErrPreconditionFailed when the context carries a
+// precondition that the version does not meet
+func Check(ctx context.Context, version uint) error {
+	if p, ok := PreconditionFromContext(ctx); ok && !p.Matches(version) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// Update applies updates to model, loaded at version current, and increments
+// the version. With a precondition in db's context the row is only updated
+// while it is still at current, so a concurrent change fails the update with
+// ErrPreconditionFailed instead of being overwritten.
+func Update(db *gorm.DB, model interface{}, current uint, updates map[string]interface{}) error {
+	ctx := db.Statement.Context
+	if err := Check(ctx, current); err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		values[column] = value
+	}
+	values[Column] = gorm.Expr(Column + " + 1")
+	tx := db.Model(model)
+	_, conditional := PreconditionFromContext(ctx)
+	if conditional {
+		tx = tx.Where(Column+" = ?", current)
+	}
+	return affected(tx.Updates(values), conditional)
+}
+
+// Delete deletes model, loaded at version current, under the same
+// precondition as Update
+func Delete(db *gorm.DB, model interface{}, current uint) error {
+	ctx := db.Statement.Context
+	if err := Check(ctx, current); err != nil {
+		return err
+	}
+	tx := db
+	_, conditional := PreconditionFromContext(ctx)
+	if conditional {
+		tx = tx.Where(Column+" = ?", current)
+	}
+	return affected(tx.Delete(model), conditional)
+}
+
+// affected reports a statement that touched no row as a failed precondition
+// when it was conditional, and as a missing record otherwise
+func affected(result *gorm.DB, conditional bool) error {
+	switch {
+	case result.Error != nil:
+		return result.Error
+	case result.RowsAffected > 0:
+		return nil
+	case conditional:
+		return ErrPreconditionFailed
+	default:
+		return gorm.ErrRecordNotFound
+	}
+}
@@ -0,0 +1,148 @@
+package versioning
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// document is a versioned, soft-deleted record like the models using the package
+type document struct {
+	ID        uint
+	Name      string
+	Version   uint `gorm:"not null;default:1"`
+	DeletedAt gorm.DeletedAt
+}
+
+func TestParsePreconditions(t *testing.T) {
+	if got := ETag(7); got != `"7"` {
+		t.Errorf(`ETag(7) = %s, want "7"`, got)
+	}
+
+	p := ParseIfMatch(`"3", W/"4", "five", 6`)
+	if !reflect.DeepEqual(p.Versions, []uint{3}) {
+		t.Errorf("If-Match versions = %v, want [3]", p.Versions)
+	}
+	if p.Matches(4) {
+		t.Error("If-Match must compare strongly")
+	}
+
+	p = ParseIfNoneMatch(`W/"4", "5"`)
+	if !reflect.DeepEqual(p.Versions, []uint{4, 5}) {
+		t.Errorf("If-None-Match versions = %v, want [4 5]", p.Versions)
+	}
+	if !p.Matches(4) {
+		t.Error("If-None-Match must compare weakly")
+	}
+
+	if !ParseIfMatch(" * ").Matches(42) {
+		t.Error("* must match any version")
+	}
+	if ParseIfMatch(`"1"`).Matches(2) {
+		t.Error(`"1" must not match version 2`)
+	}
+}
+
+// newDocument returns a db holding one document at version 1
+func newDocument(t *testing.T) (*gorm.DB, *document) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	// Every connection to :memory: is a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	doc := &document{Name: "Roadmap"}
+	if err := db.AutoMigrate(&document{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(doc).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if doc.Version != 1 {
+		t.Fatalf("new document version = %d, want 1", doc.Version)
+	}
+	return db.WithContext(context.Background()), doc
+}
+
+func storedVersion(t *testing.T, db *gorm.DB, id uint) uint {
+	t.Helper()
+	var doc document
+	if err := db.First(&doc, id).Error; err != nil {
+		t.Fatalf("load document: %v", err)
+	}
+	return doc.Version
+}
+
+func TestUpdateChecksVersion(t *testing.T) {
+	db, doc := newDocument(t)
+	conditional := func(versions ...uint) *gorm.DB {
+		return db.WithContext(WithPrecondition(context.Background(), Precondition{Versions: versions}))
+	}
+
+	if err := Update(conditional(2), doc, doc.Version, map[string]interface{}{"name": "Stale"}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("stale If-Match = %v, want ErrPreconditionFailed", err)
+	}
+	if v := storedVersion(t, db, doc.ID); v != 1 {
+		t.Errorf("version after a failed update = %d, want 1", v)
+	}
+
+	if err := Update(conditional(1), doc, doc.Version, map[string]interface{}{"name": "Renamed"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if v := storedVersion(t, db, doc.ID); v != 2 {
+		t.Errorf("version after an update = %d, want 2", v)
+	}
+
+	// A change that lands between loading and updating still fails the update
+	// the caller loaded at version 1
+	if err := Update(conditional(1), doc, 1, map[string]interface{}{"name": "Lost update"}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("concurrent change = %v, want ErrPreconditionFailed", err)
+	}
+
+	// Without If-Match the update is unconditional
+	if err := Update(db, doc, 1, map[string]interface{}{"name": "Forced"}); err != nil {
+		t.Fatalf("unconditional Update() error = %v", err)
+	}
+	if v := storedVersion(t, db, doc.ID); v != 3 {
+		t.Errorf("version after an unconditional update = %d, want 3", v)
+	}
+}
+
+func TestDeleteChecksVersion(t *testing.T) {
+	db, doc := newDocument(t)
+	ifMatch := func(p Precondition) *gorm.DB {
+		return db.WithContext(WithPrecondition(context.Background(), p))
+	}
+
+	if err := Delete(ifMatch(Precondition{Versions: []uint{2}}), doc, doc.Version); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("stale If-Match = %v, want ErrPreconditionFailed", err)
+	}
+
+	if err := Update(db, doc, doc.Version, map[string]interface{}{"name": "Renamed"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := Delete(ifMatch(Precondition{Versions: []uint{1}}), doc, 1); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("concurrent change = %v, want ErrPreconditionFailed", err)
+	}
+
+	if err := Delete(ifMatch(Precondition{Any: true}), doc, 2); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.First(&document{}, doc.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("loading the deleted document = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := Delete(db, doc, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleting twice = %v, want gorm.ErrRecordNotFound", err)
+	}
+}